@@ -0,0 +1,133 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Result statuses.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Request is a single line of a batch input file. It carries either a raw
+// Prompt or a Category/Topic/Query triple rendered through a template.
+type Request struct {
+	ID          string   `json:"id"`
+	Prompt      string   `json:"prompt,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Topic       string   `json:"topic,omitempty"`
+	Query       string   `json:"query,omitempty"`
+	Provider    string   `json:"provider,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+// Result is a single line of a batch output file.
+type Result struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Provider  string `json:"provider,omitempty"`
+	Model     string `json:"model,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Response  string `json:"response,omitempty"`
+}
+
+// validate checks that the request has something to send.
+func (r Request) validate() error {
+	if r.Prompt == "" && r.Topic == "" && r.Category == "" {
+		return fmt.Errorf("request needs a prompt or a category/topic")
+	}
+	return nil
+}
+
+// ReadRequests parses a JSONL batch file. Blank lines are skipped and
+// requests without an id are named after their line number. Lines that
+// cannot be used are returned as failed results instead of aborting.
+func ReadRequests(r io.Reader) ([]Request, []Result, error) {
+	var (
+		requests []Request
+		invalid  []Result
+		seen     = map[string]bool{}
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var req Request
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			invalid = append(invalid, failed(fmt.Sprintf("line-%d", line), fmt.Errorf("invalid JSON: %w", err)))
+			continue
+		}
+		if req.ID == "" {
+			req.ID = fmt.Sprintf("line-%d", line)
+		}
+		if seen[req.ID] {
+			invalid = append(invalid, failed(req.ID, fmt.Errorf("duplicate id on line %d", line)))
+			continue
+		}
+		seen[req.ID] = true
+
+		if err := req.validate(); err != nil {
+			invalid = append(invalid, failed(req.ID, err))
+			continue
+		}
+		requests = append(requests, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read batch input: %w", err)
+	}
+	return requests, invalid, nil
+}
+
+// failed builds an error result.
+func failed(id string, err error) Result {
+	return Result{ID: id, Status: StatusError, Error: err.Error()}
+}
+
+// Writer appends results as JSON lines. It is safe for concurrent use and
+// flushes (and syncs, when the destination supports it) after every line,
+// so the output doubles as a checkpoint for resuming interrupted runs.
+type Writer struct {
+	mu   sync.Mutex
+	w    *bufio.Writer
+	enc  *json.Encoder
+	sync func() error
+}
+
+// NewWriter wraps w in a result writer.
+func NewWriter(w io.Writer) *Writer {
+	bw := bufio.NewWriter(w)
+	writer := &Writer{w: bw, enc: json.NewEncoder(bw)}
+	if s, ok := w.(interface{ Sync() error }); ok {
+		writer.sync = s.Sync
+	}
+	return writer
+}
+
+// Write encodes and flushes a single result.
+func (w *Writer) Write(r Result) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(r); err != nil {
+		return err
+	}
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.sync != nil {
+		return w.sync()
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadRequests(t *testing.T) {
+	input := `{"id":"a","prompt":"hello"}
+
+{"category":"topics","topic":"git","model":"llama3","temperature":0.1}
+not json
+{"id":"a","prompt":"dup"}
+{"id":"empty"}
+`
+	requests, invalid, err := ReadRequests(strings.NewReader(input))
+	assert.NoError(t, err)
+
+	assert.Len(t, requests, 2)
+	assert.Equal(t, "a", requests[0].ID)
+	assert.Equal(t, "line-3", requests[1].ID)
+	assert.Equal(t, "llama3", requests[1].Model)
+	assert.Equal(t, 0.1, *requests[1].Temperature)
+
+	assert.Len(t, invalid, 3)
+	assert.Equal(t, "line-4", invalid[0].ID)
+	assert.Contains(t, invalid[0].Error, "invalid JSON")
+	assert.Contains(t, invalid[1].Error, "duplicate id")
+	assert.Equal(t, "empty", invalid[2].ID)
+	for _, r := range invalid {
+		assert.Equal(t, StatusError, r.Status)
+	}
+}
+
+func TestWriter_ConcurrentLines(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, w.Write(Result{ID: string(rune('a' + i)), Status: StatusOK}))
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 20)
+	for _, line := range lines {
+		var r Result
+		assert.NoError(t, json.Unmarshal([]byte(line), &r))
+	}
+}
@@ -0,0 +1,93 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ReadResults loads previously written results, keyed by id. Later lines win,
+// so a retried request replaces its earlier failure. Unparsable lines, such
+// as a line cut short by a crash, are ignored.
+func ReadResults(r io.Reader) (map[string]Result, []string, error) {
+	results := map[string]Result{}
+	var order []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var res Result
+		if err := json.Unmarshal(scanner.Bytes(), &res); err != nil || res.ID == "" {
+			continue
+		}
+		if _, seen := results[res.ID]; !seen {
+			order = append(order, res.ID)
+		}
+		results[res.ID] = res
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	return results, order, nil
+}
+
+// LoadResults reads a results file. A missing file yields no results.
+func LoadResults(path string) (map[string]Result, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Result{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results: %w", err)
+	}
+	defer f.Close()
+
+	results, _, err := ReadResults(f)
+	return results, err
+}
+
+// Compact rewrites a results file so each id appears once, keeping its
+// latest result. The file is replaced atomically.
+func Compact(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open results: %w", err)
+	}
+	results, order, err := ReadResults(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to compact results: %w", err)
+	}
+	w := NewWriter(out)
+	for _, id := range order {
+		if err := w.Write(results[id]); err != nil {
+			out.Close()
+			return fmt.Errorf("failed to compact results: %w", err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compact results: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Pending drops requests that already have a successful result.
+func Pending(requests []Request, done map[string]Result) (pending []Request, skipped int) {
+	for _, req := range requests {
+		if res, ok := done[req.ID]; ok && res.Status == StatusOK {
+			skipped++
+			continue
+		}
+		pending = append(pending, req)
+	}
+	return pending, skipped
+}
@@ -0,0 +1,53 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadResults_LastWinsAndSkipsGarbage(t *testing.T) {
+	input := `{"id":"a","status":"error","error":"timeout"}
+{"id":"b","status":"ok","response":"x"}
+{"id":"a","status":"ok","response":"y"}
+{"id":"c","sta`
+	results, order, err := ReadResults(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, order)
+	assert.Equal(t, StatusOK, results["a"].Status)
+	assert.Equal(t, "y", results["a"].Response)
+}
+
+func TestLoadResults_MissingFile(t *testing.T) {
+	results, err := LoadResults(filepath.Join(t.TempDir(), "none.jsonl"))
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"id":"a","status":"error"}
+{"id":"b","status":"ok"}
+{"id":"a","status":"ok"}
+`), 0644))
+
+	assert.NoError(t, Compact(path))
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"id":"a","status":"ok"`)
+}
+
+func TestPending(t *testing.T) {
+	done := map[string]Result{
+		"a": {ID: "a", Status: StatusOK},
+		"b": {ID: "b", Status: StatusError},
+	}
+	pending, skipped := Pending([]Request{{ID: "a"}, {ID: "b"}, {ID: "c"}}, done)
+	assert.Equal(t, 1, skipped)
+	assert.Equal(t, []Request{{ID: "b"}, {ID: "c"}}, pending)
+}
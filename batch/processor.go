@@ -0,0 +1,161 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"raja.aiml/ai.explorer/llm"
+
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+)
+
+// DefaultConcurrency is the worker count used when none is given.
+const DefaultConcurrency = 4
+
+// Processor fans requests out over a bounded pool of workers. Each worker
+// owns its clients, keyed by provider, model and temperature.
+type Processor struct {
+	Concurrency int
+	Defaults    llmConfig.Config
+	NewClient   func(cfg llmConfig.Config) (llm.LLM, error)
+	Render      func(req Request) (string, error)
+	Now         func() time.Time
+}
+
+// Run processes every request and passes each result to emit as soon as it
+// is ready. A failing request produces an error result; only an emit error
+// or a cancelled context stops the run.
+func (p *Processor) Run(ctx context.Context, requests []Request, emit func(Result) error) error {
+	workers := p.Workers()
+	if workers > len(requests) {
+		workers = len(requests)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan Request)
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		emitErr error
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clients := map[string]llm.LLM{}
+			for req := range jobs {
+				if err := emit(p.process(ctx, clients, req)); err != nil {
+					errOnce.Do(func() { emitErr = err; cancel() })
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, req := range requests {
+		select {
+		case jobs <- req:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if emitErr != nil {
+		return fmt.Errorf("failed to write result: %w", emitErr)
+	}
+	return ctx.Err()
+}
+
+// Workers returns the configured pool size, falling back to DefaultConcurrency.
+func (p *Processor) Workers() int {
+	if p.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return p.Concurrency
+}
+
+// process runs a single request, converting panics into error results.
+func (p *Processor) process(ctx context.Context, clients map[string]llm.LLM, req Request) (res Result) {
+	cfg := p.config(req)
+	res = Result{ID: req.ID, Provider: cfg.Provider, Model: cfg.Model.Name}
+	start := p.now()
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			res.Status, res.Error = StatusError, fmt.Sprintf("internal LLM panic: %v", rec)
+		}
+		res.LatencyMS = p.now().Sub(start).Milliseconds()
+	}()
+
+	prompt := req.Prompt
+	if prompt == "" {
+		rendered, err := p.Render(req)
+		if err != nil {
+			res.Status, res.Error = StatusError, fmt.Sprintf("render: %v", err)
+			return res
+		}
+		prompt = rendered
+	}
+
+	client, err := p.client(clients, cfg)
+	if err != nil {
+		res.Status, res.Error = StatusError, err.Error()
+		return res
+	}
+
+	response, err := client.Chat(ctx, prompt)
+	if err != nil {
+		res.Status, res.Error = StatusError, err.Error()
+		return res
+	}
+	if r, ok := client.(llm.BackendReporter); ok {
+		// Record the backend that actually answered, which may be a fallback.
+		answered := r.LastBackend()
+		res.Provider, res.Model = answered.Provider, answered.Model
+	}
+	res.Status, res.Response = StatusOK, response
+	return res
+}
+
+// config applies the request's model overrides to the defaults.
+func (p *Processor) config(req Request) llmConfig.Config {
+	cfg := p.Defaults
+	if req.Provider != "" {
+		cfg.Provider = req.Provider
+	}
+	if req.Model != "" {
+		cfg.Model.Name = req.Model
+	}
+	if req.Temperature != nil {
+		cfg.Model.Temperature = *req.Temperature
+	}
+	return cfg
+}
+
+// client returns the worker's client for cfg, creating it on first use.
+func (p *Processor) client(clients map[string]llm.LLM, cfg llmConfig.Config) (llm.LLM, error) {
+	key := fmt.Sprintf("%s|%s|%g", cfg.Provider, cfg.Model.Name, cfg.Model.Temperature)
+	if c, ok := clients[key]; ok {
+		return c, nil
+	}
+	c, err := p.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	clients[key] = c
+	return c, nil
+}
+
+func (p *Processor) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,139 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/llm"
+
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+)
+
+type fakeLLM struct {
+	model    string
+	inFlight *int32
+	peak     *int32
+}
+
+func (f *fakeLLM) Chat(_ context.Context, prompt string) (string, error) {
+	n := atomic.AddInt32(f.inFlight, 1)
+	defer atomic.AddInt32(f.inFlight, -1)
+	for {
+		p := atomic.LoadInt32(f.peak)
+		if n <= p || atomic.CompareAndSwapInt32(f.peak, p, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	switch prompt {
+	case "fail":
+		return "", errors.New("model overloaded")
+	case "panic":
+		panic("boom")
+	}
+	return f.model + ":" + prompt, nil
+}
+
+func collect(t *testing.T, p *Processor, reqs []Request) map[string]Result {
+	t.Helper()
+	var mu sync.Mutex
+	results := map[string]Result{}
+	err := p.Run(context.Background(), reqs, func(r Result) error {
+		mu.Lock()
+		defer mu.Unlock()
+		results[r.ID] = r
+		return nil
+	})
+	assert.NoError(t, err)
+	return results
+}
+
+func TestProcessor_Run_BoundedAndIsolatesFailures(t *testing.T) {
+	var inFlight, peak, created int32
+	p := &Processor{
+		Concurrency: 3,
+		Defaults:    llmConfig.Config{Provider: "ollama", Model: llmConfig.ModelConfig{Name: "phi4"}},
+		NewClient: func(cfg llmConfig.Config) (llm.LLM, error) {
+			atomic.AddInt32(&created, 1)
+			return &fakeLLM{model: cfg.Model.Name, inFlight: &inFlight, peak: &peak}, nil
+		},
+		Render: func(req Request) (string, error) { return "rendered-" + req.Topic, nil },
+	}
+
+	temp := 0.2
+	reqs := []Request{
+		{ID: "1", Prompt: "a"},
+		{ID: "2", Prompt: "fail"},
+		{ID: "3", Prompt: "panic"},
+		{ID: "4", Topic: "git"},
+		{ID: "5", Prompt: "b", Model: "llama3", Temperature: &temp},
+	}
+	for i := 6; i <= 12; i++ {
+		reqs = append(reqs, Request{ID: string(rune('a' + i)), Prompt: "x"})
+	}
+
+	results := collect(t, p, reqs)
+
+	assert.Len(t, results, len(reqs))
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(3))
+	assert.Equal(t, Result{ID: "1", Status: StatusOK, Provider: "ollama", Model: "phi4", Response: "phi4:a", LatencyMS: results["1"].LatencyMS}, results["1"])
+	assert.Equal(t, StatusError, results["2"].Status)
+	assert.Equal(t, "model overloaded", results["2"].Error)
+	assert.Contains(t, results["3"].Error, "internal LLM panic")
+	assert.Equal(t, "phi4:rendered-git", results["4"].Response)
+	assert.Equal(t, "llama3", results["5"].Model)
+	assert.Equal(t, "llama3:b", results["5"].Response)
+}
+
+func TestProcessor_Run_ClientAndRenderErrors(t *testing.T) {
+	p := &Processor{
+		Concurrency: 2,
+		NewClient:   func(llmConfig.Config) (llm.LLM, error) { return nil, errors.New("no server") },
+		Render:      func(Request) (string, error) { return "", errors.New("missing config") },
+	}
+
+	results := collect(t, p, []Request{{ID: "p", Prompt: "x"}, {ID: "t", Topic: "nope"}})
+	assert.Contains(t, results["p"].Error, "failed to create LLM client: no server")
+	assert.Contains(t, results["t"].Error, "render: missing config")
+}
+
+func TestProcessor_Run_EmitErrorStops(t *testing.T) {
+	var inFlight, peak int32
+	p := &Processor{
+		Concurrency: 1,
+		NewClient: func(llmConfig.Config) (llm.LLM, error) {
+			return &fakeLLM{inFlight: &inFlight, peak: &peak}, nil
+		},
+	}
+
+	err := p.Run(context.Background(), []Request{{ID: "1", Prompt: "a"}, {ID: "2", Prompt: "b"}}, func(Result) error {
+		return errors.New("disk full")
+	})
+	assert.ErrorContains(t, err, "failed to write result: disk full")
+}
+
+// fallbackLLM reports that a fallback backend answered.
+type fallbackLLM struct{}
+
+func (fallbackLLM) Chat(context.Context, string) (string, error) { return "ok", nil }
+func (fallbackLLM) LastBackend() llmConfig.Backend {
+	return llmConfig.Backend{Provider: "openai", Model: "gpt-4o-mini"}
+}
+
+func TestProcessor_Run_RecordsAnsweringBackend(t *testing.T) {
+	p := &Processor{
+		Defaults:  llmConfig.Config{Provider: "ollama", Model: llmConfig.ModelConfig{Name: "phi4"}},
+		NewClient: func(llmConfig.Config) (llm.LLM, error) { return fallbackLLM{}, nil },
+	}
+
+	res := collect(t, p, []Request{{ID: "1", Prompt: "q"}})["1"]
+	assert.Equal(t, StatusOK, res.Status)
+	assert.Equal(t, "openai", res.Provider)
+	assert.Equal(t, "gpt-4o-mini", res.Model)
+}
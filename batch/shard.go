@@ -0,0 +1,48 @@
+package batch
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// Shard selects a deterministic subset of requests so several machines can
+// split one input file. Index is 1-based; the zero value selects everything.
+type Shard struct {
+	Index int
+	Count int
+}
+
+// ParseShard parses "i/n", e.g. "2/8". An empty string selects everything.
+func ParseShard(s string) (Shard, error) {
+	if s == "" {
+		return Shard{}, nil
+	}
+	var sh Shard
+	var rest string
+	if n, _ := fmt.Sscanf(s, "%d/%d%s", &sh.Index, &sh.Count, &rest); n != 2 {
+		return Shard{}, fmt.Errorf("invalid shard %q: expected i/n, e.g. 2/8", s)
+	}
+	if sh.Count < 1 || sh.Index < 1 || sh.Index > sh.Count {
+		return Shard{}, fmt.Errorf("invalid shard %q: index must be between 1 and %d", s, sh.Count)
+	}
+	return sh, nil
+}
+
+// Includes reports whether the id belongs to this shard. Assignment depends
+// only on the id, so it is stable across reorderings of the input.
+func (s Shard) Includes(id string) bool {
+	if s.Count <= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32()%uint32(s.Count)) == s.Index-1
+}
+
+// String formats the shard as "i/n".
+func (s Shard) String() string {
+	if s.Count == 0 {
+		return "all"
+	}
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
@@ -0,0 +1,38 @@
+package batch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseShard(t *testing.T) {
+	sh, err := ParseShard("2/8")
+	assert.NoError(t, err)
+	assert.Equal(t, Shard{Index: 2, Count: 8}, sh)
+	assert.Equal(t, "2/8", sh.String())
+
+	sh, err = ParseShard("")
+	assert.NoError(t, err)
+	assert.Equal(t, "all", sh.String())
+
+	for _, bad := range []string{"2", "0/4", "5/4", "a/b", "1/4x", "1/0"} {
+		_, err := ParseShard(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestShard_IncludesIsDeterministicPartition(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	for _, id := range ids {
+		owners := 0
+		for i := 1; i <= 4; i++ {
+			if (Shard{Index: i, Count: 4}).Includes(id) {
+				owners++
+			}
+		}
+		assert.Equal(t, 1, owners, id)
+		assert.Equal(t, Shard{Index: 2, Count: 4}.Includes(id), Shard{Index: 2, Count: 4}.Includes(id))
+		assert.True(t, Shard{}.Includes(id))
+	}
+}
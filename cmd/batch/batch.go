@@ -0,0 +1,103 @@
+package batch
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/batch"
+	"raja.aiml/ai.explorer/cmd/llmflags"
+	promptCmd "raja.aiml/ai.explorer/cmd/prompt"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
+
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+)
+
+// Cobra command for `batch`
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Send many prompts from a JSONL file concurrently",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shard, err := batch.ParseShard(shardSpec)
+		if err != nil {
+			return err
+		}
+
+		runner := &BatchRunner{
+			Out:        cmd.OutOrStdout(),
+			InputPath:  inputPath,
+			OutputPath: outputPath,
+			Resume:     resume,
+			Shard:      shard,
+			Processor: &batch.Processor{
+				Concurrency: concurrency,
+				Defaults:    llmFlags.Config(),
+				NewClient: func(cfg llmConfig.Config) (llm.LLM, error) {
+					return llmFlags.NewClientFor(cfg)
+				},
+				Render: renderRequest,
+			},
+			OpenInput: func(path string) (io.ReadCloser, error) {
+				return os.Open(path)
+			},
+			OpenOutput:  openOutput,
+			LoadResults: batch.LoadResults,
+			Compact:     batch.Compact,
+		}
+		return runner.Run(cmd.Context())
+	},
+}
+
+// GetBatchCommand exposes the `batch` Cobra command.
+func GetBatchCommand() *cobra.Command {
+	return batchCmd
+}
+
+func init() {
+	batchCmd.Flags().StringVarP(&inputPath, "input", "i", DefaultInputPath, "JSONL file with one request per line")
+	batchCmd.Flags().StringVarP(&outputPath, "output", "o", DefaultOutputPath, "JSONL file to write one result per line")
+	batchCmd.Flags().IntVarP(&concurrency, "concurrency", "n", batch.DefaultConcurrency, "Number of concurrent workers")
+	batchCmd.Flags().BoolVar(&resume, "resume", false, "Skip ids that already succeeded in --output and retry the rest")
+	batchCmd.Flags().StringVar(&shardSpec, "shard", "", "Only process shard i of n, e.g. 2/8")
+	llmFlags.Register(batchCmd, llmflags.Defaults)
+}
+
+// openOutput creates the results file, or appends to it when resuming.
+func openOutput(path string, appendMode bool) (io.WriteCloser, error) {
+	if err := paths.EnsureDirectoryExists(path); err != nil {
+		return nil, err
+	}
+	if !appendMode {
+		return os.Create(path)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// Terminate a line cut short by a crash so new results start cleanly.
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := f.Write([]byte("\n")); err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+	}
+	return f, nil
+}
+
+// renderRequest renders a category/topic/query request through its template.
+func renderRequest(req batch.Request) (string, error) {
+	runner := &promptCmd.PromptRunner{
+		PromptCategory: req.Category,
+		Topic:          req.Topic,
+		UserQuery:      req.Query,
+	}
+	tmpl, cfg, _ := runner.ResolvePaths()
+	return prompt.DefaultRenderer.Render(context.Background(), prompt.Request{Template: tmpl, Config: cfg, Query: req.Query})
+}
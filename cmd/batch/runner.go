@@ -0,0 +1,138 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"raja.aiml/ai.explorer/batch"
+)
+
+// BatchRunner reads a JSONL request file, processes it and writes results.
+type BatchRunner struct {
+	Out         io.Writer
+	InputPath   string
+	OutputPath  string
+	Resume      bool
+	Shard       batch.Shard
+	Processor   *batch.Processor
+	OpenInput   func(path string) (io.ReadCloser, error)
+	OpenOutput  func(path string, appendMode bool) (io.WriteCloser, error)
+	LoadResults func(path string) (map[string]batch.Result, error)
+	Compact     func(path string) error
+}
+
+// Run processes every request. Individual failures are recorded in the
+// output file and summarised at the end instead of aborting the run.
+func (r *BatchRunner) Run(ctx context.Context) error {
+	requests, invalid, err := r.readRequests()
+	if err != nil {
+		return err
+	}
+
+	if r.Resume {
+		done, err := r.LoadResults(r.OutputPath)
+		if err != nil {
+			return fmt.Errorf("[batch] failed to load checkpoint: %w", err)
+		}
+		var skipped int
+		requests, skipped = batch.Pending(requests, done)
+		fmt.Fprintf(r.Out, "[batch] Resuming: %d already done, %d to run\n", skipped, len(requests)+len(invalid))
+	}
+
+	out, err := r.OpenOutput(r.OutputPath, r.Resume)
+	if err != nil {
+		return fmt.Errorf("[batch] failed to open output: %w", err)
+	}
+	failed, total, err := r.process(ctx, out, requests, invalid)
+	if cerr := out.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("[batch] failed to close output: %w", cerr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.Resume {
+		if err := r.Compact(r.OutputPath); err != nil {
+			return fmt.Errorf("[batch] %w", err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("[batch] %d of %d requests failed (rerun with --resume to retry them)", failed, total)
+	}
+	return nil
+}
+
+// readRequests loads the input and keeps only this shard's requests.
+func (r *BatchRunner) readRequests() ([]batch.Request, []batch.Result, error) {
+	in, err := r.OpenInput(r.InputPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[batch] failed to open input: %w", err)
+	}
+	defer in.Close()
+
+	requests, invalid, err := batch.ReadRequests(in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[batch] %w", err)
+	}
+
+	var keptReqs []batch.Request
+	for _, req := range requests {
+		if r.Shard.Includes(req.ID) {
+			keptReqs = append(keptReqs, req)
+		}
+	}
+	var keptInvalid []batch.Result
+	for _, res := range invalid {
+		if r.Shard.Includes(res.ID) {
+			keptInvalid = append(keptInvalid, res)
+		}
+	}
+	if r.Shard.Count > 1 {
+		fmt.Fprintf(r.Out, "[batch] Shard %s: %d of %d requests\n", r.Shard, len(keptReqs)+len(keptInvalid), len(requests)+len(invalid))
+	}
+	return keptReqs, keptInvalid, nil
+}
+
+// process runs the requests, writing each result as it completes.
+func (r *BatchRunner) process(ctx context.Context, out io.Writer, requests []batch.Request, invalid []batch.Result) (failed, total int, err error) {
+	writer := batch.NewWriter(out)
+
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	total = len(requests) + len(invalid)
+	emit := func(res batch.Result) error {
+		if err := writer.Write(res); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		if res.Status != batch.StatusOK {
+			failed++
+			fmt.Fprintf(r.Out, "[batch] %d/%d ✘ %s: %s\n", done, total, res.ID, res.Error)
+			return nil
+		}
+		fmt.Fprintf(r.Out, "[batch] %d/%d ✔ %s (%dms)\n", done, total, res.ID, res.LatencyMS)
+		return nil
+	}
+
+	fmt.Fprintf(r.Out, "[batch] Processing %d requests with %d workers...\n", total, r.Processor.Workers())
+	start := time.Now()
+	for _, res := range invalid {
+		if err := emit(res); err != nil {
+			return failed, total, fmt.Errorf("[batch] failed to write result: %w", err)
+		}
+	}
+	if err := r.Processor.Run(ctx, requests, emit); err != nil {
+		return failed, total, fmt.Errorf("[batch] %w", err)
+	}
+
+	fmt.Fprintf(r.Out, "[batch] 💾 %d ok, %d failed in %s. Results saved to: %s\n",
+		done-failed, failed, time.Since(start).Round(time.Millisecond), r.OutputPath)
+	return failed, total, nil
+}
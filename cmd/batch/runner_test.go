@@ -0,0 +1,175 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/batch"
+	"raja.aiml/ai.explorer/llm"
+
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+)
+
+type echoLLM struct{}
+
+func (echoLLM) Chat(_ context.Context, prompt string) (string, error) {
+	if prompt == "bad" {
+		return "", io.ErrUnexpectedEOF
+	}
+	return "echo:" + prompt, nil
+}
+
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestBatchRunner_Run(t *testing.T) {
+	input := `{"id":"one","prompt":"hi"}
+{"id":"two","prompt":"bad"}
+{broken
+`
+	var out, results bytes.Buffer
+	runner := &BatchRunner{
+		Out:        &out,
+		InputPath:  "in.jsonl",
+		OutputPath: "out.jsonl",
+		Processor: &batch.Processor{
+			Concurrency: 2,
+			NewClient:   func(llmConfig.Config) (llm.LLM, error) { return echoLLM{}, nil },
+		},
+		OpenInput: func(string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(input)), nil
+		},
+		OpenOutput: func(string, bool) (io.WriteCloser, error) { return nopCloser{&results}, nil },
+	}
+
+	err := runner.Run(context.Background())
+	assert.ErrorContains(t, err, "2 of 3 requests failed")
+
+	byID := map[string]batch.Result{}
+	for _, line := range strings.Split(strings.TrimSpace(results.String()), "\n") {
+		var r batch.Result
+		assert.NoError(t, json.Unmarshal([]byte(line), &r))
+		byID[r.ID] = r
+	}
+	assert.Len(t, byID, 3)
+	assert.Equal(t, "echo:hi", byID["one"].Response)
+	assert.Equal(t, batch.StatusError, byID["two"].Status)
+	assert.Equal(t, batch.StatusError, byID["line-3"].Status)
+	assert.Contains(t, out.String(), "1 ok, 2 failed")
+}
+
+func TestBatchRunner_Run_AllOK(t *testing.T) {
+	var results bytes.Buffer
+	runner := &BatchRunner{
+		Out:       &bytes.Buffer{},
+		Processor: &batch.Processor{NewClient: func(llmConfig.Config) (llm.LLM, error) { return echoLLM{}, nil }},
+		OpenInput: func(string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(`{"id":"x","prompt":"p"}`)), nil
+		},
+		OpenOutput: func(string, bool) (io.WriteCloser, error) { return nopCloser{&results}, nil },
+	}
+	assert.NoError(t, runner.Run(context.Background()))
+	assert.Contains(t, results.String(), `"status":"ok"`)
+}
+
+type flakyLLM struct{ calls *int32 }
+
+func (f flakyLLM) Chat(_ context.Context, prompt string) (string, error) {
+	atomic.AddInt32(f.calls, 1)
+	if prompt == "bad" && atomic.LoadInt32(f.calls) < 10 {
+		return "", io.ErrUnexpectedEOF
+	}
+	return "ok:" + prompt, nil
+}
+
+func TestBatchRunner_Run_ResumeRetriesOnlyFailures(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.jsonl")
+	output := filepath.Join(dir, "out.jsonl")
+	assert.NoError(t, os.WriteFile(input, []byte(`{"id":"good","prompt":"fine"}
+{"id":"flaky","prompt":"bad"}
+`), 0644))
+
+	var calls int32
+	newRunner := func(resume bool) *BatchRunner {
+		return &BatchRunner{
+			Out:        &bytes.Buffer{},
+			InputPath:  input,
+			OutputPath: output,
+			Resume:     resume,
+			Processor: &batch.Processor{
+				Concurrency: 1,
+				NewClient:   func(llmConfig.Config) (llm.LLM, error) { return flakyLLM{calls: &calls}, nil },
+			},
+			OpenInput:   func(p string) (io.ReadCloser, error) { return os.Open(p) },
+			OpenOutput:  openOutput,
+			LoadResults: batch.LoadResults,
+			Compact:     batch.Compact,
+		}
+	}
+
+	assert.Error(t, newRunner(false).Run(context.Background()))
+	assert.Equal(t, int32(2), calls)
+
+	// Pretend the process crashed while writing a line.
+	f, _ := os.OpenFile(output, os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = f.WriteString(`{"id":"trunc`)
+	f.Close()
+
+	calls = 9
+	assert.NoError(t, newRunner(true).Run(context.Background()))
+	assert.Equal(t, int32(10), calls, "only the failed request is retried")
+
+	results, err := batch.LoadResults(output)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, batch.StatusOK, results["flaky"].Status)
+
+	data, _ := os.ReadFile(output)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"), "output is compacted to one line per id")
+}
+
+func TestBatchRunner_Run_Shard(t *testing.T) {
+	var lines []string
+	for i := 0; i < 40; i++ {
+		lines = append(lines, fmt.Sprintf(`{"id":"req-%d","prompt":"p"}`, i))
+	}
+	input := strings.Join(lines, "\n")
+
+	seen := map[string]int{}
+	for i := 1; i <= 3; i++ {
+		var results bytes.Buffer
+		runner := &BatchRunner{
+			Out:       &bytes.Buffer{},
+			Shard:     batch.Shard{Index: i, Count: 3},
+			Processor: &batch.Processor{NewClient: func(llmConfig.Config) (llm.LLM, error) { return echoLLM{}, nil }},
+			OpenInput: func(string) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(input)), nil
+			},
+			OpenOutput: func(string, bool) (io.WriteCloser, error) { return nopCloser{&results}, nil },
+		}
+		assert.NoError(t, runner.Run(context.Background()))
+
+		got, _, err := batch.ReadResults(&results)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, got)
+		for id := range got {
+			seen[id]++
+		}
+	}
+
+	assert.Len(t, seen, 40, "shards cover every request")
+	for id, n := range seen {
+		assert.Equal(t, 1, n, "request %s processed by exactly one shard", id)
+	}
+}
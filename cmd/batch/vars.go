@@ -0,0 +1,21 @@
+package batch
+
+import (
+	"raja.aiml/ai.explorer/cmd/llmflags"
+)
+
+// Default file paths
+const (
+	DefaultInputPath  = "requests.jsonl"
+	DefaultOutputPath = "results.jsonl"
+)
+
+// CLI flags
+var (
+	inputPath   string
+	outputPath  string
+	concurrency int
+	resume      bool
+	shardSpec   string
+	llmFlags    llmflags.Flags
+)
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+)
+
+// DefaultMaxAge is how old an entry must be before `cache prune` removes it.
+const DefaultMaxAge = 7 * 24 * time.Hour
+
+// CLI flags
+var (
+	cacheDir string
+	maxAge   time.Duration
+)
+
+// Cobra command for `cache`
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and manage the on-disk LLM response cache",
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the number and size of cached responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStats(cmd.OutOrStdout(), llm.NewCache(cacheDir))
+	},
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached responses older than --older-than",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := llm.NewCache(cacheDir).Prune(maxAge)
+		if err != nil {
+			return fmt.Errorf("failed to prune cache: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d entries older than %s\n", removed, maxAge)
+		return nil
+	},
+}
+
+var clearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached response",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := llm.NewCache(cacheDir).Clear()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Cleared %d entries from %s\n", removed, cacheDir)
+		return nil
+	},
+}
+
+// GetCacheCommand exposes the `cache` Cobra command.
+func GetCacheCommand() *cobra.Command {
+	return cacheCmd
+}
+
+func init() {
+	cacheCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", llm.DefaultCacheDir, "Directory for cached responses")
+	pruneCmd.Flags().DurationVar(&maxAge, "older-than", DefaultMaxAge, "Remove entries older than this duration")
+
+	cacheCmd.AddCommand(statsCmd, pruneCmd, clearCmd)
+}
+
+// printStats writes a short summary of the cache contents.
+func printStats(out io.Writer, cache *llm.Cache) error {
+	stats, err := cache.Stats()
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+	fmt.Fprintf(out, "Cache:   %s\n", cache.Dir)
+	fmt.Fprintf(out, "Entries: %d\n", stats.Entries)
+	fmt.Fprintf(out, "Size:    %s\n", humanBytes(stats.Bytes))
+	if stats.Entries > 0 {
+		fmt.Fprintf(out, "Oldest:  %s\n", stats.Oldest.Format(time.DateTime))
+		fmt.Fprintf(out, "Newest:  %s\n", stats.Newest.Format(time.DateTime))
+	}
+	return nil
+}
+
+// humanBytes formats a byte count using binary units.
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/llm"
+)
+
+func TestCacheCommand_Metadata(t *testing.T) {
+	cmd := GetCacheCommand()
+	assert.Equal(t, "cache", cmd.Use)
+	names := []string{}
+	for _, sub := range cmd.Commands() {
+		names = append(names, sub.Name())
+	}
+	assert.ElementsMatch(t, []string{"stats", "prune", "clear"}, names)
+}
+
+func TestPrintStats(t *testing.T) {
+	cache := llm.NewCache(t.TempDir())
+	key := llm.CacheKey{Provider: "ollama", Model: "phi4"}
+	assert.NoError(t, cache.Put(cache.Hash(key, "q"), key, "q", "a"))
+
+	var buf bytes.Buffer
+	assert.NoError(t, printStats(&buf, cache))
+	assert.Contains(t, buf.String(), "Entries: 1")
+	assert.Contains(t, buf.String(), "Newest:")
+}
+
+func TestHumanBytes(t *testing.T) {
+	assert.Equal(t, "512 B", humanBytes(512))
+	assert.Equal(t, "1.5 KiB", humanBytes(1536))
+	assert.Equal(t, "2.0 MiB", humanBytes(2*1024*1024))
+}
@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/cmd/llmflags"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/paths"
+)
+
+// Cobra command for `chat`
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Start an interactive multi-turn chat with an LLM",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &ChatRunner{
+			In:             cmd.InOrStdin(),
+			Out:            cmd.OutOrStdout(),
+			History:        llm.NewHistory(systemPrompt),
+			Model:          llmFlags.Model,
+			NewClient:      newClient,
+			SaveTranscript: saveTranscript,
+			// Ctrl-C during a turn aborts that turn; at the prompt it
+			// still ends the chat.
+			TurnContext: func() (context.Context, context.CancelFunc) {
+				return signal.NotifyContext(cmd.Context(), os.Interrupt)
+			},
+		}
+		return runner.Run()
+	},
+}
+
+// GetChatCommand exposes the `chat` Cobra command.
+func GetChatCommand() *cobra.Command {
+	return chatCmd
+}
+
+func init() {
+	chatCmd.Flags().StringVarP(&systemPrompt, "system", "s", "", "Initial system prompt")
+	llmFlags.Register(chatCmd, llmflags.Defaults)
+}
+
+// newClient builds a streaming client for the given model.
+func newClient(model string, stream llm.StreamHandler) (llm.Conversational, error) {
+	flags := llmFlags
+	flags.Model = model
+	client, err := flags.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	client.SetStreamHandler(stream)
+	return client, nil
+}
+
+// saveTranscript writes the conversation transcript to the specified file.
+func saveTranscript(content, path string) error {
+	if err := paths.EnsureDirectoryExists(path); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
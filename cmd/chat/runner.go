@@ -0,0 +1,159 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"raja.aiml/ai.explorer/llm"
+)
+
+const helpText = `Commands:
+  /system [text]  Show or replace the system prompt
+  /reset          Clear the conversation (keeps the system prompt)
+  /save [path]    Save the transcript as Markdown
+  /model [name]   Show or switch the model
+  /help           Show this help
+  /exit           Leave the chat`
+
+// ChatRunner drives an interactive, multi-turn conversation.
+type ChatRunner struct {
+	In             io.Reader
+	Out            io.Writer
+	History        *llm.History
+	Model          string
+	NewClient      func(model string, stream llm.StreamHandler) (llm.Conversational, error)
+	SaveTranscript func(content, path string) error
+	// TurnContext returns the context of one turn and the function that
+	// releases it. The command cancels it on Ctrl-C, so a slow turn can be
+	// aborted without leaving the chat. It defaults to context.Background.
+	TurnContext func() (context.Context, context.CancelFunc)
+
+	client   llm.Conversational
+	streamed bool
+}
+
+// Run reads user input until EOF or /exit.
+func (r *ChatRunner) Run() error {
+	if err := r.connect(r.Model); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.Out, "[chat] Talking to %s. Type /help for commands.\n", r.Model)
+
+	scanner := bufio.NewScanner(r.In)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for {
+		fmt.Fprint(r.Out, "you> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(r.Out)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "/"):
+			if quit := r.command(line); quit {
+				return nil
+			}
+		default:
+			r.turn(line)
+		}
+	}
+}
+
+// turn sends one user message and streams the assistant reply.
+func (r *ChatRunner) turn(text string) {
+	r.History.AddUser(text)
+	r.streamed = false
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if r.TurnContext != nil {
+		ctx, cancel = r.TurnContext()
+	}
+	defer cancel()
+
+	fmt.Fprint(r.Out, "assistant> ")
+	reply, err := r.client.ChatMessages(ctx, r.History.Messages())
+	if err != nil {
+		r.History.Pop()
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(r.Out, "\n[chat] Turn cancelled")
+			return
+		}
+		fmt.Fprintf(r.Out, "\n[chat] LLM error: %v\n", err)
+		return
+	}
+	if !r.streamed {
+		fmt.Fprint(r.Out, reply)
+	}
+	fmt.Fprintln(r.Out)
+	r.History.AddAssistant(reply)
+}
+
+// command handles a slash command and reports whether the chat should end.
+func (r *ChatRunner) command(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/exit", "/quit":
+		return true
+	case "/help":
+		fmt.Fprintln(r.Out, helpText)
+	case "/system":
+		if arg == "" {
+			fmt.Fprintf(r.Out, "[chat] System prompt: %q\n", r.History.System())
+			return false
+		}
+		r.History.SetSystem(arg)
+		fmt.Fprintln(r.Out, "[chat] System prompt updated")
+	case "/reset":
+		r.History.Reset()
+		fmt.Fprintln(r.Out, "[chat] Conversation cleared")
+	case "/save":
+		if arg == "" {
+			arg = DefaultTranscriptPath
+		}
+		if err := r.SaveTranscript(r.History.Transcript(), arg); err != nil {
+			fmt.Fprintf(r.Out, "[chat] Failed to save transcript: %v\n", err)
+			return false
+		}
+		fmt.Fprintf(r.Out, "[chat] 💾 Transcript saved to: %s\n", arg)
+	case "/model":
+		if arg == "" {
+			fmt.Fprintf(r.Out, "[chat] Model: %s\n", r.Model)
+			return false
+		}
+		if err := r.connect(arg); err != nil {
+			fmt.Fprintf(r.Out, "[chat] %v\n", err)
+			return false
+		}
+		fmt.Fprintf(r.Out, "[chat] Switched to %s\n", r.Model)
+	default:
+		fmt.Fprintf(r.Out, "[chat] Unknown command %s (try /help)\n", name)
+	}
+	return false
+}
+
+// connect (re)creates the client for the given model.
+func (r *ChatRunner) connect(model string) error {
+	client, err := r.NewClient(model, r.streamChunk)
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	r.client = client
+	r.Model = model
+	return nil
+}
+
+// streamChunk writes streamed output as it arrives.
+func (r *ChatRunner) streamChunk(_ context.Context, chunk []byte) error {
+	r.streamed = true
+	_, err := r.Out.Write(chunk)
+	return err
+}
@@ -0,0 +1,134 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// --- Mock Implementation ---
+
+type mockClient struct {
+	model  string
+	stream llm.StreamHandler
+	calls  [][]wrapper.MessageContent
+	err    error
+}
+
+func (m *mockClient) ChatMessages(ctx context.Context, msgs []wrapper.MessageContent) (string, error) {
+	m.calls = append(m.calls, msgs)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if m.err != nil {
+		return "", m.err
+	}
+	reply := "reply from " + m.model
+	_ = m.stream(ctx, []byte(reply))
+	return reply, nil
+}
+
+func newTestRunner(input string) (*ChatRunner, *bytes.Buffer, *[]*mockClient) {
+	var out bytes.Buffer
+	var clients []*mockClient
+	runner := &ChatRunner{
+		In:      strings.NewReader(input),
+		Out:     &out,
+		History: llm.NewHistory("be brief"),
+		Model:   "phi4",
+		NewClient: func(model string, stream llm.StreamHandler) (llm.Conversational, error) {
+			c := &mockClient{model: model, stream: stream}
+			clients = append(clients, c)
+			return c, nil
+		},
+	}
+	return runner, &out, &clients
+}
+
+// --- Tests ---
+
+func TestChatRunner_KeepsRoleAwareHistory(t *testing.T) {
+	runner, out, clients := newTestRunner("hello\nand again\n")
+
+	assert.NoError(t, runner.Run())
+
+	calls := (*clients)[0].calls
+	assert.Len(t, calls, 2)
+	second := calls[1]
+	assert.Len(t, second, 4)
+	assert.Equal(t, wrapper.RoleSystem, second[0].Role)
+	assert.Equal(t, wrapper.RoleUser, second[1].Role)
+	assert.Equal(t, wrapper.RoleAssistant, second[2].Role)
+	assert.Equal(t, "and again", wrapper.MessageText(second[3]))
+	assert.Equal(t, 2, strings.Count(out.String(), "assistant> reply from phi4\n"))
+}
+
+func TestChatRunner_SlashCommands(t *testing.T) {
+	input := "/system be verbose\nq1\n/reset\n/model llama3\nq2\n/save out.md\n/bogus\n/exit\nnever sent\n"
+	runner, out, clients := newTestRunner(input)
+
+	var savedPath, saved string
+	runner.SaveTranscript = func(content, path string) error {
+		saved, savedPath = content, path
+		return nil
+	}
+
+	assert.NoError(t, runner.Run())
+
+	assert.Len(t, *clients, 2)
+	assert.Equal(t, "llama3", runner.Model)
+
+	afterReset := (*clients)[1].calls[0]
+	assert.Len(t, afterReset, 2)
+	assert.Equal(t, "be verbose", wrapper.MessageText(afterReset[0]))
+
+	assert.Equal(t, "out.md", savedPath)
+	assert.Contains(t, saved, "### Assistant\n\nreply from llama3")
+	assert.Contains(t, out.String(), "Unknown command /bogus")
+	assert.NotContains(t, out.String(), "never sent")
+}
+
+func TestChatRunner_FailedTurnIsDropped(t *testing.T) {
+	runner, out, clients := newTestRunner("hello\n")
+	runner.NewClient = func(model string, stream llm.StreamHandler) (llm.Conversational, error) {
+		c := &mockClient{model: model, stream: stream, err: errors.New("model busy")}
+		*clients = append(*clients, c)
+		return c, nil
+	}
+
+	assert.NoError(t, runner.Run())
+	assert.Contains(t, out.String(), "LLM error: model busy")
+	assert.Equal(t, 1, runner.History.Len())
+}
+
+func TestChatRunner_CancelledTurnKeepsTheChat(t *testing.T) {
+	runner, out, clients := newTestRunner("slow\nagain\n")
+	turns := 0
+	runner.TurnContext = func() (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		if turns++; turns == 1 {
+			cancel() // Ctrl-C during the first turn
+		}
+		return ctx, cancel
+	}
+
+	assert.NoError(t, runner.Run())
+	assert.Contains(t, out.String(), "[chat] Turn cancelled")
+	assert.NotContains(t, out.String(), "LLM error")
+	assert.Len(t, (*clients)[0].calls, 2, "the chat goes on after a cancelled turn")
+	assert.Equal(t, 3, runner.History.Len(), "only the second turn is kept")
+}
+
+func TestChatRunner_ClientError(t *testing.T) {
+	runner, _, _ := newTestRunner("")
+	runner.NewClient = func(string, llm.StreamHandler) (llm.Conversational, error) {
+		return nil, errors.New("no server")
+	}
+	assert.ErrorContains(t, runner.Run(), "no server")
+}
@@ -0,0 +1,16 @@
+package chat
+
+import (
+	"raja.aiml/ai.explorer/cmd/llmflags"
+)
+
+// Default values
+const (
+	DefaultTranscriptPath = "chat-transcript.md"
+)
+
+// CLI flags
+var (
+	systemPrompt string
+	llmFlags     llmflags.Flags
+)
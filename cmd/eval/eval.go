@@ -0,0 +1,91 @@
+package eval
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/cmd/llmflags"
+	"raja.aiml/ai.explorer/eval"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+// Cobra command for `eval`
+var evalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Score the router template against its ground-truth dataset",
+	Long: `Score the router template against its ground-truth dataset.
+
+Each row's query is rendered into the router template and sent to the model.
+The reply's Final Classification and Metadata are compared with the row's
+expected_technique, query_complexity, task_type, tone, urgency_level and
+user_type columns.
+
+expected_intent is not scored: the router template asks the model for
+intent_keywords, not for an intent label to compare it with.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tmpl, cfg := resolveTemplatePaths()
+		client, err := llmFlags.NewClient()
+		if err != nil {
+			return err
+		}
+
+		runner := &EvalRunner{
+			Out:         cmd.OutOrStdout(),
+			DatasetPath: datasetPath,
+			OutputPath:  outputPath,
+			Limit:       limit,
+			Fields:      eval.RouterFields,
+			LoadCases:   eval.LoadCases,
+			Render: func(query string) (string, error) {
+				return prompt.DefaultRenderer.Render(context.Background(), prompt.Request{Template: tmpl, Config: cfg, Query: query})
+			},
+			Chat: func(p string) (string, error) {
+				return client.Chat(context.Background(), p)
+			},
+			SaveReport: saveReport,
+		}
+		return runner.Run()
+	},
+}
+
+// GetEvalCommand exposes the `eval` Cobra command.
+func GetEvalCommand() *cobra.Command {
+	return evalCmd
+}
+
+func init() {
+	evalCmd.Flags().StringVar(&datasetPath, "dataset", eval.DefaultDatasetPath, "Ground-truth CSV file")
+	evalCmd.Flags().StringVar(&templatePath, "template", "", "Path to template YAML (default: classification/router)")
+	evalCmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML (default: classification/router)")
+	evalCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output file path to save the report")
+	evalCmd.Flags().IntVar(&limit, "limit", 0, "Only evaluate the first N rows (0 = all)")
+
+	defaults := llmflags.Defaults
+	defaults.Temperature = DefaultTemperature
+	llmFlags.Register(evalCmd, defaults)
+}
+
+// resolveTemplatePaths fills in the router template and config when not set.
+func resolveTemplatePaths() (tmpl, cfg string) {
+	resolver := paths.PathResolver{PromptCategory: DefaultCategory}
+	defTmpl, defCfg, _ := resolver.Derive(DefaultTopic)
+
+	tmpl, cfg = templatePath, configPath
+	if tmpl == "" {
+		tmpl = defTmpl
+	}
+	if cfg == "" {
+		cfg = defCfg
+	}
+	return
+}
+
+// saveReport writes the evaluation report to the specified file.
+func saveReport(report, path string) error {
+	if err := paths.EnsureDirectoryExists(path); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(report), 0644)
+}
@@ -0,0 +1,71 @@
+package eval
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"raja.aiml/ai.explorer/eval"
+)
+
+// EvalRunner scores a template against a ground-truth dataset.
+type EvalRunner struct {
+	Out         io.Writer
+	DatasetPath string
+	OutputPath  string
+	Limit       int
+	Fields      []eval.Field
+	LoadCases   func(string) ([]eval.Case, error)
+	Render      func(query string) (string, error)
+	Chat        func(prompt string) (string, error)
+	SaveReport  func(report, path string) error
+}
+
+// Run renders, sends and scores every case, then prints the report.
+// A failing case is recorded in the report instead of aborting the run.
+func (r *EvalRunner) Run() error {
+	cases, err := r.LoadCases(r.DatasetPath)
+	if err != nil {
+		return fmt.Errorf("[eval] dataset error: %w", err)
+	}
+	if r.Limit > 0 && r.Limit < len(cases) {
+		cases = cases[:r.Limit]
+	}
+
+	results := make([]eval.Result, 0, len(cases))
+	for i, c := range cases {
+		fmt.Fprintf(r.Out, "[eval] %d/%d %q\n", i+1, len(cases), c.Query)
+		results = append(results, r.evaluate(c))
+	}
+
+	var buf bytes.Buffer
+	eval.Score(results, r.Fields).Write(&buf)
+	fmt.Fprintln(r.Out)
+	fmt.Fprint(r.Out, buf.String())
+
+	if r.OutputPath != "" {
+		if err := r.SaveReport(buf.String(), r.OutputPath); err != nil {
+			return fmt.Errorf("[eval] failed to save report: %w", err)
+		}
+		fmt.Fprintf(r.Out, "[eval] 💾 Report saved to: %s\n", r.OutputPath)
+	}
+	return nil
+}
+
+// evaluate runs a single case through render → chat → parse.
+func (r *EvalRunner) evaluate(c eval.Case) eval.Result {
+	result := eval.Result{Case: c}
+
+	prompt, err := r.Render(c.Query)
+	if err != nil {
+		result.Err = fmt.Errorf("render: %w", err)
+		return result
+	}
+	reply, err := r.Chat(prompt)
+	if err != nil {
+		result.Err = fmt.Errorf("llm: %w", err)
+		return result
+	}
+	result.Predicted, result.Err = eval.ParseReply(reply)
+	return result
+}
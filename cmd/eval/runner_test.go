@@ -0,0 +1,72 @@
+package eval
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/eval"
+)
+
+func testCases(string) ([]eval.Case, error) {
+	return []eval.Case{
+		{Row: 1, Query: "ok", Expected: map[string]string{"expected_technique": "Zero-shot"}},
+		{Row: 2, Query: "boom", Expected: map[string]string{"expected_technique": "Step-back"}},
+	}, nil
+}
+
+func TestEvalRunner_Run_ScoresAndSurvivesFailures(t *testing.T) {
+	var out bytes.Buffer
+	var saved, savedPath string
+
+	runner := &EvalRunner{
+		Out:         &out,
+		DatasetPath: "data.csv",
+		OutputPath:  "report.txt",
+		Fields:      eval.RouterFields[:1],
+		LoadCases:   testCases,
+		Render:      func(q string) (string, error) { return "prompt:" + q, nil },
+		Chat: func(p string) (string, error) {
+			if p == "prompt:boom" {
+				return "", errors.New("model unavailable")
+			}
+			return "Final Classification: Zero-shot", nil
+		},
+		SaveReport: func(report, path string) error { saved, savedPath = report, path; return nil },
+	}
+
+	err := runner.Run()
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "[eval] 2/2")
+	assert.Contains(t, out.String(), "50.0%")
+	assert.Contains(t, out.String(), "model unavailable")
+	assert.Equal(t, "report.txt", savedPath)
+	assert.Contains(t, saved, "Misclassified rows (1)")
+}
+
+func TestEvalRunner_Run_Limit(t *testing.T) {
+	var prompts []string
+	runner := &EvalRunner{
+		Out:       &bytes.Buffer{},
+		Limit:     1,
+		Fields:    eval.RouterFields[:1],
+		LoadCases: testCases,
+		Render:    func(q string) (string, error) { return q, nil },
+		Chat: func(p string) (string, error) {
+			prompts = append(prompts, p)
+			return "Final Classification: Zero-shot", nil
+		},
+	}
+
+	assert.NoError(t, runner.Run())
+	assert.Equal(t, []string{"ok"}, prompts)
+}
+
+func TestEvalRunner_Run_DatasetError(t *testing.T) {
+	runner := &EvalRunner{
+		Out:       &bytes.Buffer{},
+		LoadCases: func(string) ([]eval.Case, error) { return nil, errors.New("missing") },
+	}
+	assert.ErrorContains(t, runner.Run(), "dataset error")
+}
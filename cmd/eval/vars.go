@@ -0,0 +1,22 @@
+package eval
+
+import (
+	"raja.aiml/ai.explorer/cmd/llmflags"
+)
+
+// Default values
+const (
+	DefaultCategory    = "classification"
+	DefaultTopic       = "router"
+	DefaultTemperature = 0.0
+)
+
+// CLI flags
+var (
+	datasetPath  string
+	templatePath string
+	configPath   string
+	outputPath   string
+	limit        int
+	llmFlags     llmflags.Flags
+)
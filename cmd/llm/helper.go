@@ -1,10 +1,14 @@
 package llm
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 
 	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
 )
 
 // getPrompt reads the prompt from a file and returns its content as a string.
@@ -18,6 +22,25 @@ func getPrompt(path string) (string, error) {
 
 // saveResponse writes the LLM response to the specified file.
 func saveResponse(response, path string) error {
-	paths.EnsureDirectoryExists(path)
+	if err := paths.EnsureDirectoryExists(path); err != nil {
+		return err
+	}
 	return os.WriteFile(path, []byte(response), 0644)
 }
+
+// recommendedModel returns the model recommended by the prompt's template:
+// --template if set, otherwise a template.yaml beside the prompt file.
+func recommendedModel(promptPath, templatePath string) (prompt.Recommended, error) {
+	path := templatePath
+	if path == "" {
+		path = filepath.Join(filepath.Dir(promptPath), "template.yaml")
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return prompt.Recommended{}, nil
+		}
+	}
+	tpl, err := prompt.DefaultBuilder.LoadTemplate(path)
+	if err != nil {
+		return prompt.Recommended{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return tpl.Spec.Recommended, nil
+}
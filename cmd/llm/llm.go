@@ -6,25 +6,38 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-	"raja.aiml/ai.explorer/llm"
-
-	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/cmd/llmflags"
+	"raja.aiml/ai.explorer/session"
+	"raja.aiml/ai.explorer/tokens"
 )
 
 // Cobra command for `llm`
 var llmCmd = &cobra.Command{
 	Use:   "llm",
 	Short: "Send a raw prompt to LLM",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rec, err := recommendedModel(promptPath, templatePath)
+		if err != nil {
+			return fmt.Errorf("[llm] Template error: %w", err)
+		}
+		llmFlags.Recommend(cmd, os.Stdout, "[llm]", rec)
+
+		runLLM := runLLMInteraction
+		if sessionName != "" {
+			runLLM = func(prompt string) (string, error) {
+				return runSessionInteraction(cmd, prompt)
+			}
+		}
+
 		runner := &LLMRunner{
 			Out:          os.Stdout,
 			PromptPath:   promptPath,
 			OutputPath:   outputPath,
 			GetPrompt:    getPrompt,
-			RunLLM:       runLLMInteraction,
+			RunLLM:       runLLM,
 			SaveResponse: saveResponse,
 		}
-		runner.Run()
+		return runner.Run()
 	},
 }
 
@@ -34,45 +47,53 @@ func GetLLMCommand() *cobra.Command {
 }
 
 func init() {
-	llmCmd.Flags().StringVarP(&providerName, "provider", "l", DefaultProvider, "LLM provider")
-	llmCmd.Flags().StringVarP(&modelName, "model", "m", DefaultModel, "LLM model")
-	llmCmd.Flags().Float64VarP(&temperature, "temperature", "t", DefaultTemperature, "Temperature")
+	llmFlags.Register(llmCmd, llmflags.Flags{
+		Provider:    DefaultProvider,
+		Model:       DefaultModel,
+		Temperature: DefaultTemperature,
+		Timeout:     DefaultTimeout,
+		MaxAttempts: llmflags.Defaults.MaxAttempts,
+		Verbose:     true,
+		CacheDir:    llmflags.Defaults.CacheDir,
+	})
 	llmCmd.Flags().StringVarP(&promptPath, "prompt", "p", DefaultPromptPath, "Prompt file")
-	llmCmd.Flags().DurationVarP(&timeout, "timeout", "d", DefaultTimeout, "Timeout duration")
 	llmCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output file path to save LLM response")
-	// Optional: override Ollama server URL if not using OLLAMA_HOST env var
-	llmCmd.Flags().StringVar(&serverURL, "server-url", "", "Ollama server URL (e.g. http://localhost:11434)")
+	llmCmd.Flags().StringVar(&templatePath, "template", "", "Template whose recommended model to use (default: template.yaml beside the prompt)")
+	llmCmd.Flags().StringVar(&sessionName, "session", "", "Resume or start a named conversation session")
+	llmCmd.Flags().StringVar(&sessionDir, "session-dir", session.DefaultDir, "Directory where sessions are stored")
+	llmCmd.Flags().StringVar(&pricesPath, "prices", "", "YAML price table extending the built-in prices")
+	llmCmd.Flags().StringVar(&onOverflow, "on-overflow", string(tokens.OverflowError),
+		"What to do when the prompt exceeds the smallest context window of the models (with --fallback): error, truncate-head, truncate-middle or truncate-query")
+	llmCmd.Flags().StringVar(&windowsPath, "context-windows", "", "YAML context-window registry extending the built-in one")
+	llmCmd.Flags().StringVarP(&userQuery, "query", "q", "", "User query as it appears in the prompt (used by --on-overflow=truncate-query)")
 }
 
 // runLLMInteraction initializes the LLM client and returns the response for the given prompt.
 func runLLMInteraction(prompt string) (string, error) {
-	cfg := llmConfig.Config{
-		Provider: providerName,
-		Model: llmConfig.ModelConfig{
-			Name:        modelName,
-			Temperature: temperature,
-		},
-		Client: llmConfig.ClientConfig{
-			Timeout:        timeout,
-			VerboseLogging: true,
-		},
-	}
-
-	// If using Ollama, ensure a host is configured via env or flag
-	if providerName == "ollama" && os.Getenv("OLLAMA_HOST") == "" && serverURL == "" {
-		return "", fmt.Errorf("ollama selected but neither OLLAMA_HOST nor --server-url provided")
+	client, err := llmFlags.NewClient()
+	if err != nil {
+		return "", err
 	}
-	// Override OLLAMA_HOST env var if server-url flag is set
-	if serverURL != "" {
-		os.Setenv("OLLAMA_HOST", serverURL)
+	prices, err := tokens.LoadPriceTable(pricesPath)
+	if err != nil {
+		return "", err
 	}
-	client, err := llm.NewDefaultClient(cfg)
+	guard, err := newGuard()
 	if err != nil {
-		return "", fmt.Errorf("failed to create LLM client: %w", err)
+		return "", err
 	}
+	if prompt, err = fitPrompt(os.Stderr, guard, prompt); err != nil {
+		return "", err
+	}
+	promptTokens := reportPromptTokens(os.Stderr, prices, prompt)
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	return client.Chat(ctx, prompt)
+	// --timeout bounds each attempt; a deadline over the whole call would
+	// leave nothing for retries and fallbacks once one attempt timed out.
+	response, err := client.Chat(context.Background(), prompt)
+	if err != nil {
+		return "", err
+	}
+	llmFlags.ReportFallback(os.Stderr, "[llm]", client)
+	reportUsage(os.Stderr, prices, client, promptTokens, response)
+	return response, nil
 }
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"fmt"
+	"io"
+
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// newGuard builds the context-window guard from the CLI flags.
+func newGuard() (*tokens.Guard, error) {
+	policy, err := tokens.ParseOverflowPolicy(onOverflow)
+	if err != nil {
+		return nil, err
+	}
+	windows, err := tokens.LoadContextWindows(windowsPath)
+	if err != nil {
+		return nil, err
+	}
+	return &tokens.Guard{Counter: tokens.Default, Windows: windows, Policy: policy}, nil
+}
+
+// tightestBackend returns the backend of the --fallback chain with the
+// smallest known context window, so the prompt fits whichever one answers.
+// It is the primary when the chain has no known window.
+func tightestBackend(guard *tokens.Guard) llmConfig.Backend {
+	backends := llmFlags.Config().Backends()
+	tightest, smallest := backends[0], 0
+	for _, b := range backends {
+		if window, ok := guard.Windows.Lookup(b.Provider, b.Model); ok && (smallest == 0 || window < smallest) {
+			tightest, smallest = b, window
+		}
+	}
+	return tightest
+}
+
+// fitPrompt checks the prompt against the smallest context window of the
+// backends, applying the --on-overflow policy and reporting any truncation.
+func fitPrompt(w io.Writer, guard *tokens.Guard, prompt string) (string, error) {
+	b := tightestBackend(guard)
+	fit, err := guard.Fit(b.Provider, b.Model, prompt, userQuery)
+	if err != nil && guard.Policy == tokens.OverflowError {
+		return "", fmt.Errorf("%w (raise the limit with --context-windows or choose a truncate policy with --on-overflow)", err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if fit.Truncated {
+		fmt.Fprintf(w, "[llm] ✂️ Prompt truncated (%s): %s → %s tokens, %s removed to fit %s:%s's %s-token context window\n",
+			guard.Policy, tokens.FormatInt(fit.Original), tokens.FormatInt(fit.Tokens), tokens.FormatInt(fit.Removed()),
+			b.Provider, b.Model, tokens.FormatInt(fit.Window))
+	}
+	return fit.Prompt, nil
+}
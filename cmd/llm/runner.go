@@ -3,7 +3,8 @@ package llm
 import (
 	"fmt"
 	"io"
-	"log"
+
+	"raja.aiml/ai.explorer/prompt"
 )
 
 // LLMRunner handles prompt loading, LLM interaction, and output.
@@ -17,13 +18,12 @@ type LLMRunner struct {
 }
 
 // Run executes the LLM flow.
-// Run executes the LLM flow.
-func (r *LLMRunner) Run() {
+func (r *LLMRunner) Run() error {
 	var err error
 	fmt.Fprintln(r.Out, "[llm] Reading prompt...")
-	prompt, err := r.GetPrompt(r.PromptPath)
+	text, err := r.GetPrompt(r.PromptPath)
 	if err != nil {
-		log.Fatalf("[llm] Prompt error: %v", err)
+		return fmt.Errorf("[llm] Prompt error: %w", err)
 	}
 
 	fmt.Fprintln(r.Out, "[llm] Running LLM...")
@@ -36,17 +36,17 @@ func (r *LLMRunner) Run() {
 				err = fmt.Errorf("internal LLM panic: %v", rec)
 			}
 		}()
-		resp, err = r.RunLLM(prompt)
+		resp, err = r.RunLLM(text)
 	}()
 	if err != nil {
-		log.Fatalf("[llm] LLM error: %v", err)
+		return fmt.Errorf("[llm] LLM error: %w", err)
 	}
 
 	if r.OutputPath != "" {
-		err := r.SaveResponse(resp, r.OutputPath)
-		if err != nil {
-			log.Fatalf("[llm] Failed to save response: %v", err)
+		if err := r.SaveResponse(resp, r.OutputPath); err != nil {
+			return &prompt.WriteError{Path: r.OutputPath, Err: err}
 		}
 		fmt.Fprintf(r.Out, "[llm] 💾 LLM response saved to: %s\n", r.OutputPath)
 	}
+	return nil
 }
@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm/wrapper"
+	"raja.aiml/ai.explorer/session"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// runSessionInteraction sends the prompt as the next turn of a stored session
+// and persists both the prompt and the reply.
+func runSessionInteraction(cmd *cobra.Command, prompt string) (string, error) {
+	store := session.NewStore(sessionDir)
+	sess, created, err := store.Open(sessionName)
+	if err != nil {
+		return "", err
+	}
+	if !created {
+		adoptSessionSettings(cmd, sess)
+	}
+
+	client, err := llmFlags.NewClient()
+	if err != nil {
+		return "", err
+	}
+	prices, err := tokens.LoadPriceTable(pricesPath)
+	if err != nil {
+		return "", err
+	}
+	guard, err := newGuard()
+	if err != nil {
+		return "", err
+	}
+
+	history := sess.History()
+	history.AddUser(prompt)
+	// Truncating would rewrite stored turns, so a session that has
+	// outgrown the context window always fails.
+	b := tightestBackend(guard)
+	if err := guard.Check(b.Provider, b.Model, history.Transcript()); err != nil {
+		return "", fmt.Errorf("%w (start a new --session or raise the limit with --context-windows)", err)
+	}
+	promptTokens := reportPromptTokens(os.Stderr, prices, history.Transcript())
+
+	// --timeout bounds each attempt; a deadline over the whole call would
+	// leave nothing for retries and fallbacks once one attempt timed out.
+	response, err := client.ChatMessages(context.Background(), history.Messages())
+	if err != nil {
+		return "", err
+	}
+	llmFlags.ReportFallback(os.Stderr, "[llm]", client)
+	reportUsage(os.Stderr, prices, client, promptTokens, response)
+
+	// The session keeps the primary, so the next turn tries it again; the
+	// reply records the backend that answered, a fallback if it was down.
+	sess.Provider, sess.Model, sess.Temperature = llmFlags.Provider, llmFlags.Model, llmFlags.Temperature
+	sess.Append(wrapper.RoleUser, prompt, store.Now())
+	sess.AppendReply(response, llmFlags.Answered(client).String(), store.Now())
+	if err := store.Save(sess); err != nil {
+		return "", err
+	}
+	return response, nil
+}
+
+// adoptSessionSettings resumes with the session's provider, model and
+// temperature unless the matching flag was set explicitly.
+func adoptSessionSettings(cmd *cobra.Command, sess *session.Session) {
+	llmFlags.Adopt(cmd, sess.Provider, sess.Model, &sess.Temperature)
+}
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"fmt"
+	"io"
+
+	"raja.aiml/ai.explorer/llm"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// reportPromptTokens prints the prompt's size and input cost before a call.
+func reportPromptTokens(w io.Writer, prices *tokens.PriceTable, prompt string) tokens.Count {
+	count := tokens.Default.Count(llmFlags.Provider, llmFlags.Model, prompt)
+	line := fmt.Sprintf("[llm] 🔢 Prompt: %s", count.Describe())
+	if cost, ok := prices.Cost(llmFlags.Provider, llmFlags.Model, tokens.Usage{PromptTokens: count.Tokens}); ok {
+		line += " · est. input cost " + cost.String()
+	}
+	fmt.Fprintln(w, line)
+	return count
+}
+
+// reportUsage prints a usage and cost summary after a call. Provider-reported
+// usage is preferred; otherwise prompt and response are counted locally.
+func reportUsage(w io.Writer, prices *tokens.PriceTable, client llm.LLM, prompt tokens.Count, response string) {
+	backend := llmConfig.Backend{Provider: llmFlags.Provider, Model: llmFlags.Model}
+	if r, ok := client.(llm.BackendReporter); ok && r.LastBackend().Model != "" {
+		backend = r.LastBackend()
+	}
+
+	summary := tokens.Summary{Backend: backend.String()}
+	if r, ok := client.(llm.UsageReporter); ok {
+		summary.Usage, summary.Reported = r.LastUsage()
+	}
+	if !summary.Reported {
+		completion := tokens.Default.Count(backend.Provider, backend.Model, response)
+		summary.Usage = tokens.Usage{PromptTokens: prompt.Tokens, CompletionTokens: completion.Tokens}
+	}
+	if cost, ok := prices.Cost(backend.Provider, backend.Model, summary.Usage); ok {
+		summary.Cost = &cost
+	}
+	fmt.Fprintf(w, "\n[llm] 📊 %s\n", summary)
+}
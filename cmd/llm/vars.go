@@ -2,6 +2,8 @@ package llm
 
 import (
 	"time"
+
+	"raja.aiml/ai.explorer/cmd/llmflags"
 )
 
 // Default file paths
@@ -14,15 +16,22 @@ const (
 	DefaultPromptPath  = "resources/demo/hello/prompt.txt" // Ensure a valid default prompt path
 )
 
-// CLI flags
 // CLI flags
 var (
-	providerName string
-	modelName    string
-	temperature  float64
-	promptPath   string
-	timeout      time.Duration
-	outputPath   string
-	// serverURL allows overriding the Ollama server endpoint
-	serverURL string
+	promptPath string
+	outputPath string
+	// templatePath supplies the recommended model for the prompt
+	templatePath string
+	// llmFlags holds provider, model, server and cache settings
+	llmFlags llmflags.Flags
+	// sessionName continues a persisted conversation when set
+	sessionName string
+	sessionDir  string
+	// pricesPath extends the built-in price table used for cost estimates
+	pricesPath string
+	// onOverflow and windowsPath control the context-window guard; userQuery
+	// locates the query inside the prompt for truncate-query
+	onOverflow  string
+	windowsPath string
+	userQuery   string
 )
@@ -0,0 +1,228 @@
+package llmflags
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/prompt"
+
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+)
+
+// Flags holds the model selection flags shared by commands that call an LLM.
+type Flags struct {
+	Provider    string
+	Model       string
+	Temperature float64
+	Timeout     time.Duration
+	// Fallbacks are tried in order when the primary backend is unavailable
+	Fallbacks []llmConfig.Backend
+	// MaxAttempts bounds retries of rate-limited and transient failures
+	MaxAttempts int
+	// ServerURL allows overriding the Ollama server endpoint
+	ServerURL string
+	// Verbose streams responses to stdout as they arrive
+	Verbose bool
+	// Record and Replay capture provider HTTP traffic to, or serve it from, a directory
+	Record string
+	Replay string
+	// Cache serves repeated requests from the on-disk response cache
+	Cache    bool
+	NoCache  bool
+	CacheDir string
+}
+
+// Defaults mirrors the defaults of the `llm` command.
+var Defaults = Flags{
+	Provider:    llmConfig.DefaultProvider,
+	Model:       llmConfig.DefaultModelName,
+	Temperature: llmConfig.DefaultTemperature,
+	Timeout:     llmConfig.DefaultTimeout,
+	MaxAttempts: llmConfig.DefaultMaxAttempts,
+	CacheDir:    llm.DefaultCacheDir,
+}
+
+// Register adds the LLM flags to cmd, seeded with the given defaults.
+func (f *Flags) Register(cmd *cobra.Command, defaults Flags) {
+	f.Verbose = defaults.Verbose
+	cmd.Flags().StringVarP(&f.Provider, "provider", "l", defaults.Provider, "LLM provider (ollama, openai, or offline: echo, mock, replay)")
+	cmd.Flags().StringVarP(&f.Model, "model", "m", defaults.Model, "LLM model")
+	cmd.Flags().Float64VarP(&f.Temperature, "temperature", "t", defaults.Temperature, "Temperature")
+	cmd.Flags().DurationVarP(&f.Timeout, "timeout", "d", defaults.Timeout, "Timeout for each attempt of a request")
+	cmd.Flags().Var(&backendList{&f.Fallbacks}, "fallback", "Fallback backend as provider:model, tried in order when the primary is unavailable (repeatable)")
+	cmd.Flags().IntVar(&f.MaxAttempts, "max-attempts", defaults.MaxAttempts, "Attempts per request for rate-limited or transient failures (1 disables retries)")
+	cmd.Flags().StringVar(&f.ServerURL, "server-url", "", "Ollama server URL (e.g. http://localhost:11434)")
+	cmd.Flags().StringVar(&f.Record, "record", "", "Record provider HTTP exchanges into this directory")
+	cmd.Flags().StringVar(&f.Replay, "replay", "", "Replay provider HTTP exchanges from this directory instead of the network")
+	cmd.MarkFlagsMutuallyExclusive("record", "replay")
+	cmd.Flags().BoolVar(&f.Cache, "cache", defaults.Cache, "Serve repeated requests from the on-disk response cache")
+	cmd.Flags().BoolVar(&f.NoCache, "no-cache", false, "Bypass the response cache")
+	cmd.Flags().StringVar(&f.CacheDir, "cache-dir", defaults.CacheDir, "Directory for cached responses")
+}
+
+// Config converts the flags into an LLM configuration.
+func (f *Flags) Config() llmConfig.Config {
+	retry := llmConfig.DefaultRetryPolicy()
+	retry.MaxAttempts = f.MaxAttempts
+	return llmConfig.Config{
+		Provider: f.Provider,
+		Model: llmConfig.ModelConfig{
+			Name:        f.Model,
+			Temperature: f.Temperature,
+		},
+		Client: llmConfig.ClientConfig{
+			Timeout:        f.Timeout,
+			VerboseLogging: f.Verbose,
+			Retry:          retry,
+			RecordDir:      f.Record,
+			ReplayDir:      f.Replay,
+		},
+		Fallbacks: f.Fallbacks,
+	}
+}
+
+// CacheEnabled reports whether responses should be cached.
+func (f *Flags) CacheEnabled() bool {
+	return f.Cache && !f.NoCache
+}
+
+// NewClient validates the server settings and builds a client.
+func (f *Flags) NewClient() (llm.ChatModel, error) {
+	return f.NewClientFor(f.Config())
+}
+
+// NewClientFor builds a client for cfg, which may override the flag values,
+// using the flags' server and cache settings.
+func (f *Flags) NewClientFor(cfg llmConfig.Config) (llm.ChatModel, error) {
+	// Replayed traffic never reaches a server, but the OpenAI client still
+	// insists on a token.
+	if cfg.Client.ReplayDir != "" && os.Getenv("OPENAI_API_KEY") == "" {
+		os.Setenv("OPENAI_API_KEY", "replay")
+	}
+	// If using Ollama, ensure a host is configured via env or flag
+	if usesProvider(cfg, "ollama") && cfg.Client.ReplayDir == "" && os.Getenv("OLLAMA_HOST") == "" && f.ServerURL == "" {
+		return nil, fmt.Errorf("ollama selected but neither OLLAMA_HOST nor --server-url provided")
+	}
+	// Override OLLAMA_HOST env var if server-url flag is set
+	if f.ServerURL != "" {
+		os.Setenv("OLLAMA_HOST", f.ServerURL)
+	}
+
+	client, err := llm.NewDefaultClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	if f.CacheEnabled() {
+		return llm.NewCached(client, llm.NewCache(f.CacheDir), cacheKey(cfg)), nil
+	}
+	return client, nil
+}
+
+// cacheKey is the cache key for cfg, including the servers its backends
+// talk to, so answers from one server are not served for another.
+func cacheKey(cfg llmConfig.Config) llm.CacheKey {
+	key := llm.CacheKeyFor(cfg)
+	if usesProvider(cfg, "ollama") {
+		key = key.WithOption("ollama_host", os.Getenv("OLLAMA_HOST"))
+	}
+	if usesProvider(cfg, "openai") {
+		key = key.WithOption("openai_base_url", os.Getenv("OPENAI_BASE_URL"))
+	}
+	return key
+}
+
+// Adopt sets the provider, model and temperature to the given values unless
+// set explicitly. Provider and model name one backend, so an explicit
+// --provider or --model keeps both. Empty values and a nil temperature are
+// ignored. It reports whether anything changed.
+func (f *Flags) Adopt(cmd *cobra.Command, provider, model string, temperature *float64) bool {
+	flags := cmd.Flags()
+	before := *f
+	if !flags.Changed("provider") && !flags.Changed("model") {
+		if provider != "" {
+			f.Provider = provider
+		}
+		if model != "" {
+			f.Model = model
+		}
+	}
+	if temperature != nil && !flags.Changed("temperature") {
+		f.Temperature = *temperature
+	}
+	return f.Provider != before.Provider || f.Model != before.Model || f.Temperature != before.Temperature
+}
+
+// Recommend adopts a template's recommended model and notes on w when it
+// changed the settings.
+func (f *Flags) Recommend(cmd *cobra.Command, w io.Writer, prefix string, rec prompt.Recommended) {
+	if f.Adopt(cmd, rec.Provider, rec.Model, rec.Temperature) {
+		fmt.Fprintf(w, "%s Using the template's recommended model %s:%s (temperature %g)\n",
+			prefix, f.Provider, f.Model, f.Temperature)
+	}
+}
+
+// ReportFallback notes on w when client's last response came from a
+// fallback rather than the primary backend.
+func (f *Flags) ReportFallback(w io.Writer, prefix string, client llm.LLM) {
+	primary := llmConfig.Backend{Provider: f.Provider, Model: f.Model}
+	if answered := f.Answered(client); answered != primary {
+		fmt.Fprintf(w, "%s ⚠️ %s unavailable, answered by fallback %s\n", prefix, primary, answered)
+	}
+}
+
+// Answered returns the backend that produced client's most recent
+// response, or the primary when client cannot tell.
+func (f *Flags) Answered(client llm.LLM) llmConfig.Backend {
+	if reporter, ok := client.(llm.BackendReporter); ok {
+		if answered := reporter.LastBackend(); answered.Model != "" {
+			return answered
+		}
+	}
+	return llmConfig.Backend{Provider: f.Provider, Model: f.Model}
+}
+
+// usesProvider reports whether any backend in cfg targets provider.
+func usesProvider(cfg llmConfig.Config, provider string) bool {
+	for _, b := range cfg.Backends() {
+		if b.Provider == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// backendList is a repeatable flag of provider:model pairs.
+type backendList struct {
+	backends *[]llmConfig.Backend
+}
+
+func (b *backendList) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		backend, err := llmConfig.ParseBackend(part)
+		if err != nil {
+			return err
+		}
+		*b.backends = append(*b.backends, backend)
+	}
+	return nil
+}
+
+func (b *backendList) String() string {
+	if b.backends == nil {
+		return ""
+	}
+	parts := make([]string, len(*b.backends))
+	for i, backend := range *b.backends {
+		parts[i] = backend.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func (b *backendList) Type() string {
+	return "provider:model"
+}
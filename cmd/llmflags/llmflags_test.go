@@ -0,0 +1,186 @@
+package llmflags
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/llm"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+func TestFlags_RegisterAndConfig(t *testing.T) {
+	var f Flags
+	cmd := &cobra.Command{Use: "test"}
+	defaults := Defaults
+	defaults.Temperature = 0
+	f.Register(cmd, defaults)
+
+	assert.NoError(t, cmd.ParseFlags([]string{"--model", "llama3", "-d", "30s", "--max-attempts", "5"}))
+
+	cfg := f.Config()
+	assert.Equal(t, "ollama", cfg.Provider)
+	assert.Equal(t, "llama3", cfg.Model.Name)
+	assert.Equal(t, 0.0, cfg.Model.Temperature)
+	assert.Equal(t, 30*time.Second, cfg.Client.Timeout)
+	assert.False(t, cfg.Client.VerboseLogging)
+	assert.Equal(t, 5, cfg.Client.Retry.MaxAttempts)
+	assert.Equal(t, llmConfig.DefaultInitialBackoff, cfg.Client.Retry.InitialBackoff)
+}
+
+func TestFlags_NewClient_RequiresOllamaHost(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "")
+	f := Flags{Provider: "ollama", Model: "phi4"}
+
+	_, err := f.NewClient()
+	assert.ErrorContains(t, err, "neither OLLAMA_HOST nor --server-url")
+}
+
+func TestFlags_NewClient_ServerURL(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "")
+	f := Flags{Provider: "ollama", Model: "phi4", ServerURL: "http://localhost:11434"}
+
+	client, err := f.NewClient()
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestFlags_NewClient_UnsupportedProvider(t *testing.T) {
+	f := Flags{Provider: "nope", Model: "x"}
+	_, err := f.NewClient()
+	assert.ErrorContains(t, err, "failed to create LLM client")
+}
+
+func TestFlags_NewClient_Cache(t *testing.T) {
+	var f Flags
+	cmd := &cobra.Command{Use: "test"}
+	defaults := Defaults
+	defaults.Cache = true
+	f.Register(cmd, defaults)
+	f.ServerURL = "http://localhost:11434"
+	f.CacheDir = t.TempDir()
+
+	client, err := f.NewClient()
+	assert.NoError(t, err)
+	assert.IsType(t, &llm.Cached{}, client)
+
+	assert.NoError(t, cmd.ParseFlags([]string{"--no-cache"}))
+	assert.False(t, f.CacheEnabled())
+	client, err = f.NewClient()
+	assert.NoError(t, err)
+	assert.IsType(t, &llm.Client{}, client)
+}
+
+func TestCacheKey_IncludesServers(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "http://gpu-box:11434")
+	t.Setenv("OPENAI_BASE_URL", "https://proxy.example/v1")
+	f := Flags{Provider: "ollama", Model: "phi4", Fallbacks: []llmConfig.Backend{{Provider: "echo", Model: "echo"}}}
+
+	key := cacheKey(f.Config())
+	assert.Equal(t, map[string]string{"fallbacks": "echo:echo", "ollama_host": "http://gpu-box:11434"}, key.Options)
+
+	t.Setenv("OLLAMA_HOST", "http://other-box:11434")
+	assert.NotEqual(t, key, cacheKey(f.Config()), "a different server gets different entries")
+}
+
+func TestFlags_Fallbacks(t *testing.T) {
+	var f Flags
+	cmd := &cobra.Command{Use: "test"}
+	f.Register(cmd, Defaults)
+
+	assert.NoError(t, cmd.ParseFlags([]string{"--fallback", "ollama:llama3", "--fallback", "openai:gpt-4o-mini,mistral"}))
+	assert.Equal(t, []llmConfig.Backend{
+		{Provider: "ollama", Model: "phi4"},
+		{Provider: "ollama", Model: "llama3"},
+		{Provider: "openai", Model: "gpt-4o-mini"},
+		{Provider: "ollama", Model: "mistral"},
+	}, f.Config().Backends())
+	assert.Equal(t, "ollama:llama3,openai:gpt-4o-mini,mistral", cmd.Flags().Lookup("fallback").Value.String())
+
+	assert.Error(t, cmd.ParseFlags([]string{"--fallback", "openai:"}))
+}
+
+func TestFlags_FallbackRequiresOllamaHost(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "")
+	f := Flags{Provider: "openai", Model: "gpt-4o-mini", Fallbacks: []llmConfig.Backend{{Provider: "ollama", Model: "phi4"}}}
+
+	_, err := f.NewClient()
+	assert.ErrorContains(t, err, "neither OLLAMA_HOST nor --server-url")
+}
+
+type answeredBy llmConfig.Backend
+
+func (answeredBy) Chat(context.Context, string) (string, error) { return "", nil }
+func (a answeredBy) LastBackend() llmConfig.Backend             { return llmConfig.Backend(a) }
+
+func TestFlags_ReportFallback(t *testing.T) {
+	f := Flags{Provider: "ollama", Model: "phi4"}
+	var buf bytes.Buffer
+
+	f.ReportFallback(&buf, "[llm]", answeredBy{Provider: "ollama", Model: "phi4"})
+	assert.Empty(t, buf.String())
+
+	f.ReportFallback(&buf, "[llm]", answeredBy{Provider: "openai", Model: "gpt-4o-mini"})
+	assert.Contains(t, buf.String(), "[llm] ⚠️ ollama:phi4 unavailable, answered by fallback openai:gpt-4o-mini")
+}
+
+func TestFlags_Answered(t *testing.T) {
+	f := Flags{Provider: "ollama", Model: "phi4"}
+	assert.Equal(t, llmConfig.Backend{Provider: "openai", Model: "gpt-4o-mini"},
+		f.Answered(answeredBy{Provider: "openai", Model: "gpt-4o-mini"}))
+	assert.Equal(t, llmConfig.Backend{Provider: "ollama", Model: "phi4"}, f.Answered(answeredBy{}),
+		"the primary when the client has not answered yet")
+}
+
+func TestFlags_RecordReplay(t *testing.T) {
+	var f Flags
+	cmd := &cobra.Command{Use: "test", RunE: func(*cobra.Command, []string) error { return nil }}
+	f.Register(cmd, Defaults)
+
+	cmd.SetArgs([]string{"--record", "a", "--replay", "b"})
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	assert.ErrorContains(t, cmd.Execute(), "none of the others can be")
+
+	f = Flags{Provider: "ollama", Model: "phi4", Replay: "cassettes"}
+	cfg := f.Config()
+	assert.Equal(t, "cassettes", cfg.Client.ReplayDir)
+
+	// Replayed traffic needs neither an Ollama host nor a real OpenAI key.
+	t.Setenv("OLLAMA_HOST", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	f.Fallbacks = []llmConfig.Backend{{Provider: "openai", Model: "gpt-4o-mini"}}
+	_, err := f.NewClient()
+	assert.NoError(t, err)
+}
+
+func TestFlags_Adopt(t *testing.T) {
+	var f Flags
+	cmd := &cobra.Command{Use: "test"}
+	f.Register(cmd, Defaults)
+	temp := 0.2
+
+	var buf bytes.Buffer
+	f.Recommend(cmd, &buf, "[run]", prompt.Recommended{Provider: "openai", Model: "gpt-4o-mini", Temperature: &temp})
+	assert.Equal(t, "openai", f.Provider)
+	assert.Equal(t, "gpt-4o-mini", f.Model)
+	assert.Equal(t, 0.2, f.Temperature)
+	assert.Contains(t, buf.String(), "[run] Using the template's recommended model openai:gpt-4o-mini (temperature 0.2)")
+
+	// An explicit --model keeps the whole backend; temperature still applies.
+	f = Flags{}
+	cmd = &cobra.Command{Use: "test"}
+	f.Register(cmd, Defaults)
+	assert.NoError(t, cmd.ParseFlags([]string{"--model", "llama3"}))
+	assert.True(t, f.Adopt(cmd, "openai", "gpt-4o-mini", &temp))
+	assert.Equal(t, "ollama", f.Provider)
+	assert.Equal(t, "llama3", f.Model)
+	assert.Equal(t, 0.2, f.Temperature)
+
+	assert.NoError(t, cmd.ParseFlags([]string{"-t", "0.9"}))
+	assert.False(t, f.Adopt(cmd, "", "", &temp))
+	assert.Equal(t, 0.9, f.Temperature)
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt/catalog"
+)
+
+// catalogRoot is the resources tree listed, shown and completed.
+var catalogRoot = paths.ResourcesDir
+
+// Cobra command for `prompt list`
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List categories and topics with template metadata and render status",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		topics, err := catalog.Scan(catalogRoot)
+		if err != nil {
+			return err
+		}
+		return listTopics(cmd.OutOrStdout(), topics)
+	},
+}
+
+// Cobra command for `prompt show`
+var showCmd = &cobra.Command{
+	Use:     "show <category>/<topic>",
+	Short:   "Print a topic's resolved paths, template and config",
+	Example: "  ai-explorer prompt show classification/router",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		topics, err := catalog.Scan(catalogRoot)
+		if err != nil {
+			return err
+		}
+		topic, err := catalog.Find(topics, args[0])
+		if err != nil {
+			return err
+		}
+		return showTopic(cmd.OutOrStdout(), topic)
+	},
+	ValidArgsFunction: completeTopicIDs,
+}
+
+// listTopics prints a table of topics.
+func listTopics(out io.Writer, topics []catalog.Topic) error {
+	if len(topics) == 0 {
+		fmt.Fprintf(out, "No topics in %s\n", catalogRoot)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOPIC\tTEMPLATE\tVERSION\tRECOMMENDED\tCONFIG\tLAST RENDER")
+	for _, t := range topics {
+		name, version, model := "-", "-", "-"
+		switch {
+		case t.Template == "":
+			name = "(missing)"
+		case t.TemplateErr != nil:
+			name = "(invalid)"
+		default:
+			if t.Spec.Name != "" {
+				name = t.Spec.Name
+			}
+			if t.Spec.Version != "" {
+				version = t.Spec.Version
+			}
+			if rec := t.Spec.Recommended; rec.Model != "" {
+				model = rec.Provider + ":" + rec.Model
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s, %d keys\t%s\n",
+			t.ID(), name, version, model, formatSize(t.ConfigSize), t.ConfigKeys, formatRender(t.LastRender))
+	}
+	return tw.Flush()
+}
+
+// showTopic prints a topic's paths followed by its template and config.
+func showTopic(out io.Writer, t catalog.Topic) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "Topic:\t%s\n", t.ID())
+	if t.Spec.Description != "" {
+		fmt.Fprintf(tw, "Description:\t%s\n", t.Spec.Description)
+	}
+	fmt.Fprintf(tw, "Template:\t%s\n", orNone(t.Template))
+	fmt.Fprintf(tw, "Config:\t%s\n", t.Config)
+	fmt.Fprintf(tw, "Schema:\t%s\n", orNone(t.Schema))
+	fmt.Fprintf(tw, "Output:\t%s (last rendered: %s)\n", t.Output, formatRender(t.LastRender))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if t.TemplateErr != nil {
+		fmt.Fprintf(out, "\n⚠️  Template does not parse: %v\n", t.TemplateErr)
+	}
+
+	for _, path := range []string{t.Template, t.Config} {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\n--- %s ---\n%s", path, data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			fmt.Fprintln(out)
+		}
+	}
+	return nil
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func formatSize(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
+
+func formatRender(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format(time.DateTime)
+}
+
+// completeCategories suggests the categories found under resources/.
+func completeCategories(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	topics, _ := catalog.Scan(catalogRoot)
+	return catalog.Categories(topics), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTopics suggests the topics of the --category being completed,
+// or of the default category.
+func completeTopics(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	category, _ := cmd.Flags().GetString("category")
+	if category == "" {
+		category = defaultPromptCategory
+	}
+	topics, _ := catalog.Scan(catalogRoot)
+	var names []string
+	for _, t := range catalog.InCategory(topics, category) {
+		names = append(names, t.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTopicIDs suggests category/topic arguments.
+func completeTopicIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	topics, _ := catalog.Scan(catalogRoot)
+	ids := make([]string, 0, len(topics))
+	for _, t := range topics {
+		ids = append(ids, t.ID())
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	promptCmd.AddCommand(listCmd, showCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/prompt/catalog"
+)
+
+// useCatalog points the catalog commands at a temporary resources tree.
+func useCatalog(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	saved := catalogRoot
+	catalogRoot = root
+	t.Cleanup(func() { catalogRoot = saved })
+	return root
+}
+
+var catalogFiles = map[string]string{
+	"topics/template.yaml":                "name: explainer\nversion: 1.0.0\ntemplate: Explain {{ topic }}\n",
+	"topics/git/config.yaml":              "topic: Git\n",
+	"topics/bgp/config.yaml":              "topic: BGP\n",
+	"classification/router/config.yaml":   "{}\n",
+	"classification/router/template.yaml": "recommended: {provider: ollama, model: phi4}\ntemplate: Route {{ user_query }}",
+}
+
+func TestListTopics(t *testing.T) {
+	root := useCatalog(t, catalogFiles)
+	topics, err := catalog.Scan(root)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, listTopics(&out, topics))
+	assert.Equal(t, `TOPIC                  TEMPLATE   VERSION  RECOMMENDED  CONFIG        LAST RENDER
+classification/router  -          -        ollama:phi4  3 B, 0 keys   never
+topics/bgp             explainer  1.0.0    -            11 B, 1 keys  never
+topics/git             explainer  1.0.0    -            11 B, 1 keys  never
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, listTopics(&out, nil))
+	assert.Equal(t, "No topics in "+root+"\n", out.String())
+}
+
+func TestShowTopic(t *testing.T) {
+	root := useCatalog(t, catalogFiles)
+	topics, _ := catalog.Scan(root)
+	topic, err := catalog.Find(topics, "topics/git")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, showTopic(&out, topic))
+	assert.Contains(t, out.String(), "Topic:    topics/git\n")
+	assert.Contains(t, out.String(), "Template: "+filepath.Join(root, "topics/template.yaml")+"\n")
+	assert.Contains(t, out.String(), "Schema:   (none)\n")
+	assert.Contains(t, out.String(), "(last rendered: never)")
+	assert.Contains(t, out.String(), "--- "+filepath.Join(root, "topics/git/config.yaml")+" ---\ntopic: Git\n")
+}
+
+func TestCatalogCompletions(t *testing.T) {
+	useCatalog(t, catalogFiles)
+
+	categories, directive := completeCategories(&cobra.Command{}, nil, "")
+	assert.Equal(t, []string{"classification", "topics"}, categories)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("category", "", "")
+	names, _ := completeTopics(cmd, nil, "")
+	assert.Equal(t, []string{"bgp", "git"}, names)
+
+	require.NoError(t, cmd.Flags().Set("category", "classification"))
+	names, _ = completeTopics(cmd, nil, "")
+	assert.Equal(t, []string{"router"}, names)
+
+	ids, _ := completeTopicIDs(&cobra.Command{}, nil, "")
+	assert.Equal(t, []string{"classification/router", "topics/bgp", "topics/git"}, ids)
+	ids, _ = completeTopicIDs(&cobra.Command{}, []string{"topics/git"}, "")
+	assert.Empty(t, ids)
+}
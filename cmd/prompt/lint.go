@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt/lint"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// Lint flags
+var (
+	lintFormat    string
+	lintQuery     string
+	lintMaxTokens int
+)
+
+// DefaultLintRoot is the resources tree linted when no directory is given.
+const DefaultLintRoot = paths.ResourcesDir
+
+// Cobra command for `prompt lint`
+var lintCmd = &cobra.Command{
+	Use:   "lint [dir]",
+	Short: "Check every category/topic template and config for problems",
+	Example: `  ai-explorer prompt lint
+  ai-explorer prompt lint resources --format json --max-tokens 2000`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := DefaultLintRoot
+		if len(args) == 1 {
+			root = args[0]
+		}
+		if lintFormat != "text" && lintFormat != "json" {
+			return fmt.Errorf("invalid format %q (want text or json)", lintFormat)
+		}
+
+		linter := &lint.Linter{
+			Root:      root,
+			Query:     lintQuery,
+			MaxTokens: lintMaxTokens,
+			Provider:  llmConfig.DefaultProvider,
+			Model:     llmConfig.DefaultModelName,
+			Counter:   tokens.Default,
+			Windows:   tokens.DefaultContextWindows(),
+		}
+		report, err := linter.Run()
+		if err != nil {
+			return err
+		}
+		if err := printLintReport(cmd.OutOrStdout(), report, lintFormat); err != nil {
+			return err
+		}
+		if report.Errors > 0 {
+			return fmt.Errorf("lint found %d error(s)", report.Errors)
+		}
+		return nil
+	},
+}
+
+// printLintReport writes the report as text lines or a JSON document.
+func printLintReport(w io.Writer, report *lint.Report, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	for _, issue := range report.Issues {
+		fmt.Fprintln(w, issue)
+	}
+	if len(report.Issues) == 0 {
+		fmt.Fprintf(w, "✅ %d topic(s), no problems found\n", report.Topics)
+		return nil
+	}
+	fmt.Fprintf(w, "\n%d error(s), %d warning(s) in %d topic(s)\n", report.Errors, report.Warnings, report.Topics)
+	return nil
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format: text or json")
+	lintCmd.Flags().StringVarP(&lintQuery, "query", "q", "What is BGP?", "Sample user query for templates that read user_query")
+	lintCmd.Flags().IntVar(&lintMaxTokens, "max-tokens", 0, "Token budget per rendered prompt (default: the model's context window)")
+	promptCmd.AddCommand(lintCmd)
+}
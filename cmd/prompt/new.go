@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/prompt"
+	"raja.aiml/ai.explorer/prompt/scaffold"
+)
+
+// New flags
+var (
+	newCategory    string
+	newTopic       string
+	newFrom        string
+	newInteractive bool
+)
+
+// Cobra command for `prompt new`
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Create a topic with a config skeleton inferred from its template",
+	Example: `  ai-explorer prompt new --topic bgp
+  ai-explorer prompt new --category topics --topic dns --from git --interactive`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := scaffold.Options{
+			Root:     catalogRoot,
+			Category: newCategory,
+			Topic:    newTopic,
+			From:     newFrom,
+		}
+		if newInteractive {
+			a := &asker{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout()}
+			opts.Ask = a.ask
+		}
+		res, err := scaffold.Create(opts)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		for _, path := range res.Created {
+			fmt.Fprintf(out, "Created %s\n", path)
+		}
+		if len(res.Violations) > 0 {
+			fmt.Fprintln(out, "The config does not match its schema until the TODOs are filled in:")
+			for _, v := range res.Violations {
+				fmt.Fprintf(out, "  %s\n", v)
+			}
+		}
+		fmt.Fprintf(out, "Next: fill in any TODO, then run `ai-explorer prompt --category %s --topic %s --preview`\n",
+			newCategory, newTopic)
+		return nil
+	},
+}
+
+// asker prompts for config values, one line per key.
+type asker struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask fills values for fields. An empty answer keeps the default, or
+// leaves a placeholder when there is none.
+func (a *asker) ask(fields []*prompt.Field, defaults map[string]any) (map[string]any, error) {
+	fmt.Fprintln(a.out, "Enter a value for each key. Press Enter to keep the [default] or leave a placeholder.")
+	return a.askMap("", fields, defaults)
+}
+
+func (a *asker) askMap(prefix string, fields []*prompt.Field, defaults map[string]any) (map[string]any, error) {
+	values := map[string]any{}
+	for _, f := range fields {
+		path := f.Key
+		if prefix != "" {
+			path = prefix + "." + f.Key
+		}
+		def, hasDef := defaults[f.Key]
+		value, ok, err := a.askField(path, f, def, hasDef)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			values[f.Key] = value
+		}
+	}
+	return values, nil
+}
+
+// askField returns the value for one field and whether there is one.
+func (a *asker) askField(path string, f *prompt.Field, def any, hasDef bool) (any, bool, error) {
+	switch {
+	case f.IsList():
+		elem := f.Child("[]")
+		if elem == nil && len(f.Children) > 0 {
+			// Only indexed elements are read; they share one layout.
+			elem = f.Children[0]
+		}
+		if elem == nil || len(elem.Children) == 0 {
+			return a.askList(path, def, hasDef)
+		}
+		return a.askItems(path, elem, def)
+
+	case f.IsMap():
+		sub, _ := def.(map[string]any)
+		values, err := a.askMap(path, f.Children, sub)
+		return values, len(values) > 0, err
+
+	case f.Child("*") != nil:
+		sub, _ := def.(map[string]any)
+		keys, ok, err := a.askList(path+" keys", sortedKeys(sub), sub != nil)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		values := map[string]any{}
+		for _, k := range keys.([]any) {
+			key := fmt.Sprint(k)
+			v, has := sub[key]
+			value, ok, err := a.askField(path+"."+key, f.Child("*"), v, has)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				values[key] = value
+			}
+		}
+		return values, true, nil
+	}
+
+	line, err := a.prompt(path, def, hasDef)
+	if err != nil || line == "" {
+		return def, hasDef, err
+	}
+	return prompt.ParseValue(line), true, nil
+}
+
+// askList reads a list of scalars as comma-separated values.
+func (a *asker) askList(path string, def any, hasDef bool) (any, bool, error) {
+	line, err := a.prompt(path+" (comma-separated)", def, hasDef)
+	if err != nil || line == "" {
+		return def, hasDef, err
+	}
+	var items []any
+	for _, item := range strings.Split(line, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, prompt.ParseValue(item))
+		}
+	}
+	return items, true, nil
+}
+
+// askItems reads list elements with keys of their own, one element at a
+// time, until the user declines another.
+func (a *asker) askItems(path string, elem *prompt.Field, def any) (any, bool, error) {
+	defs, _ := def.([]any)
+	var items []any
+	for i := 0; ; i++ {
+		if i > 0 {
+			more, err := a.confirm(fmt.Sprintf("Add another %s item?", path), i < len(defs))
+			if err != nil {
+				return nil, false, err
+			}
+			if !more {
+				break
+			}
+		}
+		var itemDefs map[string]any
+		if i < len(defs) {
+			itemDefs, _ = defs[i].(map[string]any)
+		}
+		item, err := a.askMap(fmt.Sprintf("%s[%d]", path, i), elem.Children, itemDefs)
+		if err != nil {
+			return nil, false, err
+		}
+		items = append(items, item)
+	}
+	return items, true, nil
+}
+
+// prompt prints a question and returns the trimmed answer. End of input
+// answers every remaining question with an empty line.
+func (a *asker) prompt(question string, def any, hasDef bool) (string, error) {
+	if hasDef {
+		fmt.Fprintf(a.out, "%s [%s]: ", question, formatDefault(def))
+	} else {
+		fmt.Fprintf(a.out, "%s: ", question)
+	}
+	line, err := a.in.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		fmt.Fprintln(a.out)
+		err = nil
+	}
+	return strings.TrimSpace(line), err
+}
+
+func (a *asker) confirm(question string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	fmt.Fprintf(a.out, "%s [%s]: ", question, choices)
+	line, err := a.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+	return def, nil
+}
+
+// formatDefault shortens a default for display in a question.
+func formatDefault(v any) string {
+	var s string
+	if items, ok := v.([]any); ok {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = fmt.Sprint(item)
+		}
+		s = strings.Join(parts, ", ")
+	} else {
+		s = fmt.Sprint(v)
+	}
+	if s = strings.ReplaceAll(s, "\n", " "); len([]rune(s)) > 60 {
+		s = string([]rune(s)[:57]) + "..."
+	}
+	return s
+}
+
+func sortedKeys(m map[string]any) []any {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]any, len(keys))
+	for i, k := range keys {
+		out[i] = k
+	}
+	return out
+}
+
+func init() {
+	newCmd.Flags().StringVar(&newCategory, "category", defaultPromptCategory, "Category (folder under resources/) for the new topic")
+	newCmd.Flags().StringVar(&newTopic, "topic", "", "Name of the new topic")
+	newCmd.Flags().StringVar(&newFrom, "from", "", "Copy the config (and template, if the topic needs its own) of an existing topic, as name or category/name")
+	newCmd.Flags().BoolVarP(&newInteractive, "interactive", "i", false, "Ask for each config value on the terminal")
+	_ = newCmd.MarkFlagRequired("topic")
+	_ = newCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	_ = newCmd.RegisterFlagCompletionFunc("from", completeTopicIDs)
+	promptCmd.AddCommand(newCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+func askerFor(input string) (*asker, *bytes.Buffer) {
+	var out bytes.Buffer
+	return &asker{in: bufio.NewReader(strings.NewReader(input)), out: &out}, &out
+}
+
+func TestAsker_Ask(t *testing.T) {
+	tpl, err := prompt.ParseTemplate([]byte(`template: |
+  {{ topic }} {{ level }} {{ meta.tone }}
+  {% for c in concepts %}{{ c }}{% endfor %}
+  {% for task in tasks %}{{ task.title }}{% endfor %}
+`))
+	require.NoError(t, err)
+	analysis, err := tpl.Analyze()
+	require.NoError(t, err)
+
+	defaults := map[string]any{
+		"topic":    "Git",
+		"concepts": []any{"Commits", "Branches"},
+		"tasks":    []any{map[string]any{"title": "Install"}},
+	}
+	a, out := askerFor("DNS\n3\n\nZones, Records\nLookup\ny\nCaching\nn\n")
+	values, err := a.ask(analysis.Fields(), defaults)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"topic":    "DNS",
+		"level":    3,
+		"concepts": []any{"Zones", "Records"},
+		"tasks": []any{
+			map[string]any{"title": "Lookup"},
+			map[string]any{"title": "Caching"},
+		},
+	}, values)
+	assert.Contains(t, out.String(), "topic [Git]: ")
+	assert.Contains(t, out.String(), "concepts (comma-separated) [Commits, Branches]: ")
+	assert.Contains(t, out.String(), "tasks[0].title [Install]: ")
+	assert.Contains(t, out.String(), "Add another tasks item? [y/N]: ")
+}
+
+func TestAsker_EndOfInputKeepsDefaults(t *testing.T) {
+	tpl, err := prompt.ParseTemplate([]byte(`template: "{{ topic }} {% for t in tasks %}{{ t.title }}{% endfor %}"`))
+	require.NoError(t, err)
+	analysis, err := tpl.Analyze()
+	require.NoError(t, err)
+
+	a, _ := askerFor("")
+	values, err := a.ask(analysis.Fields(), map[string]any{"topic": "Git"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"topic": "Git", "tasks": []any{map[string]any{}}}, values)
+}
+
+func TestFormatDefault(t *testing.T) {
+	assert.Equal(t, "a, 2", formatDefault([]any{"a", 2}))
+	assert.Equal(t, "one two", formatDefault("one\ntwo"))
+	assert.Equal(t, strings.Repeat("x", 57)+"...", formatDefault(strings.Repeat("x", 80)))
+}
+
+func TestNewCommand(t *testing.T) {
+	root := useCatalog(t, catalogFiles)
+
+	cmd := GetPromptCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"new", "--topic", "dns"})
+	t.Cleanup(func() { cmd.SetArgs(nil); cmd.SetOut(nil); newTopic = "" })
+	require.NoError(t, cmd.Execute())
+
+	cfg := filepath.Join(root, "topics/dns/config.yaml")
+	assert.Equal(t, "Created "+cfg+"\n"+
+		"Next: fill in any TODO, then run `ai-explorer prompt --category topics --topic dns --preview`\n", out.String())
+	assert.FileExists(t, cfg)
+}
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 
 	"github.com/spf13/cobra"
 	"raja.aiml/ai.explorer/prompt"
+	"raja.aiml/ai.explorer/prompt/dataset"
 )
 
 // CLI flags
@@ -12,10 +14,18 @@ var (
 	promptCategory     string
 	topic              string
 	promptTemplatePath string
-	promptConfigPath   string
+	promptConfigPaths  []string
+	promptSets         []string
+	promptVarsFile     string
 	promptOutputPath   string
 	preview            bool
 	userQuery          string
+	strict             bool
+	promptDataset      string
+	promptOutputDir    string
+	promptIDColumn     string
+	promptQueryColumn  string
+	promptConcurrency  int
 )
 
 const (
@@ -27,27 +37,54 @@ const (
 var promptCmd = &cobra.Command{
 	Use:   "prompt",
 	Short: "Generate prompt from a category (folder), topic, and config YAML",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Extra -c configs are layered over the first one.
+		config, layers := splitConfigs(promptConfigPaths)
+		layers.VarsFile = promptVarsFile
+		layers.Stdin = cmd.InOrStdin()
+		layers.Sets = promptSets
+
+		if promptDataset != "" && promptVarsFile == "-" {
+			return errors.New("--vars-file - cannot be used with --dataset")
+		}
+
+		renderer := prompt.DefaultRenderer
+		if strict || !layers.IsZero() {
+			builder := *prompt.DefaultBuilder
+			builder.Strict = strict
+			builder.Layers = layers
+			renderer = &builder
+		}
+
 		runner := &PromptRunner{
 			Out:            cmd.OutOrStdout(),
-			Renderer:       prompt.DefaultRenderer,
+			Renderer:       renderer,
 			PromptCategory: promptCategory,
 			Topic:          topic,
 			Template:       promptTemplatePath,
-			Config:         promptConfigPath,
+			Config:         config,
 			Output:         promptOutputPath,
 			Preview:        preview,
 			UserQuery:      userQuery,
+			Dataset:        promptDataset,
+			OutputDir:      promptOutputDir,
+			IDColumn:       promptIDColumn,
+			QueryColumn:    promptQueryColumn,
+			Concurrency:    promptConcurrency,
+		}
+		if promptDataset != "" {
+			return runner.RunDataset(cmd.Context())
 		}
-		runner.Run()
+		return runner.Run(cmd.Context())
 	},
-	ValidArgsFunction: promptAutoComplete,
 }
 
-// promptAutoComplete suggests categories or topics for CLI completions.
-func promptAutoComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	// Suggest common categories or hardcoded ones for now
-	return []string{"topics", "demo", "classification"}, cobra.ShellCompDirectiveNoFileComp
+// splitConfigs returns the primary config and layers holding the rest.
+func splitConfigs(configs []string) (string, prompt.Layers) {
+	if len(configs) == 0 {
+		return "", prompt.Layers{}
+	}
+	return configs[0], prompt.Layers{Configs: configs[1:]}
 }
 
 // GetPromptCommand exposes the `prompt` Cobra command.
@@ -65,8 +102,21 @@ func init() {
 	promptCmd.Flags().StringVar(&promptCategory, "category", "", "Base folder for prompt templates (default: topics)")
 	promptCmd.Flags().StringVar(&topic, "topic", "", "Topic name (used to infer default paths)")
 	promptCmd.Flags().StringVarP(&promptTemplatePath, "template", "t", "", "Path to template YAML")
-	promptCmd.Flags().StringVarP(&promptConfigPath, "config", "c", "", "Path to config YAML")
+	promptCmd.Flags().StringArrayVarP(&promptConfigPaths, "config", "c", nil, "Path to config YAML (repeatable; later configs are deep-merged over earlier ones)")
+	promptCmd.Flags().StringArrayVar(&promptSets, "set", nil, "Override a config value, e.g. tone=formal or concepts[0]=Branches (repeatable)")
+	promptCmd.Flags().StringVar(&promptVarsFile, "vars-file", "", "JSON or YAML context merged over the configs (- reads stdin)")
 	promptCmd.Flags().StringVarP(&promptOutputPath, "output", "o", "", "Path to output file")
 	promptCmd.Flags().BoolVar(&preview, "preview", false, "Print output to stdout instead of writing to file")
 	promptCmd.Flags().StringVarP(&userQuery, "query", "q", "", "User query to inject into template context")
+	promptCmd.Flags().BoolVar(&strict, "strict", false, "Fail when the template reads variables the config lacks, the config has keys the template never reads, or it references unset environment variables")
+	promptCmd.Flags().StringVar(&promptDataset, "dataset", "", "CSV or JSONL file; renders one prompt per row, with its columns over the config")
+	promptCmd.Flags().StringVar(&promptOutputDir, "output-dir", "", "Directory for --dataset prompts and their manifest.jsonl")
+	promptCmd.Flags().StringVar(&promptIDColumn, "id-column", "id", "Dataset column that names each prompt file; rows without it use the row number")
+	promptCmd.Flags().StringVar(&promptQueryColumn, "query-column", "query", "Dataset column used as the user query")
+	promptCmd.Flags().IntVar(&promptConcurrency, "concurrency", dataset.DefaultConcurrency, "Rows rendered in parallel with --dataset")
+	promptCmd.MarkFlagsRequiredTogether("dataset", "output-dir")
+	promptCmd.MarkFlagsMutuallyExclusive("dataset", "preview")
+	promptCmd.MarkFlagsMutuallyExclusive("dataset", "output")
+	_ = promptCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	_ = promptCmd.RegisterFlagCompletionFunc("topic", completeTopics)
 }
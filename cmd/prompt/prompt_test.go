@@ -3,7 +3,6 @@ package cmd
 import (
 	"testing"
 
-	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,12 +24,27 @@ func TestPromptCommand_FlagsRegistered(t *testing.T) {
 	assert.NotNil(t, flags.Lookup("config"))
 	assert.NotNil(t, flags.Lookup("output"))
 	assert.NotNil(t, flags.Lookup("preview"))
+	assert.NotNil(t, flags.Lookup("set"))
+	assert.NotNil(t, flags.Lookup("vars-file"))
+	assert.NotNil(t, flags.Lookup("dataset"))
+	assert.NotNil(t, flags.Lookup("output-dir"))
+	assert.NotNil(t, flags.Lookup("concurrency"))
 }
 
-func TestPromptAutoComplete_ReturnsCategories(t *testing.T) {
-	completions, directive := promptAutoComplete(&cobra.Command{}, nil, "")
+func TestPromptCommand_Subcommands(t *testing.T) {
+	var names []string
+	for _, sub := range GetPromptCommand().Commands() {
+		names = append(names, sub.Name())
+	}
+	assert.Subset(t, names, []string{"lint", "list", "new", "show"})
+}
+
+func TestSplitConfigs(t *testing.T) {
+	config, layers := splitConfigs(nil)
+	assert.Empty(t, config)
+	assert.True(t, layers.IsZero())
 
-	expected := []string{"topics", "demo", "classification"}
-	assert.Equal(t, expected, completions)
-	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+	config, layers = splitConfigs([]string{"base.yaml", "team.yaml", "local.yaml"})
+	assert.Equal(t, "base.yaml", config)
+	assert.Equal(t, []string{"team.yaml", "local.yaml"}, layers.Configs)
 }
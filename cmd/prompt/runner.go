@@ -1,22 +1,29 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 
 	"raja.aiml/ai.explorer/paths"
 	"raja.aiml/ai.explorer/prompt"
+	"raja.aiml/ai.explorer/prompt/dataset"
 )
 
 // Runner defines the interface for anything that can generate a prompt.
 type Runner interface {
-	Run()
+	Run(ctx context.Context) error
 }
 
 // PromptRunner holds values required to generate a prompt.
 type PromptRunner struct {
-	Out            io.Writer
-	Renderer       prompt.Renderer
+	Out      io.Writer
+	Renderer prompt.Renderer
+	// WriteFile saves the prompt; it defaults to creating the output's
+	// directory and writing the file there.
+	WriteFile      func(path string, data []byte, perm os.FileMode) error
 	PromptCategory string
 	Topic          string
 	Template       string
@@ -24,6 +31,13 @@ type PromptRunner struct {
 	Output         string
 	Preview        bool
 	UserQuery      string
+
+	// Dataset, when set, renders one prompt per row into OutputDir.
+	Dataset     string
+	OutputDir   string
+	IDColumn    string
+	QueryColumn string
+	Concurrency int
 }
 
 // ResolvePaths infers missing paths from category and topic.
@@ -53,23 +67,106 @@ func (r *PromptRunner) ResolvePaths() (tmpl, cfg, out string) {
 }
 
 // Run generates or previews the prompt.
-func (r *PromptRunner) Run() {
+func (r *PromptRunner) Run(ctx context.Context) error {
 	tmpl, cfg, out := r.ResolvePaths()
-	queryArgs := r.queryArgs()
+	req := prompt.Request{Template: tmpl, Config: cfg, Query: r.UserQuery}
 
 	if r.Preview {
-		r.Renderer.RenderToStdout(tmpl, cfg, queryArgs...)
-		return
+		if err := r.Renderer.RenderTo(ctx, r.Out, req); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(r.Out); err != nil {
+			return &prompt.WriteError{Err: err}
+		}
+		return nil
 	}
 
-	r.Renderer.RenderToFile(tmpl, cfg, out, queryArgs...)
+	text, err := r.Renderer.Render(ctx, req)
+	if err != nil {
+		return err
+	}
+	writeFile := r.WriteFile
+	if writeFile == nil {
+		writeFile = writeOutput
+	}
+	if err := writeFile(out, []byte(text), 0644); err != nil {
+		return &prompt.WriteError{Path: out, Err: err}
+	}
 	fmt.Fprintf(r.Out, "Prompt saved to: %s\n", out)
+	return nil
+}
+
+// writeOutput writes a prompt file, creating its directory first.
+func writeOutput(path string, data []byte, perm os.FileMode) error {
+	if err := paths.EnsureDirectoryExists(path); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// RunDataset renders the template once per dataset row, with the row's
+// columns over the config, and writes a manifest next to the prompts.
+// Failed rows are reported without stopping the others.
+func (r *PromptRunner) RunDataset(ctx context.Context) error {
+	tmpl, cfg, _ := r.ResolvePaths()
+
+	rows, err := dataset.Load(r.Dataset)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
+		return &prompt.WriteError{Path: r.OutputDir, Err: err}
+	}
+
+	renderer := &dataset.Renderer{
+		OutputDir:   r.OutputDir,
+		IDColumn:    r.IDColumn,
+		QueryColumn: r.QueryColumn,
+		Concurrency: r.Concurrency,
+		Render: func(vars map[string]any, query string) (string, error) {
+			if query == "" {
+				query = r.UserQuery
+			}
+			return r.Renderer.Render(ctx, prompt.Request{Template: tmpl, Config: cfg, Query: query, Vars: vars})
+		},
+		WriteFile: os.WriteFile,
+	}
+	entries, err := renderer.Run(ctx, rows)
+	if err != nil {
+		return err
+	}
+
+	manifest := filepath.Join(r.OutputDir, dataset.ManifestFile)
+	if err := writeManifest(manifest, entries); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Status != dataset.StatusOK {
+			fmt.Fprintf(r.Out, "❌ row %d (%s): %s\n", e.Row, e.ID, e.Error)
+		}
+	}
+	failed := dataset.Failed(entries)
+	fmt.Fprintf(r.Out, "Rendered %d of %d rows to %s (manifest: %s)\n",
+		len(entries)-failed, len(entries), r.OutputDir, manifest)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", failed, len(entries))
+	}
+	return nil
 }
 
-// queryArgs returns userQuery as a variadic string slice if non-empty.
-func (r *PromptRunner) queryArgs() []string {
-	if r.UserQuery != "" {
-		return []string{r.UserQuery}
+// writeManifest writes the manifest for entries to path. Failures are
+// returned as *prompt.WriteError.
+func writeManifest(path string, entries []dataset.Entry) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return &prompt.WriteError{Path: path, Err: fmt.Errorf("manifest: %w", err)}
+	}
+	if err := dataset.WriteManifest(f, entries); err != nil {
+		f.Close()
+		return &prompt.WriteError{Path: path, Err: fmt.Errorf("manifest: %w", err)}
+	}
+	if err := f.Close(); err != nil {
+		return &prompt.WriteError{Path: path, Err: fmt.Errorf("manifest: %w", err)}
 	}
 	return nil
 }
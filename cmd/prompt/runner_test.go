@@ -2,50 +2,78 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/prompt"
 )
 
 // --- Mock Implementation ---
 
 type mockRenderer struct {
-	ToFileCalled   bool
-	ToStdoutCalled bool
+	RenderCalled   bool
+	RenderToCalled bool
 	GotTmpl        string
 	GotCfg         string
-	GotOut         string
 	GotQuery       string
+	// Output is the rendered prompt, unless RenderFn is set.
+	Output   string
+	RenderFn func(req prompt.Request) (string, error)
 }
 
-func (m *mockRenderer) RenderToFile(tmpl, cfg, out string, userQuery ...string) {
-	m.ToFileCalled = true
-	m.GotTmpl = tmpl
-	m.GotCfg = cfg
-	m.GotOut = out
-	if len(userQuery) > 0 {
-		m.GotQuery = userQuery[0]
+func (m *mockRenderer) Render(_ context.Context, req prompt.Request) (string, error) {
+	m.RenderCalled = true
+	m.GotTmpl = req.Template
+	m.GotCfg = req.Config
+	m.GotQuery = req.Query
+	if m.RenderFn != nil {
+		return m.RenderFn(req)
 	}
+	return m.Output, nil
 }
 
-func (m *mockRenderer) RenderToStdout(tmpl, cfg string, userQuery ...string) {
-	m.ToStdoutCalled = true
-	m.GotTmpl = tmpl
-	m.GotCfg = cfg
-	if len(userQuery) > 0 {
-		m.GotQuery = userQuery[0]
+func (m *mockRenderer) RenderTo(ctx context.Context, w io.Writer, req prompt.Request) error {
+	out, err := m.Render(ctx, req)
+	m.RenderToCalled = true
+	if err != nil {
+		return err
 	}
+	_, err = io.WriteString(w, out)
+	return err
+}
+
+// fileWriter records what PromptRunner writes instead of touching disk.
+type fileWriter struct {
+	Path string
+	Data string
+	Err  error
+}
+
+func (f *fileWriter) WriteFile(path string, data []byte, _ os.FileMode) error {
+	f.Path = path
+	f.Data = string(data)
+	return f.Err
 }
 
 // --- Tests ---
 
 func TestPromptRunner_Run_ExplicitPaths(t *testing.T) {
 	var buf bytes.Buffer
-	renderer := &mockRenderer{}
+	renderer := &mockRenderer{Output: "Explain Git"}
+	files := &fileWriter{}
 
 	runner := PromptRunner{
 		Out:            &buf,
 		Renderer:       renderer,
+		WriteFile:      files.WriteFile,
 		PromptCategory: "topics",
 		Topic:          "ignored",
 		Template:       "template.yaml",
@@ -55,56 +83,62 @@ func TestPromptRunner_Run_ExplicitPaths(t *testing.T) {
 		UserQuery:      "explain Git in simple terms",
 	}
 
-	runner.Run()
+	require.NoError(t, runner.Run(context.Background()))
 
-	assert.True(t, renderer.ToFileCalled)
+	assert.True(t, renderer.RenderCalled)
 	assert.Equal(t, "template.yaml", renderer.GotTmpl)
 	assert.Equal(t, "config.yaml", renderer.GotCfg)
-	assert.Equal(t, "output.txt", renderer.GotOut)
+	assert.Equal(t, "output.txt", files.Path)
+	assert.Equal(t, "Explain Git", files.Data)
 	assert.Equal(t, "explain Git in simple terms", renderer.GotQuery)
 	assert.Contains(t, buf.String(), "Prompt saved to: output.txt")
 }
 
 func TestPromptRunner_Run_WithPreview(t *testing.T) {
 	var buf bytes.Buffer
-	renderer := &mockRenderer{}
+	renderer := &mockRenderer{Output: "Preview text"}
+	files := &fileWriter{}
 
 	runner := PromptRunner{
 		Out:            &buf,
 		Renderer:       renderer,
+		WriteFile:      files.WriteFile,
 		PromptCategory: "topics",
 		Topic:          "demo",
 		Preview:        true,
 		UserQuery:      "what is prompt engineering?",
 	}
 
-	runner.Run()
+	require.NoError(t, runner.Run(context.Background()))
 
-	assert.True(t, renderer.ToStdoutCalled)
+	assert.True(t, renderer.RenderToCalled)
 	assert.Equal(t, "resources/topics/template.yaml", renderer.GotTmpl)
 	assert.Equal(t, "resources/topics/demo/config.yaml", renderer.GotCfg)
 	assert.Equal(t, "what is prompt engineering?", renderer.GotQuery)
-	assert.NotContains(t, buf.String(), "Prompt saved to:")
+	assert.Equal(t, "Preview text\n", buf.String())
+	assert.Empty(t, files.Path, "previews are not saved")
 }
 
 func TestPromptRunner_Run_UsesDerivedPaths(t *testing.T) {
 	var buf bytes.Buffer
 	renderer := &mockRenderer{}
+	files := &fileWriter{}
 
 	runner := PromptRunner{
 		Out:            &buf,
 		Renderer:       renderer,
+		WriteFile:      files.WriteFile,
 		PromptCategory: "topics",
 		Topic:          "demo",
 		UserQuery:      "what are embeddings?",
 	}
 
-	runner.Run()
+	require.NoError(t, runner.Run(context.Background()))
 
-	assert.True(t, renderer.ToFileCalled)
+	assert.True(t, renderer.RenderCalled)
 	assert.Equal(t, "resources/topics/template.yaml", renderer.GotTmpl)
 	assert.Equal(t, "resources/topics/demo/config.yaml", renderer.GotCfg)
-	assert.Equal(t, "resources/topics/demo/prompt.txt", renderer.GotOut)
+	assert.Equal(t, "resources/topics/demo/prompt.txt", files.Path)
 	assert.Equal(t, "what are embeddings?", renderer.GotQuery)
 	assert.Contains(t, buf.String(), "Prompt saved to: resources/topics/demo/prompt.txt")
 }
@@ -112,19 +146,117 @@ func TestPromptRunner_Run_UsesDerivedPaths(t *testing.T) {
 func TestPromptRunner_Run_DefaultsWhenMissing(t *testing.T) {
 	var buf bytes.Buffer
 	renderer := &mockRenderer{}
+	files := &fileWriter{}
 
 	runner := PromptRunner{
 		Out:       &buf,
 		Renderer:  renderer,
+		WriteFile: files.WriteFile,
 		UserQuery: "summarize vector databases",
 	}
 
-	runner.Run()
+	require.NoError(t, runner.Run(context.Background()))
 
-	assert.True(t, renderer.ToFileCalled)
+	assert.True(t, renderer.RenderCalled)
 	assert.Equal(t, "resources/topics/template.yaml", renderer.GotTmpl)
 	assert.Equal(t, "resources/topics/git/config.yaml", renderer.GotCfg)
-	assert.Equal(t, "resources/topics/git/prompt.txt", renderer.GotOut)
+	assert.Equal(t, "resources/topics/git/prompt.txt", files.Path)
 	assert.Equal(t, "summarize vector databases", renderer.GotQuery)
 	assert.Contains(t, buf.String(), "Prompt saved to: resources/topics/git/prompt.txt")
 }
+
+func TestPromptRunner_Run_ReturnsErrors(t *testing.T) {
+	notFound := &prompt.TemplateNotFoundError{Path: "missing.yaml", Err: os.ErrNotExist}
+	failing := &mockRenderer{RenderFn: func(prompt.Request) (string, error) { return "", notFound }}
+
+	for _, preview := range []bool{false, true} {
+		var buf bytes.Buffer
+		files := &fileWriter{}
+		runner := PromptRunner{Out: &buf, Renderer: failing, WriteFile: files.WriteFile, Preview: preview}
+		err := runner.Run(context.Background())
+		assert.Same(t, notFound, err)
+		assert.Empty(t, buf.String(), "nothing is printed when rendering fails")
+		assert.Empty(t, files.Path, "nothing is saved when rendering fails")
+	}
+
+	files := &fileWriter{Err: errors.New("disk full")}
+	runner := PromptRunner{Out: &bytes.Buffer{}, Renderer: &mockRenderer{}, WriteFile: files.WriteFile, Output: "out.txt"}
+	err := runner.Run(context.Background())
+	var writeErr *prompt.WriteError
+	require.ErrorAs(t, err, &writeErr)
+	assert.Equal(t, "out.txt", writeErr.Path)
+	assert.EqualError(t, err, "failed to write output: disk full")
+}
+
+// renderRow renders a dataset row from its topic column.
+func renderRow(req prompt.Request) (string, error) {
+	if req.Vars["topic"] == "fail" {
+		return "", errors.New("template rendering failed")
+	}
+	return fmt.Sprintf("%s %v %s", req.Config, req.Vars["topic"], req.Query), nil
+}
+
+func TestPromptRunner_RunDataset(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data.csv")
+	require.NoError(t, os.WriteFile(data, []byte("id,topic,query\ngit,Git,Why?\n,DNS,\nx,fail,\n"), 0644))
+	outDir := filepath.Join(dir, "out")
+
+	var buf bytes.Buffer
+	runner := PromptRunner{
+		Out:            &buf,
+		Renderer:       &mockRenderer{RenderFn: renderRow},
+		PromptCategory: "topics",
+		Topic:          "demo",
+		UserQuery:      "Default?",
+		Dataset:        data,
+		OutputDir:      outDir,
+		IDColumn:       "id",
+		QueryColumn:    "query",
+	}
+	err := runner.RunDataset(context.Background())
+	assert.EqualError(t, err, "1 of 3 rows failed")
+
+	got, err := os.ReadFile(filepath.Join(outDir, "git.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "resources/topics/demo/config.yaml Git Why?", string(got))
+	got, err = os.ReadFile(filepath.Join(outDir, "2.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "resources/topics/demo/config.yaml DNS Default?", string(got))
+
+	manifest, err := os.ReadFile(filepath.Join(outDir, "manifest.jsonl"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(manifest), "\n"))
+	assert.Contains(t, string(manifest), `{"row":3,"id":"x","status":"error","error":"template rendering failed"}`)
+	assert.Contains(t, buf.String(), "❌ row 3 (x): template rendering failed\n")
+	assert.Contains(t, buf.String(), "Rendered 2 of 3 rows to "+outDir)
+}
+
+func TestPromptRunner_RunDataset_WriteErrors(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data.csv")
+	require.NoError(t, os.WriteFile(data, []byte("id,topic\ngit,Git\n"), 0644))
+	blocked := filepath.Join(dir, "blocked")
+	require.NoError(t, os.WriteFile(blocked, nil, 0644))
+
+	runner := PromptRunner{
+		Out:            io.Discard,
+		Renderer:       &mockRenderer{RenderFn: renderRow},
+		PromptCategory: "topics",
+		Topic:          "demo",
+		Dataset:        data,
+		OutputDir:      filepath.Join(blocked, "out"),
+	}
+	err := runner.RunDataset(context.Background())
+	var writeErr *prompt.WriteError
+	require.ErrorAs(t, err, &writeErr, "the output directory cannot be created")
+	assert.Equal(t, runner.OutputDir, writeErr.Path)
+
+	runner.OutputDir = filepath.Join(dir, "out")
+	manifest := filepath.Join(runner.OutputDir, "manifest.jsonl")
+	require.NoError(t, os.MkdirAll(manifest, 0755))
+	err = runner.RunDataset(context.Background())
+	require.ErrorAs(t, err, &writeErr, "the manifest cannot be created")
+	assert.Equal(t, manifest, writeErr.Path)
+	assert.ErrorContains(t, err, "failed to write output: manifest: ")
+}
@@ -1,37 +1,95 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/cmd/batch"
+	"raja.aiml/ai.explorer/cmd/cache"
+	"raja.aiml/ai.explorer/cmd/chat"
+	"raja.aiml/ai.explorer/cmd/eval"
 	"raja.aiml/ai.explorer/cmd/llm"
 	cmd "raja.aiml/ai.explorer/cmd/prompt"
+	"raja.aiml/ai.explorer/cmd/run"
+	"raja.aiml/ai.explorer/cmd/session"
+	"raja.aiml/ai.explorer/cmd/tokens"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+// Exit codes returned by the CLI.
+const (
+	ExitError    = 1 // any other failure
+	ExitUsage    = 2 // invalid flags
+	ExitTemplate = 3 // template missing, failing to parse or failing to render
+	ExitConfig   = 4 // config unreadable, invalid or rejected by --strict
+	ExitWrite    = 5 // output could not be written
 )
 
 var rootCmd = newRootCmd()
 var exit = os.Exit // overridable for testing
 
 func newRootCmd() *cobra.Command {
-	return &cobra.Command{
+	root := &cobra.Command{
 		Use:           "ai-explorer",
 		Short:         "Prompt generation + LLM interaction CLI",
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
+	// Subcommands inherit this, so every flag error exits with ExitUsage.
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &usageError{err}
+	})
+	return root
 }
 
+// usageError is a command line that could not be parsed.
+type usageError struct{ error }
+
+func (e *usageError) Unwrap() error { return e.error }
+
 func Execute() {
 	_ = godotenv.Load()
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(rootCmd.ErrOrStderr(), "Error: %v\n", err)
-		exit(1)
+		exit(exitCode(err))
+	}
+}
+
+// exitCode maps an error returned by a command to the process exit code.
+func exitCode(err error) int {
+	var (
+		usageErr    *usageError
+		notFoundErr *prompt.TemplateNotFoundError
+		parseErr    *prompt.ParseError
+		renderErr   *prompt.RenderError
+		configErr   *prompt.ConfigError
+		writeErr    *prompt.WriteError
+	)
+	switch {
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	case errors.As(err, &notFoundErr), errors.As(err, &parseErr), errors.As(err, &renderErr):
+		return ExitTemplate
+	case errors.As(err, &configErr):
+		return ExitConfig
+	case errors.As(err, &writeErr):
+		return ExitWrite
 	}
+	return ExitError
 }
 
 func init() {
 	rootCmd.AddCommand(completionCmd)
 	rootCmd.AddCommand(cmd.GetPromptCommand())
 	rootCmd.AddCommand(llm.GetLLMCommand())
+	rootCmd.AddCommand(eval.GetEvalCommand())
+	rootCmd.AddCommand(chat.GetChatCommand())
+	rootCmd.AddCommand(session.GetSessionCommand())
+	rootCmd.AddCommand(run.GetRunCommand())
+	rootCmd.AddCommand(batch.GetBatchCommand())
+	rootCmd.AddCommand(cache.GetCacheCommand())
+	rootCmd.AddCommand(tokens.GetTokensCommand())
 }
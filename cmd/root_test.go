@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/prompt"
 )
 
 func TestExecuteSuccess(t *testing.T) {
@@ -64,3 +66,50 @@ func TestExecuteFailure(t *testing.T) {
 	assert.Contains(t, buf.String(), "Error: something went wrong")
 	assert.Equal(t, 1, code)
 }
+
+func TestExecuteFlagError(t *testing.T) {
+	origRoot := rootCmd
+	origExit := exit
+	defer func() {
+		rootCmd = origRoot
+		exit = origExit
+	}()
+
+	var code int
+	exit = func(c int) {
+		code = c
+	}
+
+	rootCmd = newRootCmd()
+	rootCmd.AddCommand(&cobra.Command{Use: "sub", Run: func(*cobra.Command, []string) {}})
+	rootCmd.SetArgs([]string{"sub", "--nope"})
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+
+	Execute()
+	assert.Contains(t, buf.String(), "Error: unknown flag: --nope")
+	assert.Equal(t, ExitUsage, code)
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"generic", errors.New("boom"), ExitError},
+		{"usage", &usageError{errors.New("unknown flag")}, ExitUsage},
+		{"template not found", &prompt.TemplateNotFoundError{Path: "t.yaml", Err: os.ErrNotExist}, ExitTemplate},
+		{"parse", fmt.Errorf("wrapped: %w", &prompt.ParseError{Line: 3, Err: errors.New("bad tag")}), ExitTemplate},
+		{"render", &prompt.RenderError{Path: "t.yaml", Err: errors.New("missing required variables: user_query")}, ExitTemplate},
+		{"config", &prompt.ConfigError{Path: "c.yaml", Err: errors.New("bad yaml")}, ExitConfig},
+		{"write", &prompt.WriteError{Path: "out.txt", Err: errors.New("disk full")}, ExitWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package run
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/cmd/llmflags"
+	promptCmd "raja.aiml/ai.explorer/cmd/prompt"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+// Cobra command for `run`
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Render a prompt and send it to the LLM in one step",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		promptRunner := &promptCmd.PromptRunner{
+			PromptCategory: promptCategory,
+			Topic:          topic,
+			Template:       templatePath,
+			Config:         configPath,
+			UserQuery:      userQuery,
+		}
+		// A template that fails to load is reported when it is rendered.
+		tmpl, _, _ := promptRunner.ResolvePaths()
+		if t, err := prompt.DefaultBuilder.LoadTemplate(tmpl); err == nil {
+			llmFlags.Recommend(cmd, cmd.OutOrStdout(), "[run]", t.Spec.Recommended)
+		}
+
+		client, err := llmFlags.NewClient()
+		if err != nil {
+			return err
+		}
+
+		runner := &RunRunner{
+			Out:        cmd.OutOrStdout(),
+			Prompt:     promptRunner,
+			AnswerPath: answerPath,
+			Render: func(tmpl, cfg, query string) (prompt.Rendered, error) {
+				return prompt.DefaultBuilder.RenderSections(tmpl, cfg, query)
+			},
+			Chat: func(p prompt.Rendered) (string, error) {
+				answer, err := chat(client, p)
+				if err == nil {
+					llmFlags.ReportFallback(cmd.ErrOrStderr(), "[run]", client)
+				}
+				return answer, err
+			},
+			SaveAnswer: saveAnswer,
+		}
+		return runner.Run()
+	},
+}
+
+// GetRunCommand exposes the `run` Cobra command.
+func GetRunCommand() *cobra.Command {
+	return runCmd
+}
+
+func init() {
+	runCmd.Flags().StringVar(&promptCategory, "category", "", "Base folder for prompt templates (default: topics)")
+	runCmd.Flags().StringVar(&topic, "topic", "", "Topic name (used to infer default paths)")
+	runCmd.Flags().StringVar(&templatePath, "template", "", "Path to template YAML")
+	runCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to config YAML")
+	runCmd.Flags().StringVarP(&userQuery, "query", "q", "", "User query to inject into template context")
+	runCmd.Flags().StringVarP(&answerPath, "output", "o", "", "Path to save the answer (default: answer.md for the topic)")
+
+	defaults := llmflags.Defaults
+	defaults.Verbose = true
+	llmFlags.Register(runCmd, defaults)
+}
+
+// chat sends a rendered prompt, as separate system and user messages when
+// the template has a system section.
+func chat(client llm.ChatModel, p prompt.Rendered) (string, error) {
+	if p.System == "" {
+		return client.Chat(context.Background(), p.User)
+	}
+	return client.ChatMessages(context.Background(), []wrapper.MessageContent{
+		wrapper.TextMessage(wrapper.RoleSystem, p.System),
+		wrapper.TextMessage(wrapper.RoleUser, p.User),
+	})
+}
+
+// saveAnswer writes the LLM answer to the specified file.
+func saveAnswer(answer, path string) error {
+	if err := paths.EnsureDirectoryExists(path); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(answer), 0644)
+}
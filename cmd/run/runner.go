@@ -0,0 +1,44 @@
+package run
+
+import (
+	"fmt"
+	"io"
+
+	promptCmd "raja.aiml/ai.explorer/cmd/prompt"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+// RunRunner renders a prompt in memory and sends it straight to the LLM.
+type RunRunner struct {
+	Out        io.Writer
+	Prompt     *promptCmd.PromptRunner
+	AnswerPath string
+	Render     func(templatePath, configPath, userQuery string) (prompt.Rendered, error)
+	Chat       func(prompt prompt.Rendered) (string, error)
+	SaveAnswer func(answer, path string) error
+}
+
+// Run executes render → chat → save.
+func (r *RunRunner) Run() error {
+	tmpl, cfg, _ := r.Prompt.ResolvePaths()
+	answerPath := paths.GetAnswerPath(r.Prompt.Topic, r.AnswerPath)
+
+	fmt.Fprintf(r.Out, "[run] Rendering %s/%s...\n", r.Prompt.PromptCategory, r.Prompt.Topic)
+	rendered, err := r.Render(tmpl, cfg, r.Prompt.UserQuery)
+	if err != nil {
+		return fmt.Errorf("[run] Prompt error: %w", err)
+	}
+
+	fmt.Fprintln(r.Out, "[run] Running LLM...")
+	answer, err := r.Chat(rendered)
+	if err != nil {
+		return fmt.Errorf("[run] LLM error: %w", err)
+	}
+
+	if err := r.SaveAnswer(answer, answerPath); err != nil {
+		return fmt.Errorf("[run] Failed to save answer: %w", err)
+	}
+	fmt.Fprintf(r.Out, "\n[run] 💾 Answer saved to: %s\n", answerPath)
+	return nil
+}
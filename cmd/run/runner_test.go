@@ -0,0 +1,89 @@
+package run
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	promptCmd "raja.aiml/ai.explorer/cmd/prompt"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+func TestRunRunner_Run_RendersChatsAndSaves(t *testing.T) {
+	var out bytes.Buffer
+	var gotTmpl, gotCfg, gotQuery, sentPrompt, saved, savedPath string
+
+	runner := &RunRunner{
+		Out: &out,
+		Prompt: &promptCmd.PromptRunner{
+			PromptCategory: "classification",
+			Topic:          "router",
+			UserQuery:      "what is ARP?",
+		},
+		Render: func(tmpl, cfg, query string) (prompt.Rendered, error) {
+			gotTmpl, gotCfg, gotQuery = tmpl, cfg, query
+			return prompt.Rendered{User: "rendered prompt"}, nil
+		},
+		Chat: func(p prompt.Rendered) (string, error) {
+			sentPrompt = p.String()
+			return "the answer", nil
+		},
+		SaveAnswer: func(answer, path string) error {
+			saved, savedPath = answer, path
+			return nil
+		},
+	}
+
+	assert.NoError(t, runner.Run())
+	assert.Equal(t, "resources/classification/router/template.yaml", gotTmpl)
+	assert.Equal(t, "resources/classification/router/config.yaml", gotCfg)
+	assert.Equal(t, "what is ARP?", gotQuery)
+	assert.Equal(t, "rendered prompt", sentPrompt)
+	assert.Equal(t, "the answer", saved)
+	assert.Equal(t, paths.GetAnswerPath("router", ""), savedPath)
+	assert.Contains(t, out.String(), "Answer saved to: "+savedPath)
+}
+
+func TestRunRunner_Run_DefaultsAndCustomAnswerPath(t *testing.T) {
+	var savedPath string
+	runner := &RunRunner{
+		Out:        &bytes.Buffer{},
+		Prompt:     &promptCmd.PromptRunner{},
+		AnswerPath: "out/answer.md",
+		Render:     func(string, string, string) (prompt.Rendered, error) { return prompt.Rendered{User: "p"}, nil },
+		Chat:       func(prompt.Rendered) (string, error) { return "a", nil },
+		SaveAnswer: func(_, path string) error { savedPath = path; return nil },
+	}
+
+	assert.NoError(t, runner.Run())
+	assert.Equal(t, "git", runner.Prompt.Topic)
+	assert.Equal(t, "out/answer.md", savedPath)
+}
+
+func TestRunRunner_Run_Errors(t *testing.T) {
+	base := func() *RunRunner {
+		return &RunRunner{
+			Out:        &bytes.Buffer{},
+			Prompt:     &promptCmd.PromptRunner{},
+			Render:     func(string, string, string) (prompt.Rendered, error) { return prompt.Rendered{User: "p"}, nil },
+			Chat:       func(prompt.Rendered) (string, error) { return "a", nil },
+			SaveAnswer: func(string, string) error { return nil },
+		}
+	}
+
+	r := base()
+	r.Render = func(string, string, string) (prompt.Rendered, error) {
+		return prompt.Rendered{}, errors.New("bad template")
+	}
+	assert.ErrorContains(t, r.Run(), "Prompt error: bad template")
+
+	r = base()
+	r.Chat = func(prompt.Rendered) (string, error) { return "", errors.New("timeout") }
+	assert.ErrorContains(t, r.Run(), "LLM error: timeout")
+
+	r = base()
+	r.SaveAnswer = func(string, string) error { return errors.New("disk full") }
+	assert.ErrorContains(t, r.Run(), "Failed to save answer: disk full")
+}
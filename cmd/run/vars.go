@@ -0,0 +1,16 @@
+package run
+
+import (
+	"raja.aiml/ai.explorer/cmd/llmflags"
+)
+
+// CLI flags
+var (
+	promptCategory string
+	topic          string
+	templatePath   string
+	configPath     string
+	answerPath     string
+	userQuery      string
+	llmFlags       llmflags.Flags
+)
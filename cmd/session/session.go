@@ -0,0 +1,162 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
+	"raja.aiml/ai.explorer/session"
+)
+
+// CLI flags
+var (
+	sessionDir   string
+	exportFormat string
+	exportOutput string
+)
+
+// Cobra command for `session`
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "List, inspect, delete or export stored conversation sessions",
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listSessions(cmd.OutOrStdout(), session.NewStore(sessionDir))
+	},
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print a session transcript",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportSession(cmd.OutOrStdout(), session.NewStore(sessionDir), args[0], "markdown")
+	},
+	ValidArgsFunction: completeSessionNames,
+}
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := session.NewStore(sessionDir).Delete(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Session deleted: %s\n", args[0])
+		return nil
+	},
+	ValidArgsFunction: completeSessionNames,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Export a session as Markdown or JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store := session.NewStore(sessionDir)
+		if exportOutput == "" {
+			return exportSession(cmd.OutOrStdout(), store, args[0], exportFormat)
+		}
+
+		if err := exportToFile(store, args[0], exportFormat, exportOutput); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Session exported to: %s\n", exportOutput)
+		return nil
+	},
+	ValidArgsFunction: completeSessionNames,
+}
+
+// GetSessionCommand exposes the `session` Cobra command.
+func GetSessionCommand() *cobra.Command {
+	return sessionCmd
+}
+
+func init() {
+	sessionCmd.PersistentFlags().StringVar(&sessionDir, "session-dir", session.DefaultDir, "Directory where sessions are stored")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "markdown", "Export format: markdown or json")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write the export to a file instead of stdout")
+
+	sessionCmd.AddCommand(listCmd, showCmd, deleteCmd, exportCmd)
+}
+
+// listSessions prints a table of stored sessions.
+func listSessions(out io.Writer, store *session.Store) error {
+	sessions, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		fmt.Fprintf(out, "No sessions in %s\n", store.Dir)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPROVIDER\tMODEL\tMESSAGES\tUPDATED")
+	for _, s := range sessions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", s.Name, s.Provider, s.Model, len(s.Messages), s.UpdatedAt.Format(time.DateTime))
+	}
+	return tw.Flush()
+}
+
+// exportSession writes a session in the requested format.
+func exportSession(out io.Writer, store *session.Store, name, format string) error {
+	sess, err := store.Load(name)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "markdown", "md":
+		_, err = io.WriteString(out, sess.Transcript())
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(sess)
+	default:
+		return fmt.Errorf("unsupported export format %q (use markdown or json)", format)
+	}
+	return err
+}
+
+// exportToFile writes a session export to path. Nothing is written when the
+// session or format is invalid.
+func exportToFile(store *session.Store, name, format, path string) error {
+	var buf bytes.Buffer
+	if err := exportSession(&buf, store, name, format); err != nil {
+		return err
+	}
+	if err := paths.EnsureDirectoryExists(path); err != nil {
+		return &prompt.WriteError{Path: path, Err: err}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return &prompt.WriteError{Path: path, Err: err}
+	}
+	return nil
+}
+
+// completeSessionNames suggests stored session names.
+func completeSessionNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sessions, _ := session.NewStore(sessionDir).List()
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
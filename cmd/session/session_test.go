@@ -0,0 +1,81 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/llm/wrapper"
+	"raja.aiml/ai.explorer/session"
+)
+
+func seedStore(t *testing.T) *session.Store {
+	t.Helper()
+	store := session.NewStore(filepath.Join(t.TempDir(), "sessions"))
+	sess, _, err := store.Open("bgp")
+	assert.NoError(t, err)
+	sess.Provider, sess.Model = "ollama", "phi4"
+	sess.Append(wrapper.RoleUser, "explain BGP", store.Now())
+	sess.Append(wrapper.RoleAssistant, "path-vector routing", store.Now())
+	assert.NoError(t, store.Save(sess))
+	return store
+}
+
+func TestSessionCommand_Metadata(t *testing.T) {
+	cmd := GetSessionCommand()
+	assert.Equal(t, "session", cmd.Use)
+	names := []string{}
+	for _, sub := range cmd.Commands() {
+		names = append(names, sub.Name())
+	}
+	assert.ElementsMatch(t, []string{"list", "show", "delete", "export"}, names)
+}
+
+func TestListSessions(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, listSessions(&buf, seedStore(t)))
+	assert.Contains(t, buf.String(), "NAME")
+	assert.Contains(t, buf.String(), "bgp")
+	assert.Contains(t, buf.String(), "phi4")
+}
+
+func TestListSessions_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, listSessions(&buf, session.NewStore(t.TempDir())))
+	assert.Contains(t, buf.String(), "No sessions")
+}
+
+func TestExportSession_Formats(t *testing.T) {
+	store := seedStore(t)
+
+	var md bytes.Buffer
+	assert.NoError(t, exportSession(&md, store, "bgp", "markdown"))
+	assert.Contains(t, md.String(), "### Assistant\n\npath-vector routing")
+
+	var js bytes.Buffer
+	assert.NoError(t, exportSession(&js, store, "bgp", "json"))
+	var decoded session.Session
+	assert.NoError(t, json.Unmarshal(js.Bytes(), &decoded))
+	assert.Len(t, decoded.Messages, 2)
+
+	assert.ErrorContains(t, exportSession(&js, store, "bgp", "xml"), "unsupported export format")
+	assert.ErrorIs(t, exportSession(&js, store, "missing", "json"), session.ErrNotFound)
+}
+
+func TestExportToFile(t *testing.T) {
+	store := seedStore(t)
+	out := filepath.Join(t.TempDir(), "exports", "bgp.json")
+
+	assert.ErrorContains(t, exportToFile(store, "bgp", "xml", out), "unsupported export format")
+	assert.NoFileExists(t, out, "an invalid format writes nothing")
+	assert.ErrorIs(t, exportToFile(store, "missing", "json", out), session.ErrNotFound)
+	assert.NoFileExists(t, out)
+
+	assert.NoError(t, exportToFile(store, "bgp", "json", out))
+	data, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"path-vector routing"`)
+}
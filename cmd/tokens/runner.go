@@ -0,0 +1,49 @@
+package tokens
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// TokensRunner counts a prompt's tokens and estimates what sending it costs.
+type TokensRunner struct {
+	Out              io.Writer
+	PromptPath       string
+	Provider         string
+	Model            string
+	CompletionTokens int
+	ReadPrompt       func(path string) (string, error)
+	Counter          *tokens.Counter
+	Prices           *tokens.PriceTable
+}
+
+// Run prints the token count and cost estimate.
+func (r *TokensRunner) Run() error {
+	prompt, err := r.ReadPrompt(r.PromptPath)
+	if err != nil {
+		return fmt.Errorf("failed to read prompt: %w", err)
+	}
+	count := r.Counter.Count(r.Provider, r.Model, prompt)
+
+	fmt.Fprintf(r.Out, "Prompt:     %s\n", r.PromptPath)
+	fmt.Fprintf(r.Out, "Model:      %s:%s\n", r.Provider, r.Model)
+	fmt.Fprintf(r.Out, "Characters: %s\n", tokens.FormatInt(utf8.RuneCountInString(prompt)))
+	fmt.Fprintf(r.Out, "Tokens:     %s\n", count.Describe())
+
+	usage := tokens.Usage{PromptTokens: count.Tokens, CompletionTokens: r.CompletionTokens}
+	cost, ok := r.Prices.Cost(r.Provider, r.Model, usage)
+	switch {
+	case !ok:
+		fmt.Fprintf(r.Out, "Cost:       unknown (no price for %s:%s; add one with --prices)\n", r.Provider, r.Model)
+	case r.CompletionTokens > 0:
+		fmt.Fprintf(r.Out, "Cost:       %s (input %s + output %s for %s completion tokens)\n",
+			cost, tokens.Cost{Input: cost.Input, Currency: cost.Currency},
+			tokens.Cost{Output: cost.Output, Currency: cost.Currency}, tokens.FormatInt(r.CompletionTokens))
+	default:
+		fmt.Fprintf(r.Out, "Cost:       %s input\n", cost)
+	}
+	return nil
+}
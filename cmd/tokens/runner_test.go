@@ -0,0 +1,60 @@
+package tokens
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// --- Mocks ---
+
+type wordEncoder struct{}
+
+func (wordEncoder) Encode(text string, _, _ []string) []int {
+	return make([]int, len(strings.Fields(text)))
+}
+
+func (wordEncoder) Decode([]int) string { return "" }
+
+func newRunner(out *bytes.Buffer, provider, model string) *TokensRunner {
+	return &TokensRunner{
+		Out:        out,
+		PromptPath: "prompt.txt",
+		Provider:   provider,
+		Model:      model,
+		ReadPrompt: func(string) (string, error) { return "count these four words", nil },
+		Counter:    &tokens.Counter{Load: func(string) (tokens.Encoder, error) { return wordEncoder{}, nil }},
+		Prices:     tokens.DefaultPriceTable(),
+	}
+}
+
+// --- Tests ---
+
+func TestTokensRunner_Run(t *testing.T) {
+	var out bytes.Buffer
+	r := newRunner(&out, "openai", "gpt-4o-mini")
+	r.CompletionTokens = 1_000_000
+
+	assert.NoError(t, r.Run())
+	assert.Contains(t, out.String(), "Model:      openai:gpt-4o-mini")
+	assert.Contains(t, out.String(), "Characters: 22")
+	assert.Contains(t, out.String(), "Tokens:     4 tokens (cl100k_base, approximate)")
+	assert.Contains(t, out.String(), "Cost:       $0.6000 (input $0.0000 + output $0.6000 for 1,000,000 completion tokens)")
+}
+
+func TestTokensRunner_UnknownPrice(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, newRunner(&out, "openai", "mystery").Run())
+	assert.Contains(t, out.String(), "Cost:       unknown (no price for openai:mystery")
+}
+
+func TestTokensRunner_ReadError(t *testing.T) {
+	var out bytes.Buffer
+	r := newRunner(&out, "ollama", "phi4")
+	r.ReadPrompt = func(string) (string, error) { return "", errors.New("no such file") }
+	assert.ErrorContains(t, r.Run(), "failed to read prompt: no such file")
+}
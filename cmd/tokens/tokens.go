@@ -0,0 +1,63 @@
+package tokens
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// CLI flags
+var (
+	promptPath       string
+	provider         string
+	model            string
+	pricesPath       string
+	completionTokens int
+)
+
+// Cobra command for `tokens`
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "Count a prompt's tokens and estimate its cost",
+	Example: `  ai-explorer tokens --prompt resources/codegen/evaluator/instructions.txt
+  ai-explorer tokens -p prompt.txt -l openai -m gpt-4o-mini --completion-tokens 2000`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prices, err := tokens.LoadPriceTable(pricesPath)
+		if err != nil {
+			return err
+		}
+		runner := &TokensRunner{
+			Out:              cmd.OutOrStdout(),
+			PromptPath:       promptPath,
+			Provider:         provider,
+			Model:            model,
+			CompletionTokens: completionTokens,
+			ReadPrompt:       readPrompt,
+			Counter:          tokens.Default,
+			Prices:           prices,
+		}
+		return runner.Run()
+	},
+}
+
+// GetTokensCommand exposes the `tokens` Cobra command.
+func GetTokensCommand() *cobra.Command {
+	return tokensCmd
+}
+
+func init() {
+	tokensCmd.Flags().StringVarP(&promptPath, "prompt", "p", "", "Prompt file to measure")
+	tokensCmd.Flags().StringVarP(&provider, "provider", "l", llmConfig.DefaultProvider, "LLM provider")
+	tokensCmd.Flags().StringVarP(&model, "model", "m", llmConfig.DefaultModelName, "LLM model")
+	tokensCmd.Flags().StringVar(&pricesPath, "prices", "", "YAML price table extending the built-in prices")
+	tokensCmd.Flags().IntVar(&completionTokens, "completion-tokens", 0, "Expected completion tokens to include in the estimate")
+	_ = tokensCmd.MarkFlagRequired("prompt")
+}
+
+func readPrompt(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
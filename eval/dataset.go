@@ -0,0 +1,73 @@
+package eval
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DefaultDatasetPath points at the router ground-truth shipped with the repo.
+const DefaultDatasetPath = "resources/classification/router/ground-truth/data.csv"
+
+// Case is a single labelled query from a ground-truth dataset.
+type Case struct {
+	Row      int               // 1-based data row number (header excluded)
+	Query    string            // Query sent to the template as user_query
+	Expected map[string]string // Remaining columns keyed by header name
+}
+
+// LoadCases reads a ground-truth CSV file. The header must contain a
+// `query` column; every other column is kept as an expected value.
+func LoadCases(path string) ([]Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer f.Close()
+	return ReadCases(f)
+}
+
+// ReadCases parses ground-truth rows from any CSV reader.
+func ReadCases(r io.Reader) ([]Case, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset header: %w", err)
+	}
+	queryIdx := -1
+	for i, col := range header {
+		header[i] = strings.TrimSpace(col)
+		if header[i] == "query" {
+			queryIdx = i
+		}
+	}
+	if queryIdx < 0 {
+		return nil, fmt.Errorf("dataset header has no 'query' column")
+	}
+
+	var cases []Case
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dataset row %d: %w", row, err)
+		}
+
+		c := Case{Row: row, Expected: make(map[string]string, len(header)-1)}
+		for i, col := range header {
+			if i == queryIdx {
+				c.Query = record[i]
+				continue
+			}
+			c.Expected[col] = strings.TrimSpace(record[i])
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
@@ -0,0 +1,42 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCases_ParsesColumns(t *testing.T) {
+	csv := "query,expected_technique,tone\n" +
+		"\"Explain BGP, briefly\",Zero-shot,curious\n" +
+		"Fix my build,Step-back, frustrated\n"
+
+	cases, err := ReadCases(strings.NewReader(csv))
+	assert.NoError(t, err)
+	assert.Len(t, cases, 2)
+	assert.Equal(t, 1, cases[0].Row)
+	assert.Equal(t, "Explain BGP, briefly", cases[0].Query)
+	assert.Equal(t, "Zero-shot", cases[0].Expected["expected_technique"])
+	assert.Equal(t, "frustrated", cases[1].Expected["tone"])
+	assert.NotContains(t, cases[0].Expected, "query")
+}
+
+func TestReadCases_MissingQueryColumn(t *testing.T) {
+	_, err := ReadCases(strings.NewReader("prompt,tone\nhi,neutral\n"))
+	assert.ErrorContains(t, err, "no 'query' column")
+}
+
+func TestLoadCases_RouterGroundTruth(t *testing.T) {
+	cases, err := LoadCases("../" + DefaultDatasetPath)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cases)
+	for _, f := range RouterFields {
+		assert.Contains(t, cases[0].Expected, f.Column)
+	}
+}
+
+func TestLoadCases_MissingFile(t *testing.T) {
+	_, err := LoadCases("does-not-exist.csv")
+	assert.ErrorContains(t, err, "failed to open dataset")
+}
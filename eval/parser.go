@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrNoClassification is returned when a reply has no "Final Classification" line.
+var ErrNoClassification = errors.New("no 'Final Classification' found in reply")
+
+// keyValueLine matches "Key: value" lines, tolerating markdown decoration.
+var keyValueLine = regexp.MustCompile(`^[\s>*#-]*\**([A-Za-z][A-Za-z _]*?)\**\s*:\**\s*(.*)$`)
+
+// ParseReply extracts the "Final Classification" and the Metadata fields from
+// a router reply. Keys are normalised to snake_case, so "Final Classification"
+// becomes "final_classification". Fields under the Metadata block take
+// precedence over identically named keys elsewhere in the reply.
+func ParseReply(reply string) (map[string]string, error) {
+	top := map[string]string{}
+	meta := map[string]string{}
+	inMetadata := false
+
+	for _, line := range strings.Split(reply, "\n") {
+		m := keyValueLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		key := normalizeKey(m[1])
+		value := cleanValue(m[2])
+
+		if key == "metadata" {
+			inMetadata = true
+			continue
+		}
+		if value == "" || value == "|" || value == ">" {
+			continue
+		}
+
+		target := top
+		if inMetadata {
+			target = meta
+		}
+		if _, seen := target[key]; !seen {
+			target[key] = value
+		}
+	}
+
+	if _, ok := top["final_classification"]; !ok {
+		if _, ok := meta["final_classification"]; !ok {
+			return nil, ErrNoClassification
+		}
+	}
+
+	fields := top
+	for k, v := range meta {
+		fields[k] = v
+	}
+	return fields, nil
+}
+
+// normalizeKey lowercases a key and joins words with underscores.
+func normalizeKey(key string) string {
+	return strings.Join(strings.Fields(strings.ToLower(key)), "_")
+}
+
+// cleanValue strips quotes, brackets, markdown emphasis and trailing comments.
+func cleanValue(value string) string {
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.Trim(strings.TrimSpace(value), "\"'`*[] ")
+}
+
+// Normalize folds a label so that "Chain of Thought", "chain-of-thought"
+// and "chain_of_thought" compare equal.
+func Normalize(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	return strings.Join(strings.FieldsFunc(label, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "-")
+}
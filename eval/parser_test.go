@@ -0,0 +1,47 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleReply = "```yaml\n" + `---
+Tree of Thought: |
+  - The user may want a conceptual overview
+Chain of Thought Reasoning: |
+  The query is about a protocol.
+**Final Classification:** Chain-of-thought
+Explanation: |
+  tone: this line is prose, not metadata
+Metadata:
+  intent_keywords: ["explain", "protocol"]
+  tone: "curious"            # Query tone
+  urgency_level: "low"
+  query_complexity: "intermediate"
+  task_type: "information_retrieval"
+  user_type: "network engineer"
+  prompt_technique: "Chain-of-thought"
+---
+` + "```"
+
+func TestParseReply_ExtractsClassificationAndMetadata(t *testing.T) {
+	fields, err := ParseReply(sampleReply)
+	assert.NoError(t, err)
+	assert.Equal(t, "Chain-of-thought", fields["final_classification"])
+	assert.Equal(t, "curious", fields["tone"])
+	assert.Equal(t, "low", fields["urgency_level"])
+	assert.Equal(t, "intermediate", fields["query_complexity"])
+	assert.Equal(t, "network engineer", fields["user_type"])
+}
+
+func TestParseReply_NoClassification(t *testing.T) {
+	_, err := ParseReply("I cannot help with that.")
+	assert.ErrorIs(t, err, ErrNoClassification)
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, Normalize("Chain-of-thought"), Normalize("chain of thought"))
+	assert.Equal(t, Normalize("network_engineer"), Normalize("Network Engineer"))
+	assert.NotEqual(t, Normalize("Few-shot"), Normalize("Multi-shot"))
+}
@@ -0,0 +1,204 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Field maps a dataset column to the reply keys that answer it.
+type Field struct {
+	Name   string   // Display name
+	Column string   // Ground-truth CSV column
+	Keys   []string // Parsed reply keys, tried in order
+}
+
+// RouterFields are the columns of the router ground-truth that the router
+// template asks the model to produce. expected_intent has no counterpart in
+// the reply, which lists intent_keywords rather than an intent label.
+var RouterFields = []Field{
+	{Name: "technique", Column: "expected_technique", Keys: []string{"final_classification", "prompt_technique"}},
+	{Name: "query_complexity", Column: "query_complexity", Keys: []string{"query_complexity"}},
+	{Name: "task_type", Column: "task_type", Keys: []string{"task_type"}},
+	{Name: "tone", Column: "tone", Keys: []string{"tone"}},
+	{Name: "urgency_level", Column: "urgency_level", Keys: []string{"urgency_level"}},
+	{Name: "user_type", Column: "user_type", Keys: []string{"user_type"}},
+}
+
+// Result pairs a case with the fields parsed from the model reply.
+type Result struct {
+	Case      Case
+	Predicted map[string]string
+	Err       error
+}
+
+// predicted returns the first non-empty reply value for the field.
+func (r Result) predicted(f Field) string {
+	for _, k := range f.Keys {
+		if v := r.Predicted[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// FieldScore holds accuracy counters for a single field.
+type FieldScore struct {
+	Field   string
+	Correct int
+	Total   int
+}
+
+// Accuracy returns the fraction of correct predictions.
+func (s FieldScore) Accuracy() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Total)
+}
+
+// Mismatch describes a single wrong field on a case.
+type Mismatch struct {
+	Field     string
+	Expected  string
+	Predicted string
+}
+
+// Miss lists every wrong field of a misclassified case.
+type Miss struct {
+	Case       Case
+	Err        error
+	Mismatches []Mismatch
+}
+
+// Confusion counts expected→predicted label pairs, keyed by normalised label.
+type Confusion map[string]map[string]int
+
+// Report is the outcome of scoring a set of results.
+type Report struct {
+	Total     int
+	Failed    int
+	Scores    []FieldScore
+	Confusion Confusion
+	Misses    []Miss
+}
+
+// Score compares results against their expected values. The confusion
+// matrix is built for the first field. Failed results count as wrong on
+// every field.
+func Score(results []Result, fields []Field) Report {
+	report := Report{
+		Total:     len(results),
+		Scores:    make([]FieldScore, len(fields)),
+		Confusion: Confusion{},
+	}
+	for i, f := range fields {
+		report.Scores[i].Field = f.Name
+	}
+
+	for _, r := range results {
+		miss := Miss{Case: r.Case, Err: r.Err}
+		if r.Err != nil {
+			report.Failed++
+		}
+
+		for i, f := range fields {
+			expected := r.Case.Expected[f.Column]
+			got := r.predicted(f)
+			report.Scores[i].Total++
+
+			if i == 0 {
+				report.Confusion.add(expected, got)
+			}
+			if r.Err == nil && Normalize(expected) == Normalize(got) {
+				report.Scores[i].Correct++
+				continue
+			}
+			if r.Err == nil {
+				miss.Mismatches = append(miss.Mismatches, Mismatch{Field: f.Name, Expected: expected, Predicted: got})
+			}
+		}
+
+		if r.Err != nil || len(miss.Mismatches) > 0 {
+			report.Misses = append(report.Misses, miss)
+		}
+	}
+	return report
+}
+
+// add counts a pair, folding both labels with Normalize as scoring does.
+func (c Confusion) add(expected, predicted string) {
+	expected, predicted = Normalize(expected), Normalize(predicted)
+	if predicted == "" {
+		predicted = "<none>"
+	}
+	if c[expected] == nil {
+		c[expected] = map[string]int{}
+	}
+	c[expected][predicted]++
+}
+
+// labels returns the sorted union of expected and predicted labels.
+func (c Confusion) labels() []string {
+	seen := map[string]bool{}
+	for exp, row := range c {
+		seen[exp] = true
+		for pred := range row {
+			seen[pred] = true
+		}
+	}
+	labels := make([]string, 0, len(seen))
+	for l := range seen {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// Write prints the accuracy table, confusion matrix and misclassified rows.
+func (r Report) Write(w io.Writer) {
+	fmt.Fprintf(w, "Evaluated %d cases (%d failed)\n\n", r.Total, r.Failed)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tCORRECT\tTOTAL\tACCURACY")
+	for _, s := range r.Scores {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\n", s.Field, s.Correct, s.Total, 100*s.Accuracy())
+	}
+	tw.Flush()
+
+	if len(r.Confusion) > 0 && len(r.Scores) > 0 {
+		fmt.Fprintf(w, "\nConfusion matrix (%s, rows=expected, cols=predicted)\n", r.Scores[0].Field)
+		labels := r.Confusion.labels()
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(tw, "\t%s\t\n", strings.Join(labels, "\t"))
+		for _, exp := range labels {
+			row, ok := r.Confusion[exp]
+			if !ok {
+				continue
+			}
+			cells := make([]string, len(labels))
+			for i, pred := range labels {
+				cells[i] = fmt.Sprint(row[pred])
+			}
+			fmt.Fprintf(tw, "%s\t%s\t\n", exp, strings.Join(cells, "\t"))
+		}
+		tw.Flush()
+	}
+
+	if len(r.Misses) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nMisclassified rows (%d)\n", len(r.Misses))
+	for _, m := range r.Misses {
+		fmt.Fprintf(w, "- row %d: %q\n", m.Case.Row, m.Case.Query)
+		if m.Err != nil {
+			fmt.Fprintf(w, "    error: %v\n", m.Err)
+			continue
+		}
+		for _, mm := range m.Mismatches {
+			fmt.Fprintf(w, "    %s: expected %q, got %q\n", mm.Field, mm.Expected, mm.Predicted)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testFields = []Field{
+	{Name: "technique", Column: "expected_technique", Keys: []string{"final_classification"}},
+	{Name: "tone", Column: "tone", Keys: []string{"tone"}},
+}
+
+func testCase(row int, technique, tone string) Case {
+	return Case{
+		Row:      row,
+		Query:    "q",
+		Expected: map[string]string{"expected_technique": technique, "tone": tone},
+	}
+}
+
+func TestScore_CountsAccuracyAndMisses(t *testing.T) {
+	results := []Result{
+		{Case: testCase(1, "Zero-shot", "curious"), Predicted: map[string]string{"final_classification": "zero shot", "tone": "curious"}},
+		{Case: testCase(2, "Step-back", "frustrated"), Predicted: map[string]string{"final_classification": "Chain-of-thought", "tone": "frustrated"}},
+		{Case: testCase(3, "Few-shot", "neutral"), Err: errors.New("timeout")},
+	}
+
+	report := Score(results, testFields)
+
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, FieldScore{Field: "technique", Correct: 1, Total: 3}, report.Scores[0])
+	assert.Equal(t, FieldScore{Field: "tone", Correct: 2, Total: 3}, report.Scores[1])
+	assert.Equal(t, 1, report.Confusion["zero-shot"]["zero-shot"], "labels scored equal sit on the diagonal")
+	assert.Equal(t, 1, report.Confusion["step-back"]["chain-of-thought"])
+	assert.Equal(t, 1, report.Confusion["few-shot"]["<none>"])
+
+	assert.Len(t, report.Misses, 2)
+	assert.Equal(t, 2, report.Misses[0].Case.Row)
+	assert.Equal(t, []Mismatch{{Field: "technique", Expected: "Step-back", Predicted: "Chain-of-thought"}}, report.Misses[0].Mismatches)
+	assert.Error(t, report.Misses[1].Err)
+}
+
+func TestReport_Write(t *testing.T) {
+	results := []Result{
+		{Case: testCase(1, "Zero-shot", "curious"), Predicted: map[string]string{"final_classification": "Few-shot", "tone": "curious"}},
+	}
+
+	var buf bytes.Buffer
+	Score(results, testFields).Write(&buf)
+	out := buf.String()
+
+	assert.Contains(t, out, "Evaluated 1 cases (0 failed)")
+	assert.Contains(t, out, "technique")
+	assert.Contains(t, out, "0.0%")
+	assert.Contains(t, out, "100.0%")
+	assert.Contains(t, out, "Confusion matrix (technique")
+	assert.Contains(t, out, "Misclassified rows (1)")
+	assert.Contains(t, out, `technique: expected "Zero-shot", got "Few-shot"`)
+}
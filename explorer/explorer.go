@@ -0,0 +1,213 @@
+// Package explorer renders ai-explorer prompts and calls the models the CLI
+// uses, for Go programs that embed them. It reads no flags and no
+// environment: everything comes from the options given to New.
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"raja.aiml/ai.explorer/llm"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/llm/wrapper"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+// Defaults used when no option overrides them.
+const (
+	DefaultProvider    = llmConfig.DefaultProvider
+	DefaultModel       = llmConfig.DefaultModelName
+	DefaultTemperature = llmConfig.DefaultTemperature
+	DefaultTimeout     = llmConfig.DefaultTimeout
+	DefaultOllamaURL   = "http://localhost:11434"
+	DefaultCategory    = "topics"
+)
+
+// defaultEmbeddingModels are the embedding models used per provider
+// unless WithEmbeddingModel is given.
+var defaultEmbeddingModels = map[string]string{
+	"ollama": "nomic-embed-text",
+	"openai": "text-embedding-3-small",
+}
+
+// Explorer renders prompts and sends them to models. It is safe for
+// concurrent use; every method honours cancellation of its context.
+type Explorer struct {
+	resources  string
+	renderer   prompt.Renderer
+	chat       llm.LLM
+	similarity *llm.SimilarityService
+	// embedErr explains why similarity is nil.
+	embedErr error
+}
+
+// Prompt names a prompt to render. Template and Config default to the
+// files of Topic within Category, as `ai-explorer prompt` derives them.
+type Prompt struct {
+	Category string
+	Topic    string
+	Template string
+	Config   string
+	// Query is exposed to the template as user_query when set.
+	Query string
+	// Vars are merged over the config.
+	Vars map[string]any
+}
+
+// New returns an Explorer for the given options. Without any it renders
+// from ./resources and talks to phi4 on a local Ollama server.
+func New(opts ...Option) (*Explorer, error) {
+	o := &options{
+		config: llmConfig.Config{
+			Provider: DefaultProvider,
+			Model:    llmConfig.ModelConfig{Name: DefaultModel, Temperature: DefaultTemperature},
+			Client:   llmConfig.ClientConfig{Timeout: DefaultTimeout, Retry: llmConfig.DefaultRetryPolicy()},
+		},
+		ollamaURL: DefaultOllamaURL,
+		resources: paths.ResourcesDir,
+		lookupEnv: func(string) (string, bool) { return "", false },
+	}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+
+	e := &Explorer{
+		resources: o.resources,
+		renderer: &prompt.Builder{
+			ReadFile:     os.ReadFile,
+			TemplateRoot: o.resources,
+			LoadSchema:   prompt.LoadSchemaFor,
+			Strict:       o.strict,
+			Logger:       o.logger,
+			LookupEnv:    o.lookupEnv,
+		},
+	}
+
+	provider := llm.NewProvider(o.config)
+	provider.OllamaURL = o.ollamaURL
+	provider.OpenAIToken = o.openAIKey
+	provider.OpenAIBaseURL = o.openAIBaseURL
+	if provider.OpenAIToken == "" && o.config.Client.ReplayDir != "" {
+		// Recorded traffic carries no auth headers, so any key will do.
+		provider.OpenAIToken = "replay"
+	}
+
+	e.chat = o.chat
+	if e.chat == nil {
+		if provider.OpenAIToken == "" && usesProvider(o.config, "openai") {
+			return nil, errors.New("explorer: OpenAI backends need an API key; use WithOpenAIKey")
+		}
+		client, err := llm.NewClient(o.config, provider, wrapper.GenerateFromSinglePrompt)
+		if err != nil {
+			return nil, err
+		}
+		e.chat = client
+	}
+	if o.cacheDir != "" {
+		key := llm.CacheKeyFor(o.config)
+		if usesProvider(o.config, "ollama") {
+			key = key.WithOption("ollama_host", o.ollamaURL)
+		}
+		if usesProvider(o.config, "openai") {
+			key = key.WithOption("openai_base_url", o.openAIBaseURL)
+		}
+		e.chat = llm.NewCached(e.chat, llm.NewCache(o.cacheDir), key)
+	}
+
+	embedder := o.embedder
+	if embedder == nil {
+		model := o.embedModel
+		if model == "" {
+			model = defaultEmbeddingModels[o.config.Provider]
+		}
+		impl, err := provider.InitEmbedder(o.config.Provider, model)
+		if err != nil {
+			e.embedErr = fmt.Errorf("explorer: %w; use WithEmbedder", err)
+		} else {
+			embedder = impl
+		}
+	}
+	if embedder != nil {
+		e.similarity = llm.NewSimilarityService(embedder)
+	}
+	return e, nil
+}
+
+// usesProvider reports whether any backend of cfg is provider.
+func usesProvider(cfg llmConfig.Config, provider string) bool {
+	for _, b := range cfg.Backends() {
+		if b.Provider == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// Render renders p. Failures are reported as the error types of the prompt
+// package, such as *prompt.TemplateNotFoundError or *prompt.ConfigError.
+func (e *Explorer) Render(ctx context.Context, p Prompt) (string, error) {
+	req, err := e.request(p)
+	if err != nil {
+		return "", err
+	}
+	return e.renderer.Render(ctx, req)
+}
+
+// request resolves the template and config of p.
+func (e *Explorer) request(p Prompt) (prompt.Request, error) {
+	req := prompt.Request{Template: p.Template, Config: p.Config, Query: p.Query, Vars: p.Vars}
+	if req.Template != "" && req.Config != "" {
+		return req, nil
+	}
+	if p.Topic == "" {
+		return req, errors.New("explorer: a prompt needs a topic, or both a template and a config")
+	}
+	category := p.Category
+	if category == "" {
+		category = DefaultCategory
+	}
+	template, config, _ := paths.PathResolver{PromptCategory: category, Root: e.resources}.Derive(p.Topic)
+	if req.Template == "" {
+		req.Template = template
+	}
+	if req.Config == "" {
+		req.Config = config
+	}
+	return req, nil
+}
+
+// Chat sends text to the model and returns its answer. Model failures are
+// returned as *llm.Error.
+func (e *Explorer) Chat(ctx context.Context, text string) (string, error) {
+	return e.chat.Chat(ctx, text)
+}
+
+// Stream is Chat, calling onChunk with each piece of the answer as it
+// arrives. An error from onChunk aborts the call.
+func (e *Explorer) Stream(ctx context.Context, text string, onChunk func(chunk string) error) (string, error) {
+	ctx = llm.WithStreamHandler(ctx, func(_ context.Context, chunk []byte) error {
+		return onChunk(string(chunk))
+	})
+	return e.chat.Chat(ctx, text)
+}
+
+// Embed returns one embedding per input.
+func (e *Explorer) Embed(ctx context.Context, inputs ...string) ([][]float32, error) {
+	if e.similarity == nil {
+		return nil, e.embedErr
+	}
+	return e.similarity.GetEmbeddings(ctx, inputs)
+}
+
+// Similarity returns the cosine similarity of the embeddings of a and b.
+func (e *Explorer) Similarity(ctx context.Context, a, b string) (float64, error) {
+	if e.similarity == nil {
+		return 0, e.embedErr
+	}
+	return e.similarity.Compare(ctx, a, b)
+}
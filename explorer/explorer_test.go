@@ -0,0 +1,189 @@
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+const mockFixtures = "../tests/e2e/testdata/mock.yaml"
+
+// writeResources creates a resources tree with one topic, demo.
+func writeResources(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"topics/template.yaml":    "Explain {{ topic }} to {{ team }}.{% if user_query %} {{ user_query }}{% endif %}",
+		"topics/demo/config.yaml": "topic: DNS\nteam: ${TEAM:-everyone}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return root
+}
+
+// vectorEmbedder embeds known words as fixed vectors.
+type vectorEmbedder map[string][]float32
+
+func (v vectorEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	out := make([][]float32, len(inputs))
+	for i, in := range inputs {
+		vec, ok := v[in]
+		if !ok {
+			return nil, fmt.Errorf("no vector for %q", in)
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func TestNew_RejectsInvalidOptions(t *testing.T) {
+	_, err := New(WithModel("echo", ""))
+	assert.EqualError(t, err, "explorer: provider and model are required")
+
+	_, err = New(WithFallbacks(":"))
+	assert.Error(t, err)
+
+	_, err = New(WithModel("openai", "gpt-4o-mini"))
+	assert.EqualError(t, err, "explorer: OpenAI backends need an API key; use WithOpenAIKey")
+
+	_, err = New(WithModel("openai", "gpt-4o-mini"), WithOpenAIKey("sk-test"))
+	assert.NoError(t, err)
+}
+
+func TestExplorer_Render(t *testing.T) {
+	resources := writeResources(t)
+	ctx := context.Background()
+
+	ex, err := New(WithResources(resources))
+	require.NoError(t, err)
+
+	got, err := ex.Render(ctx, Prompt{Topic: "demo", Query: "Keep it short."})
+	require.NoError(t, err)
+	assert.Equal(t, "Explain DNS to everyone. Keep it short.", got)
+
+	got, err = ex.Render(ctx, Prompt{Topic: "demo", Vars: map[string]any{"topic": "BGP"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Explain BGP to everyone.", got)
+
+	_, err = ex.Render(ctx, Prompt{Category: "missing", Topic: "demo"})
+	var notFound *prompt.TemplateNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+
+	_, err = ex.Render(ctx, Prompt{})
+	assert.EqualError(t, err, "explorer: a prompt needs a topic, or both a template and a config")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = ex.Render(cancelled, Prompt{Topic: "demo"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExplorer_RenderReadsOnlyTheGivenEnvironment(t *testing.T) {
+	resources := writeResources(t)
+	t.Setenv("TEAM", "from the process")
+
+	ex, err := New(WithResources(resources))
+	require.NoError(t, err)
+	got, err := ex.Render(context.Background(), Prompt{Topic: "demo"})
+	require.NoError(t, err)
+	assert.Equal(t, "Explain DNS to everyone.", got)
+
+	lookup := func(key string) (string, bool) { return map[string]string{"TEAM": "SREs"}[key], key == "TEAM" }
+	ex, err = New(WithResources(resources), WithEnv(lookup))
+	require.NoError(t, err)
+	got, err = ex.Render(context.Background(), Prompt{Topic: "demo"})
+	require.NoError(t, err)
+	assert.Equal(t, "Explain DNS to SREs.", got)
+}
+
+func TestExplorer_ChatAndStream(t *testing.T) {
+	ex, err := New(WithModel("mock", mockFixtures))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	got, err := ex.Chat(ctx, "What is a route reflector?")
+	require.NoError(t, err)
+	assert.Contains(t, got, "A route reflector lets iBGP peers skip the full mesh")
+
+	var chunks []string
+	got, err = ex.Stream(ctx, "What is a route reflector?", func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Greater(t, len(chunks), 1)
+	assert.Equal(t, got, strings.Join(chunks, ""))
+
+	stop := errors.New("stop")
+	_, err = ex.Stream(ctx, "What is a route reflector?", func(string) error { return stop })
+	assert.ErrorIs(t, err, stop)
+
+	_, err = ex.Chat(ctx, "Please simulate overload.")
+	var llmErr *llm.Error
+	assert.ErrorAs(t, err, &llmErr)
+}
+
+func TestExplorer_ConcurrentCallsWithCache(t *testing.T) {
+	resources := writeResources(t)
+	ex, err := New(WithModel("echo", "echo"), WithResources(resources), WithCache(t.TempDir()))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			text, err := ex.Render(ctx, Prompt{Topic: "demo", Query: fmt.Sprintf("Call %d.", i%2)})
+			if !assert.NoError(t, err) {
+				return
+			}
+			var streamed strings.Builder
+			got, err := ex.Stream(ctx, text, func(chunk string) error {
+				streamed.WriteString(chunk)
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, text, got)
+			assert.Equal(t, text, streamed.String())
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestExplorer_EmbedAndSimilarity(t *testing.T) {
+	ex, err := New(WithModel("echo", "echo"), WithEmbedder(vectorEmbedder{
+		"router": {1, 0},
+		"switch": {1, 1},
+	}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	vecs, err := ex.Embed(ctx, "router", "switch")
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {1, 1}}, vecs)
+
+	sim, err := ex.Similarity(ctx, "router", "switch")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.7071, sim, 1e-4)
+
+	ex, err = New(WithModel("echo", "echo"))
+	require.NoError(t, err)
+	_, err = ex.Embed(ctx, "router")
+	assert.EqualError(t, err, "explorer: LLM provider echo does not support embeddings; use WithEmbedder")
+	_, err = ex.Similarity(ctx, "router", "switch")
+	assert.Error(t, err)
+}
@@ -0,0 +1,188 @@
+package explorer
+
+import (
+	"errors"
+	"time"
+
+	"raja.aiml/ai.explorer/llm"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/llm/wrapper"
+	"raja.aiml/ai.explorer/logger"
+)
+
+// Option configures an Explorer.
+type Option func(*options) error
+
+// options collects the settings New builds an Explorer from.
+type options struct {
+	config        llmConfig.Config
+	ollamaURL     string
+	openAIKey     string
+	openAIBaseURL string
+	cacheDir      string
+	resources     string
+	strict        bool
+	lookupEnv     func(key string) (string, bool)
+	logger        logger.Logger
+	embedModel    string
+	embedder      wrapper.Embedder
+	chat          llm.LLM
+}
+
+// WithModel selects the provider and model, e.g. "ollama", "phi4". The
+// offline providers echo, mock and replay work too.
+func WithModel(provider, model string) Option {
+	return func(o *options) error {
+		if provider == "" || model == "" {
+			return errors.New("explorer: provider and model are required")
+		}
+		o.config.Provider, o.config.Model.Name = provider, model
+		return nil
+	}
+}
+
+// WithTemperature sets the sampling temperature.
+func WithTemperature(temperature float64) Option {
+	return func(o *options) error {
+		o.config.Model.Temperature = temperature
+		return nil
+	}
+}
+
+// WithTimeout bounds each attempt of a model call. Callers bound the whole
+// call, retries included, with their context.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		o.config.Client.Timeout = timeout
+		return nil
+	}
+}
+
+// WithMaxAttempts sets the attempts per call for rate-limited or transient
+// failures; 1 disables retries.
+func WithMaxAttempts(n int) Option {
+	return func(o *options) error {
+		o.config.Client.Retry.MaxAttempts = n
+		return nil
+	}
+}
+
+// WithFallbacks adds backends, written as provider:model, tried in order
+// when the primary one is unavailable.
+func WithFallbacks(backends ...string) Option {
+	return func(o *options) error {
+		for _, s := range backends {
+			b, err := llmConfig.ParseBackend(s)
+			if err != nil {
+				return err
+			}
+			o.config.Fallbacks = append(o.config.Fallbacks, b)
+		}
+		return nil
+	}
+}
+
+// WithOllamaURL sets the Ollama server; it defaults to DefaultOllamaURL.
+func WithOllamaURL(url string) Option {
+	return func(o *options) error {
+		o.ollamaURL = url
+		return nil
+	}
+}
+
+// WithOpenAIKey sets the API key, required when a backend is OpenAI.
+func WithOpenAIKey(key string) Option {
+	return func(o *options) error {
+		o.openAIKey = key
+		return nil
+	}
+}
+
+// WithOpenAIBaseURL points OpenAI backends at a compatible API.
+func WithOpenAIBaseURL(url string) Option {
+	return func(o *options) error {
+		o.openAIBaseURL = url
+		return nil
+	}
+}
+
+// WithCache serves repeated Chat calls from an on-disk response cache in
+// dir. Streamed calls replay cached responses as a single chunk.
+func WithCache(dir string) Option {
+	return func(o *options) error {
+		o.cacheDir = dir
+		return nil
+	}
+}
+
+// WithReplay answers model calls from HTTP traffic recorded into dir by
+// `ai-explorer llm --record`, without a network.
+func WithReplay(dir string) Option {
+	return func(o *options) error {
+		o.config.Client.ReplayDir = dir
+		return nil
+	}
+}
+
+// WithResources sets the resources tree topics are rendered from; it
+// defaults to paths.ResourcesDir, relative to the working directory.
+func WithResources(dir string) Option {
+	return func(o *options) error {
+		o.resources = dir
+		return nil
+	}
+}
+
+// WithStrict fails rendering on variables the config lacks, config keys
+// the template never reads, and unresolved ${VAR} references.
+func WithStrict() Option {
+	return func(o *options) error {
+		o.strict = true
+		return nil
+	}
+}
+
+// WithEnv resolves ${VAR} references in configs through lookup, e.g.
+// os.LookupEnv. Without it every reference is unset.
+func WithEnv(lookup func(key string) (string, bool)) Option {
+	return func(o *options) error {
+		o.lookupEnv = lookup
+		return nil
+	}
+}
+
+// WithLogger receives rendering warnings, which are dropped otherwise.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) error {
+		o.logger = l
+		return nil
+	}
+}
+
+// WithEmbeddingModel sets the model Embed and Similarity use with the
+// primary provider; it defaults to nomic-embed-text on Ollama and
+// text-embedding-3-small on OpenAI.
+func WithEmbeddingModel(model string) Option {
+	return func(o *options) error {
+		o.embedModel = model
+		return nil
+	}
+}
+
+// WithEmbedder replaces the provider's embedding model.
+func WithEmbedder(e wrapper.Embedder) Option {
+	return func(o *options) error {
+		o.embedder = e
+		return nil
+	}
+}
+
+// WithLLM replaces the configured backends with model, e.g. a stub in
+// tests. Stream needs a model that streams through the handler on its
+// context, as llm.Client does.
+func WithLLM(model llm.LLM) Option {
+	return func(o *options) error {
+		o.chat = model
+		return nil
+	}
+}
@@ -0,0 +1,230 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+)
+
+// DefaultCacheDir is where cached responses are stored unless overridden.
+const DefaultCacheDir = ".ai-explorer/cache"
+
+// CacheKey describes everything besides the prompt that affects a response.
+type CacheKey struct {
+	Provider    string            `json:"provider"`
+	Model       string            `json:"model"`
+	Temperature float64           `json:"temperature"`
+	Options     map[string]string `json:"options,omitempty"`
+}
+
+// CacheKeyFor derives the cache key from a client configuration. The
+// fallbacks are part of it, as any of them may have answered.
+func CacheKeyFor(cfg llmConfig.Config) CacheKey {
+	key := CacheKey{
+		Provider:    cfg.Provider,
+		Model:       cfg.Model.Name,
+		Temperature: cfg.Model.Temperature,
+	}
+	if backends := cfg.Backends(); len(backends) > 1 {
+		names := make([]string, len(backends)-1)
+		for i, b := range backends[1:] {
+			names[i] = b.String()
+		}
+		key = key.WithOption("fallbacks", strings.Join(names, ","))
+	}
+	return key
+}
+
+// WithOption returns a copy of k with the option set, such as the server
+// that answers. Empty values are left out.
+func (k CacheKey) WithOption(name, value string) CacheKey {
+	if value == "" {
+		return k
+	}
+	options := make(map[string]string, len(k.Options)+1)
+	for n, v := range k.Options {
+		options[n] = v
+	}
+	options[name] = value
+	k.Options = options
+	return k
+}
+
+// CacheEntry is a single cached response as stored on disk.
+type CacheEntry struct {
+	Key       CacheKey  `json:"key"`
+	Request   string    `json:"request"`
+	Response  string    `json:"response"`
+	Backend   string    `json:"backend,omitempty"` // provider:model that answered
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CacheStats summarises the contents of a cache directory.
+type CacheStats struct {
+	Entries int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// Cache is a content-addressed, on-disk response store. Entries live in
+// Dir/<first two hex chars>/<sha256>.json.
+type Cache struct {
+	Dir string
+	Now func() time.Time
+}
+
+// NewCache returns a cache rooted at dir, falling back to DefaultCacheDir.
+func NewCache(dir string) *Cache {
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+	return &Cache{Dir: dir, Now: time.Now}
+}
+
+// Hash returns the content address for a key and request payload.
+func (c *Cache) Hash(key CacheKey, request string) string {
+	data, _ := json.Marshal(struct {
+		Key     CacheKey `json:"key"`
+		Request string   `json:"request"`
+	}{key, request})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) path(hash string) string {
+	return filepath.Join(c.Dir, hash[:2], hash+".json")
+}
+
+// Get returns the cached response for hash, if present and readable.
+func (c *Cache) Get(hash string) (string, bool) {
+	entry, ok := c.Lookup(hash)
+	return entry.Response, ok
+}
+
+// Lookup returns the full cache entry for hash, if present and readable.
+func (c *Cache) Lookup(hash string) (CacheEntry, bool) {
+	data, err := os.ReadFile(c.path(hash))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Put stores a response under hash.
+func (c *Cache) Put(hash string, key CacheKey, request, response string) error {
+	return c.Store(hash, CacheEntry{Key: key, Request: request, Response: response})
+}
+
+// Store writes entry under hash, stamping CreatedAt if unset.
+func (c *Cache) Store(hash string, entry CacheEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = c.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	path := c.path(hash)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	// Write through a temp file so concurrent readers never see partial
+	// entries, and concurrent writers never share one.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Stats walks the cache and reports its size.
+func (c *Cache) Stats() (CacheStats, error) {
+	var stats CacheStats
+	err := c.walk(func(path string, info fs.FileInfo) error {
+		stats.Entries++
+		stats.Bytes += info.Size()
+		mod := info.ModTime()
+		if stats.Oldest.IsZero() || mod.Before(stats.Oldest) {
+			stats.Oldest = mod
+		}
+		if mod.After(stats.Newest) {
+			stats.Newest = mod
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// Prune removes entries last written more than maxAge ago.
+func (c *Cache) Prune(maxAge time.Duration) (int, error) {
+	cutoff := c.Now().Add(-maxAge)
+	removed := 0
+	err := c.walk(func(path string, info fs.FileInfo) error {
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// Clear removes every entry.
+func (c *Cache) Clear() (int, error) {
+	stats, err := c.Stats()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(c.Dir); err != nil {
+		return 0, fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return stats.Entries, nil
+}
+
+// walk visits every cache entry file.
+func (c *Cache) walk(visit func(path string, info fs.FileInfo) error) error {
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return visit(path, info)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+)
+
+func TestCache_HashCoversKeyAndRequest(t *testing.T) {
+	c := NewCache(t.TempDir())
+	base := CacheKey{Provider: "ollama", Model: "phi4", Temperature: 0.8}
+
+	h := c.Hash(base, "prompt")
+	assert.Len(t, h, 64)
+	assert.Equal(t, h, c.Hash(base, "prompt"))
+	assert.NotEqual(t, h, c.Hash(base, "prompt "))
+
+	for _, k := range []CacheKey{
+		{Provider: "openai", Model: "phi4", Temperature: 0.8},
+		{Provider: "ollama", Model: "llama3", Temperature: 0.8},
+		{Provider: "ollama", Model: "phi4", Temperature: 0.2},
+		{Provider: "ollama", Model: "phi4", Temperature: 0.8, Options: map[string]string{"max_tokens": "64"}},
+	} {
+		assert.NotEqual(t, h, c.Hash(k, "prompt"), "%+v", k)
+	}
+}
+
+func TestCacheKeyFor(t *testing.T) {
+	key := CacheKeyFor(llmConfig.Config{Provider: "ollama", Model: llmConfig.ModelConfig{Name: "phi4", Temperature: 0.5}})
+	assert.Equal(t, CacheKey{Provider: "ollama", Model: "phi4", Temperature: 0.5}, key)
+
+	key = CacheKeyFor(llmConfig.Config{
+		Provider:  "ollama",
+		Model:     llmConfig.ModelConfig{Name: "phi4"},
+		Fallbacks: []llmConfig.Backend{{Model: "llama3"}, {Provider: "openai", Model: "gpt-4o-mini"}},
+	})
+	assert.Equal(t, map[string]string{"fallbacks": "ollama:llama3,openai:gpt-4o-mini"}, key.Options)
+
+	withHost := key.WithOption("ollama_host", "http://gpu-box:11434")
+	assert.Equal(t, "http://gpu-box:11434", withHost.Options["ollama_host"])
+	assert.NotContains(t, key.Options, "ollama_host", "the original key is left alone")
+	assert.Equal(t, key, key.WithOption("ollama_host", ""))
+}
+
+func TestCache_PutGetStatsPruneClear(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	c := NewCache(dir)
+	key := CacheKey{Provider: "ollama", Model: "phi4"}
+
+	_, ok := c.Get(c.Hash(key, "a"))
+	assert.False(t, ok)
+
+	stats, err := c.Stats()
+	assert.NoError(t, err)
+	assert.Zero(t, stats.Entries)
+
+	for _, p := range []string{"a", "b"} {
+		assert.NoError(t, c.Put(c.Hash(key, p), key, p, "answer-"+p))
+	}
+	resp, ok := c.Get(c.Hash(key, "a"))
+	assert.True(t, ok)
+	assert.Equal(t, "answer-a", resp)
+
+	stats, err = c.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Entries)
+	assert.Positive(t, stats.Bytes)
+
+	old := time.Now().Add(-48 * time.Hour)
+	assert.NoError(t, os.Chtimes(c.path(c.Hash(key, "a")), old, old))
+	removed, err := c.Prune(24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	removed, err = c.Clear()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/llm/wrapper"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// ChatModel is the full client surface: single prompts, message histories
+// and a configurable stream handler. Client and Cached both implement it.
+type ChatModel interface {
+	LLM
+	Conversational
+	SetStreamHandler(h StreamHandler)
+}
+
+// Cached wraps an LLM so identical requests are answered from a Cache.
+// Cache hits are replayed through the stream handler, so streaming callers
+// see the same output they would from a live call.
+type Cached struct {
+	inner LLM
+	cache *Cache
+	key   CacheKey
+
+	mu       sync.Mutex
+	stream   StreamHandler
+	answered llmConfig.Backend
+	usage    tokens.Usage
+	reported bool
+}
+
+// Ensure Cached satisfies ChatModel, BackendReporter and UsageReporter.
+var (
+	_ ChatModel       = (*Cached)(nil)
+	_ BackendReporter = (*Cached)(nil)
+	_ UsageReporter   = (*Cached)(nil)
+)
+
+// NewCached wraps inner with cache. The key must describe the provider,
+// model and options inner was built with. If inner is a Client, its
+// stream handler is reused for replaying hits.
+func NewCached(inner LLM, cache *Cache, key CacheKey) *Cached {
+	c := &Cached{inner: inner, cache: cache, key: key}
+	if s, ok := inner.(interface{ StreamHandler() StreamHandler }); ok {
+		c.stream = s.StreamHandler()
+	}
+	return c
+}
+
+// SetStreamHandler sets the replay handler and forwards it to the inner LLM.
+func (c *Cached) SetStreamHandler(h StreamHandler) {
+	c.mu.Lock()
+	c.stream = h
+	c.mu.Unlock()
+	if s, ok := c.inner.(interface{ SetStreamHandler(StreamHandler) }); ok {
+		s.SetStreamHandler(h)
+	}
+}
+
+// LastBackend reports which backend produced the most recent response,
+// including responses served from the cache.
+func (c *Cached) LastBackend() llmConfig.Backend {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.answered
+}
+
+// LastUsage reports the provider usage of the most recent response. Cache
+// hits consume no tokens and report none.
+func (c *Cached) LastUsage() (tokens.Usage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usage, c.reported
+}
+
+// Chat returns a cached response or calls the inner LLM and stores the result.
+func (c *Cached) Chat(ctx context.Context, prompt string) (string, error) {
+	return c.lookup(ctx, prompt, func() (string, error) {
+		return c.inner.Chat(ctx, prompt)
+	})
+}
+
+// ChatMessages caches message-based calls when the inner LLM supports them.
+func (c *Cached) ChatMessages(ctx context.Context, messages []wrapper.MessageContent) (string, error) {
+	conv, ok := c.inner.(Conversational)
+	if !ok {
+		return "", fmt.Errorf("chat failed: %T does not support message histories", c.inner)
+	}
+	return c.lookup(ctx, wrapper.EncodeMessages(messages), func() (string, error) {
+		return conv.ChatMessages(ctx, messages)
+	})
+}
+
+// lookup serves request from the cache or via call.
+func (c *Cached) lookup(ctx context.Context, request string, call func() (string, error)) (string, error) {
+	hash := c.cache.Hash(c.key, request)
+	if entry, ok := c.cache.Lookup(hash); ok {
+		c.mu.Lock()
+		stream := c.stream
+		c.mu.Unlock()
+		if stream = streamHandlerFrom(ctx, stream); stream != nil {
+			if err := stream(ctx, []byte(entry.Response)); err != nil {
+				return "", err
+			}
+		}
+		answered := c.defaultBackend()
+		if b, err := llmConfig.ParseBackend(entry.Backend); err == nil && entry.Backend != "" {
+			answered = b
+		}
+		c.record(answered, tokens.Usage{}, false)
+		return entry.Response, nil
+	}
+
+	resp, err := call()
+	if err != nil {
+		return "", err
+	}
+	answered := c.defaultBackend()
+	if r, ok := c.inner.(BackendReporter); ok {
+		answered = r.LastBackend()
+	}
+	var usage tokens.Usage
+	var reported bool
+	if r, ok := c.inner.(UsageReporter); ok {
+		usage, reported = r.LastUsage()
+	}
+	c.record(answered, usage, reported)
+	// A failed write only costs a future cache miss.
+	_ = c.cache.Store(hash, CacheEntry{Key: c.key, Request: request, Response: resp, Backend: answered.String()})
+	return resp, nil
+}
+
+// record notes the backend and usage of the latest response.
+func (c *Cached) record(answered llmConfig.Backend, usage tokens.Usage, reported bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.answered, c.usage, c.reported = answered, usage, reported
+}
+
+// defaultBackend is the backend described by the cache key.
+func (c *Cached) defaultBackend() llmConfig.Backend {
+	return llmConfig.Backend{Provider: c.key.Provider, Model: c.key.Model}
+}
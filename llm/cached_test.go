@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// countingLLM records how often it is called.
+type countingLLM struct {
+	calls  int
+	reply  string
+	err    error
+	stream StreamHandler
+}
+
+func (c *countingLLM) Chat(ctx context.Context, prompt string) (string, error) {
+	c.calls++
+	if c.err != nil {
+		return "", c.err
+	}
+	if c.stream != nil {
+		_ = c.stream(ctx, []byte(c.reply))
+	}
+	return c.reply, nil
+}
+
+func (c *countingLLM) ChatMessages(ctx context.Context, _ []wrapper.MessageContent) (string, error) {
+	return c.Chat(ctx, "")
+}
+
+func (c *countingLLM) SetStreamHandler(h StreamHandler) { c.stream = h }
+
+func TestCached_ChatHitsSkipInner(t *testing.T) {
+	inner := &countingLLM{reply: "pong"}
+	cached := NewCached(inner, NewCache(t.TempDir()), CacheKey{Model: "phi4"})
+
+	for i := 0; i < 3; i++ {
+		resp, err := cached.Chat(context.Background(), "ping")
+		assert.NoError(t, err)
+		assert.Equal(t, "pong", resp)
+	}
+	assert.Equal(t, 1, inner.calls)
+
+	_, _ = cached.Chat(context.Background(), "other")
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestCached_ReplaysHitsThroughStreamHandler(t *testing.T) {
+	inner := &countingLLM{reply: "streamed"}
+	cached := NewCached(inner, NewCache(t.TempDir()), CacheKey{})
+
+	var got string
+	cached.SetStreamHandler(func(_ context.Context, chunk []byte) error {
+		got += string(chunk)
+		return nil
+	})
+
+	_, _ = cached.Chat(context.Background(), "q")
+	_, _ = cached.Chat(context.Background(), "q")
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, "streamedstreamed", got, "live and cached output both reach the handler")
+}
+
+func TestCached_ReusesClientStreamHandler(t *testing.T) {
+	var got string
+	client := &Client{}
+	client.SetStreamHandler(func(_ context.Context, chunk []byte) error {
+		got += string(chunk)
+		return nil
+	})
+	cache := NewCache(t.TempDir())
+	key := CacheKey{Model: "m"}
+	assert.NoError(t, cache.Put(cache.Hash(key, "q"), key, "q", "from cache"))
+
+	resp, err := NewCached(client, cache, key).Chat(context.Background(), "q")
+	assert.NoError(t, err)
+	assert.Equal(t, "from cache", resp)
+	assert.Equal(t, "from cache", got)
+}
+
+func TestCached_ErrorsAreNotCached(t *testing.T) {
+	inner := &countingLLM{err: errors.New("down")}
+	cached := NewCached(inner, NewCache(t.TempDir()), CacheKey{})
+
+	_, err := cached.Chat(context.Background(), "q")
+	assert.Error(t, err)
+	_, err = cached.Chat(context.Background(), "q")
+	assert.Error(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestCached_ChatMessages(t *testing.T) {
+	inner := &countingLLM{reply: "hi"}
+	cached := NewCached(inner, NewCache(t.TempDir()), CacheKey{})
+	msgs := []wrapper.MessageContent{wrapper.TextMessage(wrapper.RoleUser, "hello")}
+
+	_, _ = cached.ChatMessages(context.Background(), msgs)
+	resp, err := cached.ChatMessages(context.Background(), msgs)
+	assert.NoError(t, err)
+	assert.Equal(t, "hi", resp)
+	assert.Equal(t, 1, inner.calls)
+
+	other := append(msgs, wrapper.TextMessage(wrapper.RoleAssistant, "hi"), wrapper.TextMessage(wrapper.RoleUser, "again"))
+	_, _ = cached.ChatMessages(context.Background(), other)
+	assert.Equal(t, 2, inner.calls)
+}
+
+type promptOnly struct{}
+
+func (promptOnly) Chat(context.Context, string) (string, error) { return "", nil }
+
+func TestCached_ChatMessagesUnsupported(t *testing.T) {
+	cached := NewCached(promptOnly{}, NewCache(t.TempDir()), CacheKey{})
+	_, err := cached.ChatMessages(context.Background(), nil)
+	assert.ErrorContains(t, err, "does not support message histories")
+}
+
+func TestCached_ReplaysHitsThroughContextHandler(t *testing.T) {
+	inner := &countingLLM{reply: "streamed"}
+	cached := NewCached(inner, NewCache(t.TempDir()), CacheKey{})
+	_, err := cached.Chat(context.Background(), "ping")
+	assert.NoError(t, err)
+
+	var got string
+	ctx := WithStreamHandler(context.Background(), func(_ context.Context, chunk []byte) error {
+		got += string(chunk)
+		return nil
+	})
+	resp, err := cached.Chat(ctx, "ping")
+	assert.NoError(t, err)
+	assert.Equal(t, "streamed", resp)
+	assert.Equal(t, "streamed", got)
+	assert.Equal(t, 1, inner.calls)
+}
@@ -0,0 +1,278 @@
+// Package cassette records HTTP exchanges with LLM providers to disk and
+// replays them, so real provider code paths can run without a network.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoInteraction is returned in replay mode when no recorded exchange
+// matches a request.
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// sensitiveHeaders are never written to disk.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Api-Key",
+	"X-Api-Key",
+	"Openai-Organization",
+	"Openai-Project",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// Interaction is a single recorded request/response pair.
+type Interaction struct {
+	Request    Request   `json:"request"`
+	Response   Response  `json:"response"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Request is the recorded part of an HTTP request.
+type Request struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is the recorded part of an HTTP response.
+type Response struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Key identifies a request by method, path and normalized body.
+func Key(method, path string, body []byte) string {
+	sum := sha256.Sum256([]byte(method + " " + path + "\n" + NormalizeBody(body)))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeBody makes equivalent request bodies compare equal: JSON is
+// re-encoded compactly with sorted keys, anything else is trimmed.
+func NormalizeBody(body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err == nil {
+		if out, err := json.Marshal(v); err == nil {
+			return string(out)
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// Recorder is an http.RoundTripper that forwards requests to Base and
+// writes each exchange into Dir.
+type Recorder struct {
+	Dir  string
+	Base http.RoundTripper
+	Now  func() time.Time
+}
+
+// NewRecorder records exchanges made through base into dir.
+func NewRecorder(dir string, base http.RoundTripper) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Recorder{Dir: dir, Base: base, Now: time.Now}
+}
+
+// RoundTrip sends the request and records it. The response body is teed
+// to disk as the caller reads it, so streaming still works while recording.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			Path:    req.URL.Path,
+			Headers: scrub(req.Header),
+			Body:    string(reqBody),
+		},
+		Response: Response{Status: resp.StatusCode, Headers: scrub(resp.Header)},
+	}
+	resp.Body = &teeBody{ReadCloser: resp.Body, done: func(body []byte) error {
+		in.Response.Body = string(body)
+		in.RecordedAt = r.Now()
+		return r.save(in)
+	}}
+	return resp, nil
+}
+
+// save writes an interaction as <method>-<path>-<key prefix>.json.
+func (r *Recorder) save(in Interaction) error {
+	if err := os.MkdirAll(r.Dir, os.ModePerm); err != nil {
+		return fmt.Errorf("cassette: failed to create %s: %w", r.Dir, err)
+	}
+	data, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette: failed to encode interaction: %w", err)
+	}
+	key := Key(in.Request.Method, in.Request.Path, []byte(in.Request.Body))
+	name := fmt.Sprintf("%s-%s-%s.json", strings.ToLower(in.Request.Method), slug(in.Request.Path), key[:12])
+	return os.WriteFile(filepath.Join(r.Dir, name), append(data, '\n'), 0644)
+}
+
+// Replayer is an http.RoundTripper that answers from recorded interactions
+// and never touches the network.
+type Replayer struct {
+	Dir string
+
+	once         sync.Once
+	interactions map[string]Interaction
+	loadErr      error
+}
+
+// NewReplayer replays the interactions stored in dir.
+func NewReplayer(dir string) *Replayer {
+	return &Replayer{Dir: dir}
+}
+
+// RoundTrip returns the recorded response matching req.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.once.Do(r.load)
+	if r.loadErr != nil {
+		return nil, r.loadErr
+	}
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	in, ok := r.interactions[Key(req.Method, req.URL.Path, body)]
+	if !ok {
+		return nil, fmt.Errorf("cassette: %w for %s %s in %s", ErrNoInteraction, req.Method, req.URL.Path, r.Dir)
+	}
+
+	header := in.Response.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+		StatusCode:    in.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+		ContentLength: int64(len(in.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// load indexes every *.json interaction in Dir by request key.
+func (r *Replayer) load() {
+	r.interactions = map[string]Interaction{}
+	r.loadErr = filepath.WalkDir(r.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var in Interaction
+		if err := json.Unmarshal(data, &in); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		r.interactions[Key(in.Request.Method, in.Request.Path, []byte(in.Request.Body))] = in
+		return nil
+	})
+	if r.loadErr != nil {
+		r.loadErr = fmt.Errorf("cassette: failed to load %s: %w", r.Dir, r.loadErr)
+	}
+}
+
+// readBody reads and restores the request body.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// scrub copies h without sensitive headers.
+func scrub(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range sensitiveHeaders {
+		out.Del(name)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(path string) string {
+	s := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(path), "-"), "-")
+	if s == "" {
+		return "root"
+	}
+	return s
+}
+
+// teeBody buffers everything read from the body and hands it to done once,
+// at EOF or Close, whichever comes first.
+type teeBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	done func([]byte) error
+	once sync.Once
+	err  error
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	t.buf.Write(p[:n])
+	if err == io.EOF {
+		t.finish()
+		if t.err != nil {
+			return n, t.err
+		}
+	}
+	return n, err
+}
+
+func (t *teeBody) Close() error {
+	// Record the whole response even if the caller stopped reading early.
+	_, _ = io.Copy(&t.buf, t.ReadCloser)
+	t.finish()
+	if err := t.ReadCloser.Close(); err != nil {
+		return err
+	}
+	return t.err
+}
+
+func (t *teeBody) finish() {
+	t.once.Do(func() { t.err = t.done(t.buf.Bytes()) })
+}
@@ -0,0 +1,96 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func post(t *testing.T, rt http.RoundTripper, url, body string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	req.Header.Set("Content-Type", "application/json")
+	return rt.RoundTrip(req)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"model":"phi4","stream":true}`, string(body), "the server still sees the request body")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, "{\"done\":false}\n{\"done\":true}\n")
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	resp, err := post(t, NewRecorder(dir, nil), srv.URL+"/api/chat", `{"model":"phi4","stream":true}`)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "{\"done\":false}\n{\"done\":true}\n", string(body))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "post-api-chat-*.json"))
+	require.Len(t, files, 1)
+	data, _ := os.ReadFile(files[0])
+	assert.NotContains(t, string(data), "sk-secret")
+	assert.NotContains(t, string(data), "session=abc")
+
+	// Key order and whitespace differences still match; the host does not matter.
+	replayed, err := post(t, NewReplayer(dir), "http://elsewhere:1/api/chat", "{ \"stream\": true,\n \"model\": \"phi4\" }")
+	require.NoError(t, err)
+	body, _ = io.ReadAll(replayed.Body)
+	assert.Equal(t, http.StatusOK, replayed.StatusCode)
+	assert.Equal(t, "application/x-ndjson", replayed.Header.Get("Content-Type"))
+	assert.Equal(t, "{\"done\":false}\n{\"done\":true}\n", string(body))
+	assert.Equal(t, 1, hits)
+}
+
+func TestRecorder_RecordsBodyClosedEarly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "full body")
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	resp, err := post(t, NewRecorder(dir, nil), srv.URL+"/", "x")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	resp, err = post(t, NewReplayer(dir), srv.URL+"/", "x")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "full body", string(body))
+}
+
+func TestReplayer_Miss(t *testing.T) {
+	_, err := post(t, NewReplayer(t.TempDir()), "http://localhost/api/chat", `{"model":"phi4"}`)
+	assert.ErrorIs(t, err, ErrNoInteraction)
+	assert.ErrorContains(t, err, "POST /api/chat")
+}
+
+func TestReplayer_BadCassette(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644))
+
+	_, err := post(t, NewReplayer(dir), "http://localhost/", "")
+	assert.ErrorContains(t, err, "failed to load")
+}
+
+func TestNormalizeBody(t *testing.T) {
+	assert.Equal(t, `{"a":1,"b":[true,null]}`, NormalizeBody([]byte(" {\"b\": [true, null],\n\"a\": 1} ")))
+	assert.Equal(t, "plain text", NormalizeBody([]byte("  plain text\n")))
+	assert.Equal(t, Key("POST", "/x", []byte(`{"a":1, "b":2}`)), Key("POST", "/x", []byte(`{"b":2,"a":1}`)))
+	assert.NotEqual(t, Key("POST", "/x", nil), Key("GET", "/x", nil))
+}
@@ -2,10 +2,14 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	llmConfig "raja.aiml/ai.explorer/llm/config"
 	"raja.aiml/ai.explorer/llm/wrapper"
+	"raja.aiml/ai.explorer/tokens"
 )
 
 // LLM defines the interface for any chat-capable client.
@@ -13,46 +17,186 @@ type LLM interface {
 	Chat(ctx context.Context, prompt string) (string, error)
 }
 
+// Conversational defines the interface for clients that accept a full,
+// role-aware message history instead of a single prompt.
+type Conversational interface {
+	ChatMessages(ctx context.Context, messages []wrapper.MessageContent) (string, error)
+}
+
+// BackendReporter is implemented by clients that can say which
+// provider/model produced their most recent response.
+type BackendReporter interface {
+	LastBackend() llmConfig.Backend
+}
+
 // Client wraps an LLM model and config.
 type Client struct {
+	model     wrapper.Model
+	config    llmConfig.Config
+	callGen   func(ctx context.Context, model wrapper.Model, prompt string, opts ...wrapper.CallOption) (string, error)
+	stream    StreamHandler
+	sleep     func(ctx context.Context, d time.Duration) error // waits between retries
+	random    func() float64                                   // jitter source
+	fallbacks []fallback                                       // tried in order after the primary model
+
+	mu            sync.Mutex
+	answered      llmConfig.Backend
+	usage         tokens.Usage
+	usageReported bool
+}
+
+// fallback is a secondary backend. A backend that failed to initialise is
+// kept with its error so it is reported, not silently dropped, when reached.
+type fallback struct {
+	backend llmConfig.Backend
 	model   wrapper.Model
-	config  llmConfig.Config
-	callGen func(ctx context.Context, model wrapper.Model, prompt string, opts ...wrapper.CallOption) (string, error)
+	err     error
 }
 
+// Ensure Client satisfies ChatModel, BackendReporter and UsageReporter.
+var (
+	_ ChatModel       = (*Client)(nil)
+	_ BackendReporter = (*Client)(nil)
+	_ UsageReporter   = (*Client)(nil)
+)
+
 // NewClient supports injecting dependencies for testability.
 func NewClient(cfg llmConfig.Config, provider wrapper.Provider, generator func(context.Context, wrapper.Model, string, ...wrapper.CallOption) (string, error)) (*Client, error) {
 	model, err := provider.Init(cfg.Provider, cfg.Model.Name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize LLM provider: %w", err)
 	}
-	return &Client{
+	client := &Client{
 		model:   model,
 		config:  cfg,
 		callGen: generator,
-	}, nil
+	}
+	for _, b := range cfg.Backends()[1:] {
+		fb := fallback{backend: b}
+		fb.model, fb.err = provider.Init(b.Provider, b.Model)
+		client.fallbacks = append(client.fallbacks, fb)
+	}
+	return client, nil
 }
 
 // NewDefaultClient returns a client with default dependencies.
 func NewDefaultClient(cfg llmConfig.Config) (*Client, error) {
-	return NewClient(cfg, &wrapper.LangchaingoProvider{}, wrapper.GenerateFromSinglePrompt)
+	return NewClient(cfg, NewProvider(cfg), wrapper.GenerateFromSinglePrompt)
+}
+
+// NewProvider returns the provider NewDefaultClient uses, whose requests
+// retry on Retry-After and are recorded or replayed as cfg asks. Servers
+// and tokens come from the environment unless its fields are set.
+func NewProvider(cfg llmConfig.Config) *wrapper.LangchaingoProvider {
+	return &wrapper.LangchaingoProvider{HTTPClient: newHTTPClient(cfg.Client)}
+}
+
+// SetStreamHandler routes streamed chunks to h instead of stdout.
+// Streaming is enabled whenever a handler is set, regardless of VerboseLogging.
+func (c *Client) SetStreamHandler(h StreamHandler) {
+	c.stream = h
+}
+
+// StreamHandler returns the handler used for streamed chunks, if any.
+func (c *Client) StreamHandler() StreamHandler {
+	switch {
+	case c.stream != nil:
+		return c.stream
+	case c.config.Client.VerboseLogging:
+		return defaultStreamHandler
+	}
+	return nil
+}
+
+// LastBackend returns the provider/model that produced the most recent
+// successful response.
+func (c *Client) LastBackend() llmConfig.Backend {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.answered
+}
+
+// LastUsage returns the token usage the provider reported for the most
+// recent successful response.
+func (c *Client) LastUsage() (tokens.Usage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usage, c.usageReported
 }
 
-// Chat generates a response for the given prompt.
+// Chat generates a response for the given prompt, retrying retryable
+// failures according to the configured policy and then moving down the
+// fallback chain. Failures are returned as *Error.
 func (c *Client) Chat(ctx context.Context, prompt string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.config.Client.Timeout)
-	defer cancel()
+	return c.generate(ctx, func(ctx context.Context, model wrapper.Model, opts []wrapper.CallOption) (string, error) {
+		return c.callGen(ctx, model, prompt, opts...)
+	})
+}
+
+// ChatMessages generates the next assistant turn for a message history.
+func (c *Client) ChatMessages(ctx context.Context, messages []wrapper.MessageContent) (string, error) {
+	return c.generate(ctx, func(ctx context.Context, model wrapper.Model, opts []wrapper.CallOption) (string, error) {
+		resp, err := model.GenerateContent(ctx, messages, opts...)
+		if err != nil {
+			return "", err
+		}
+		if resp == nil || len(resp.Choices) == 0 {
+			return "", errors.New("empty response from model")
+		}
+		return resp.Choices[0].Content, nil
+	})
+}
+
+// generate tries each backend in turn. It moves on only when a backend is
+// unavailable or still failing after its retries, and never once output
+// has been streamed or the caller has given up. Every attempt gets its own
+// timeout, so a backend that hangs gives way to the next one as long as
+// ctx itself has not expired.
+func (c *Client) generate(ctx context.Context, call generateFunc) (string, error) {
+	primary := fallback{backend: llmConfig.Backend{Provider: c.config.Provider, Model: c.config.Model.Name}, model: c.model}
+	chain := append([]fallback{primary}, c.fallbacks...)
 
+	var errs []error
+	for _, b := range chain {
+		if b.err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to initialize LLM provider: %w", b.backend, b.err))
+			continue
+		}
+		resp, streamed, err := c.withRetry(ctx, b.model, call)
+		if err == nil {
+			c.mu.Lock()
+			c.answered = b.backend
+			c.mu.Unlock()
+			return resp, nil
+		}
+		if len(chain) == 1 {
+			return "", err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", b.backend, err))
+		if streamed || ctx.Err() != nil || !canFallback(err) {
+			break
+		}
+	}
+	return "", fmt.Errorf("all backends failed: %w", errors.Join(errs...))
+}
+
+// canFallback reports whether another backend might succeed where this
+// one failed.
+func canFallback(err error) bool {
+	return Retryable(err) || errors.Is(err, ErrModelNotFound)
+}
+
+// callOptions builds the per-call options shared by Chat and ChatMessages.
+// onChunk is invoked for every streamed chunk.
+func (c *Client) callOptions(ctx context.Context, onChunk func()) []wrapper.CallOption {
 	opts := []wrapper.CallOption{
 		wrapper.WithTemperature(c.config.Model.Temperature),
 	}
-	if c.config.Client.VerboseLogging {
-		opts = append(opts, wrapper.WithStreamingFunc(defaultStreamHandler))
-	}
-
-	response, err := c.callGen(ctx, c.model, prompt, opts...)
-	if err != nil {
-		return "", fmt.Errorf("chat failed: %w", err)
+	if h := streamHandlerFrom(ctx, c.StreamHandler()); h != nil {
+		opts = append(opts, wrapper.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			onChunk()
+			return h(ctx, chunk)
+		}))
 	}
-	return response, nil
+	return opts
 }
@@ -3,11 +3,13 @@ package llm
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/tmc/langchaingo/llms"
 	llmConfig "raja.aiml/ai.explorer/llm/config"
 	"raja.aiml/ai.explorer/llm/wrapper"
 )
@@ -133,3 +135,128 @@ func TestClient_Chat_Error(t *testing.T) {
 	assert.Empty(t, resp)
 	assert.Contains(t, err.Error(), "chat failed")
 }
+
+func TestClient_ChatMessages_Success(t *testing.T) {
+	messages := []wrapper.MessageContent{
+		wrapper.TextMessage(wrapper.RoleSystem, "be brief"),
+		wrapper.TextMessage(wrapper.RoleUser, "hi"),
+	}
+	mockModel := new(MockModel)
+	mockModel.On("GenerateContent", mock.Anything, messages, mock.Anything).
+		Return(&wrapper.ContentResponse{Choices: []*llms.ContentChoice{{Content: "hello"}}}, nil)
+
+	client := &Client{
+		model:  mockModel,
+		config: llmConfig.Config{Client: llmConfig.ClientConfig{Timeout: time.Second}},
+	}
+
+	resp, err := client.ChatMessages(context.Background(), messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", resp)
+	mockModel.AssertExpectations(t)
+}
+
+func TestClient_ChatMessages_Errors(t *testing.T) {
+	mockModel := new(MockModel)
+	mockModel.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return((*wrapper.ContentResponse)(nil), errors.New("boom")).Once()
+	mockModel.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return(&wrapper.ContentResponse{}, nil).Once()
+
+	client := &Client{
+		model:  mockModel,
+		config: llmConfig.Config{Client: llmConfig.ClientConfig{Timeout: time.Second}},
+	}
+
+	_, err := client.ChatMessages(context.Background(), nil)
+	assert.ErrorContains(t, err, "chat failed: boom")
+
+	_, err = client.ChatMessages(context.Background(), nil)
+	assert.ErrorContains(t, err, "empty response from model")
+}
+
+func TestClient_callOptions_UsesStreamHandler(t *testing.T) {
+	var got []byte
+	client := &Client{config: llmConfig.Config{Model: llmConfig.ModelConfig{Temperature: 0.3}}}
+
+	opts := llms.CallOptions{}
+	for _, opt := range client.callOptions(context.Background(), func() {}) {
+		opt(&opts)
+	}
+	assert.Nil(t, opts.StreamingFunc)
+
+	client.SetStreamHandler(func(_ context.Context, chunk []byte) error {
+		got = append(got, chunk...)
+		return nil
+	})
+	chunks := 0
+	opts = llms.CallOptions{}
+	for _, opt := range client.callOptions(context.Background(), func() { chunks++ }) {
+		opt(&opts)
+	}
+	assert.Equal(t, 0.3, opts.Temperature)
+	assert.NoError(t, opts.StreamingFunc(context.Background(), []byte("chunk")))
+	assert.Equal(t, "chunk", string(got))
+	assert.Equal(t, 1, chunks)
+
+	// A handler on the context takes precedence for that call only.
+	var perCall []byte
+	ctx := WithStreamHandler(context.Background(), func(_ context.Context, chunk []byte) error {
+		perCall = append(perCall, chunk...)
+		return nil
+	})
+	opts = llms.CallOptions{}
+	for _, opt := range client.callOptions(ctx, func() {}) {
+		opt(&opts)
+	}
+	assert.NoError(t, opts.StreamingFunc(ctx, []byte("mine")))
+	assert.Equal(t, "mine", string(perCall))
+	assert.Equal(t, "chunk", string(got))
+}
+
+// The cassettes under testdata were recorded with --record; replaying them
+// runs the real langchaingo provider clients without a network.
+func TestClient_ReplayedProviders(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENAI_BASE_URL", "")
+
+	for _, tc := range []struct{ provider, model string }{
+		{"ollama", "phi4"},
+		{"openai", "gpt-4o-mini"},
+	} {
+		t.Run(tc.provider, func(t *testing.T) {
+			cfg := llmConfig.Config{
+				Provider: tc.provider,
+				Model:    llmConfig.ModelConfig{Name: tc.model, Temperature: 0.2},
+				Client: llmConfig.ClientConfig{
+					Timeout:   5 * time.Second,
+					ReplayDir: "testdata/cassettes/" + tc.provider,
+				},
+			}
+			client, err := NewDefaultClient(cfg)
+			assert.NoError(t, err)
+
+			resp, err := client.Chat(context.Background(), "What is Git?")
+			assert.NoError(t, err)
+			assert.Equal(t, "Git is a distributed version control system.", resp)
+
+			usage, ok := client.LastUsage()
+			assert.True(t, ok, "providers report usage")
+			assert.Positive(t, usage.PromptTokens)
+			assert.Equal(t, 7, usage.CompletionTokens)
+
+			var chunks []string
+			client.SetStreamHandler(func(_ context.Context, chunk []byte) error {
+				chunks = append(chunks, string(chunk))
+				return nil
+			})
+			resp, err = client.Chat(context.Background(), "What is Git?")
+			assert.NoError(t, err)
+			assert.Equal(t, resp, strings.Join(chunks, ""))
+			assert.Greater(t, len(chunks), 1, "streamed responses arrive in chunks")
+
+			_, err = client.Chat(context.Background(), "Not recorded")
+			assert.ErrorContains(t, err, "no recorded interaction")
+		})
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -17,16 +18,120 @@ const (
 	DefaultVerboseLogging = true
 )
 
+// Default retry policy values
+const (
+	DefaultMaxAttempts       = 3
+	DefaultInitialBackoff    = 500 * time.Millisecond
+	DefaultMaxBackoff        = 30 * time.Second
+	DefaultBackoffMultiplier = 2.0
+	DefaultJitter            = 0.2
+)
+
 // ModelConfig holds configuration specific to the language model.
 type ModelConfig struct {
 	Name        string  // Name of the model
 	Temperature float64 // Temperature setting
 }
 
+// RetryPolicy controls how failed requests are retried. Only errors
+// classified as retryable (rate limits, timeouts, transient failures) are
+// retried; a MaxAttempts of 0 or 1 disables retries.
+type RetryPolicy struct {
+	MaxAttempts    int           `yaml:"max_attempts"`    // Total attempts, including the first
+	InitialBackoff time.Duration `yaml:"initial_backoff"` // Delay before the first retry
+	MaxBackoff     time.Duration `yaml:"max_backoff"`     // Upper bound for computed delays
+	Multiplier     float64       `yaml:"multiplier"`      // Growth factor between retries
+	Jitter         float64       `yaml:"jitter"`          // Random spread as a fraction of the delay (0-1)
+}
+
+// DefaultRetryPolicy returns the policy used by the CLI.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    DefaultMaxAttempts,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+		Multiplier:     DefaultBackoffMultiplier,
+		Jitter:         DefaultJitter,
+	}
+}
+
+// Backoff returns the delay before the given retry (1 for the first retry).
+// rnd must return a value in [0, 1) and is used to apply jitter.
+func (p RetryPolicy) Backoff(retry int, rnd func() float64) time.Duration {
+	initial, maxDelay, mult := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = DefaultInitialBackoff
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxBackoff
+	}
+	if mult < 1 {
+		mult = DefaultBackoffMultiplier
+	}
+
+	delay := float64(initial)
+	for i := 1; i < retry && delay < float64(maxDelay); i++ {
+		delay *= mult
+	}
+	delay = min(delay, float64(maxDelay))
+	if p.Jitter > 0 && rnd != nil {
+		jitter := min(p.Jitter, 1)
+		delay *= 1 + jitter*(2*rnd()-1)
+	}
+	return time.Duration(delay)
+}
+
 // ClientConfig holds runtime behavior configuration.
 type ClientConfig struct {
-	Timeout        time.Duration // Maximum request time
+	Timeout        time.Duration // Maximum time for a single attempt
 	VerboseLogging bool          // Enable verbose logs
+	Retry          RetryPolicy   // Retry behavior for failed requests
+	RecordDir      string        `yaml:"record_dir"` // Record provider HTTP traffic into this directory
+	ReplayDir      string        `yaml:"replay_dir"` // Answer provider HTTP requests from recordings in this directory
+}
+
+// Backend identifies a provider/model pair, written as "provider:model".
+// Everything after the first colon is the model, so Ollama tags such as
+// "ollama:llama3:8b" work as expected.
+type Backend struct {
+	Provider string
+	Model    string
+}
+
+// ParseBackend parses "provider:model". A bare model name leaves Provider
+// empty so it can inherit the primary provider.
+func ParseBackend(s string) (Backend, error) {
+	s = strings.TrimSpace(s)
+	provider, model, ok := strings.Cut(s, ":")
+	if !ok {
+		provider, model = "", s
+	}
+	if model == "" {
+		return Backend{}, fmt.Errorf("invalid backend %q: expected provider:model", s)
+	}
+	return Backend{Provider: provider, Model: model}, nil
+}
+
+func (b Backend) String() string {
+	if b.Provider == "" {
+		return b.Model
+	}
+	return b.Provider + ":" + b.Model
+}
+
+// UnmarshalYAML accepts either "provider:model" or a mapping with
+// provider and model keys.
+func (b *Backend) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		parsed, err := ParseBackend(node.Value)
+		if err != nil {
+			return err
+		}
+		*b = parsed
+		return nil
+	}
+	type plain Backend
+	return node.Decode((*plain)(b))
 }
 
 // Config aggregates model and client configurations.
@@ -34,6 +139,25 @@ type Config struct {
 	Provider string
 	Model    ModelConfig
 	Client   ClientConfig
+	// Fallbacks are tried in order when the primary backend is unavailable.
+	Fallbacks []Backend
+}
+
+// Backends returns the primary backend followed by the fallbacks, with
+// duplicates removed and missing providers inherited from the primary.
+func (c Config) Backends() []Backend {
+	backends := []Backend{{Provider: c.Provider, Model: c.Model.Name}}
+	seen := map[Backend]bool{backends[0]: true}
+	for _, b := range c.Fallbacks {
+		if b.Provider == "" {
+			b.Provider = c.Provider
+		}
+		if !seen[b] {
+			seen[b] = true
+			backends = append(backends, b)
+		}
+	}
+	return backends
 }
 
 // Dependency injection: package-level variable for file reading.
@@ -90,3 +90,115 @@ func TestConfigLoaderParseError(t *testing.T) {
 		t.Errorf("Expected error to contain 'failed to parse config YAML', got %v", err)
 	}
 }
+
+func TestConfigLoaderRetryPolicy(t *testing.T) {
+	origReadFile := readFile
+	defer func() { readFile = origReadFile }()
+
+	readFile = func(filename string) ([]byte, error) {
+		return []byte(`
+client:
+  retry:
+    max_attempts: 5
+    initial_backoff: 250ms
+    max_backoff: 10s
+    multiplier: 3
+    jitter: 0.1
+`), nil
+	}
+
+	cfg, err := ConfigLoader("dummy.yaml")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	want := RetryPolicy{MaxAttempts: 5, InitialBackoff: 250 * time.Millisecond, MaxBackoff: 10 * time.Second, Multiplier: 3, Jitter: 0.1}
+	if cfg.Client.Retry != want {
+		t.Errorf("Expected retry policy %+v, got %+v", want, cfg.Client.Retry)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	cases := map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	}
+	for retry, want := range cases {
+		if got := p.Backoff(retry, nil); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", retry, got, want)
+		}
+	}
+
+	p.Jitter = 0.5
+	if got := p.Backoff(1, func() float64 { return 0 }); got != 50*time.Millisecond {
+		t.Errorf("Expected lowest jittered delay 50ms, got %v", got)
+	}
+	if got := p.Backoff(1, func() float64 { return 0.5 }); got != 100*time.Millisecond {
+		t.Errorf("Expected centred jittered delay 100ms, got %v", got)
+	}
+}
+
+func TestRetryPolicyBackoffDefaults(t *testing.T) {
+	if got := (RetryPolicy{}).Backoff(1, nil); got != DefaultInitialBackoff {
+		t.Errorf("Expected default initial backoff, got %v", got)
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	cases := map[string]Backend{
+		"ollama:phi4":          {Provider: "ollama", Model: "phi4"},
+		"ollama:llama3:8b":     {Provider: "ollama", Model: "llama3:8b"},
+		" openai:gpt-4o-mini ": {Provider: "openai", Model: "gpt-4o-mini"},
+		"llama3":               {Model: "llama3"},
+	}
+	for in, want := range cases {
+		got, err := ParseBackend(in)
+		if err != nil || got != want {
+			t.Errorf("ParseBackend(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	if _, err := ParseBackend("ollama:"); err == nil {
+		t.Error("Expected error for missing model")
+	}
+}
+
+func TestConfigLoaderFallbacks(t *testing.T) {
+	origReadFile := readFile
+	defer func() { readFile = origReadFile }()
+
+	readFile = func(filename string) ([]byte, error) {
+		return []byte(`
+provider: ollama
+model:
+  name: phi4
+fallbacks:
+  - ollama:llama3
+  - provider: openai
+    model: gpt-4o-mini
+  - phi4
+`), nil
+	}
+
+	cfg, err := ConfigLoader("dummy.yaml")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	got := cfg.Backends()
+	want := []Backend{
+		{Provider: "ollama", Model: "phi4"},
+		{Provider: "ollama", Model: "llama3"},
+		{Provider: "openai", Model: "gpt-4o-mini"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected backends %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Backend %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
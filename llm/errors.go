@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Error kinds returned by Client calls. Use errors.Is to test for them.
+var (
+	ErrRateLimited    = errors.New("rate limited")
+	ErrTimeout        = errors.New("request timed out")
+	ErrModelNotFound  = errors.New("model not found")
+	ErrAuth           = errors.New("authentication failed")
+	ErrContextTooLong = errors.New("context too long")
+	ErrTransient      = errors.New("transient error")
+)
+
+// Error describes a failed LLM call. Kind is one of the Err* sentinels, or
+// nil when the failure could not be classified.
+type Error struct {
+	Kind       error
+	StatusCode int           // HTTP status reported by the provider, if known
+	RetryAfter time.Duration // Delay requested by the provider, if any
+	Attempts   int           // Number of attempts made
+	Err        error         // Underlying provider error
+}
+
+func (e *Error) Error() string {
+	msg := "chat failed: "
+	if e.Kind != nil {
+		msg += e.Kind.Error() + ": "
+	}
+	msg += e.Err.Error()
+	if e.Attempts > 1 {
+		msg += fmt.Sprintf(" (after %d attempts)", e.Attempts)
+	}
+	return msg
+}
+
+// Unwrap exposes the underlying provider error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is matches the error kind, so errors.Is(err, ErrRateLimited) works.
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// Retryable reports whether err is worth retrying.
+func Retryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrTransient)
+}
+
+var (
+	statusPattern     = regexp.MustCompile(`(?:status code:? |^)([1-5]\d\d)\b`)
+	retryAfterPattern = regexp.MustCompile(`(?i)(?:try again in|retry after) (\d+(?:\.\d+)?(?:ms|s|m)?)`)
+)
+
+// Classify wraps err in an *Error with its kind, status code and any
+// Retry-After hint found in the message. Errors that are already classified
+// are returned unchanged.
+func Classify(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	e = &Error{Err: err}
+
+	msg := strings.ToLower(err.Error())
+	if m := statusPattern.FindStringSubmatch(msg); m != nil {
+		e.StatusCode, _ = strconv.Atoi(m[1])
+	}
+	if m := retryAfterPattern.FindStringSubmatch(msg); m != nil {
+		e.RetryAfter = parseDelay(m[1])
+	}
+	e.Kind = classifyKind(err, msg, e.StatusCode)
+	return e
+}
+
+// classifyKind maps an error to one of the Err* sentinels.
+func classifyKind(err error, msg string, status int) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return nil
+	case errors.Is(err, context.DeadlineExceeded), isNetTimeout(err):
+		return ErrTimeout
+	case status == 429, strings.Contains(msg, "rate limit"), strings.Contains(msg, "too many requests"):
+		return ErrRateLimited
+	case status == 401, status == 403, strings.Contains(msg, "api key"), strings.Contains(msg, "unauthorized"):
+		return ErrAuth
+	case status == 413, strings.Contains(msg, "context length"), strings.Contains(msg, "context window"),
+		strings.Contains(msg, "maximum context"), strings.Contains(msg, "context_length_exceeded"):
+		return ErrContextTooLong
+	case strings.Contains(msg, "model") && (status == 404 || strings.Contains(msg, "not found") || strings.Contains(msg, "does not exist")):
+		return ErrModelNotFound
+	case status == 408, status >= 500,
+		errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF),
+		strings.Contains(msg, "connection refused"), strings.Contains(msg, "connection reset"),
+		strings.Contains(msg, "overloaded"):
+		return ErrTransient
+	}
+	return nil
+}
+
+func isNetTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseDelay parses a delay such as "20s", "1.5s", "500ms" or a bare
+// number of seconds.
+func parseDelay(s string) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(secs * float64(time.Second))
+	}
+	return 0
+}
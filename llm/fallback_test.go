@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// errHang makes a backend in chainClient block until its call times out.
+var errHang = errors.New("hang")
+
+// namedModel is a wrapper.Model identified by name; the generator below
+// looks the name up in a table of canned errors.
+type namedModel struct {
+	wrapper.Model
+	name string
+}
+
+// chainProvider builds namedModels, failing to initialise any listed in broken.
+type chainProvider struct {
+	broken map[string]bool
+}
+
+func (p chainProvider) Init(provider, model string) (wrapper.Model, error) {
+	name := provider + ":" + model
+	if p.broken[name] {
+		return nil, errors.New("missing API key")
+	}
+	return namedModel{name: name}, nil
+}
+
+func chainClient(t *testing.T, failures map[string]error, broken ...string) (*Client, *[]string) {
+	t.Helper()
+	var tried []string
+	cfg := llmConfig.Config{
+		Provider: "ollama",
+		Model:    llmConfig.ModelConfig{Name: "phi4"},
+		Client:   llmConfig.ClientConfig{Timeout: time.Second},
+		Fallbacks: []llmConfig.Backend{
+			{Provider: "ollama", Model: "llama3"},
+			{Provider: "openai", Model: "gpt-4o-mini"},
+		},
+	}
+	provider := chainProvider{broken: map[string]bool{}}
+	for _, b := range broken {
+		provider.broken[b] = true
+	}
+	client, err := NewClient(cfg, provider, func(ctx context.Context, m wrapper.Model, _ string, _ ...wrapper.CallOption) (string, error) {
+		if recorder, ok := m.(*usageModel); ok {
+			m = recorder.Model
+		}
+		name := m.(namedModel).name
+		tried = append(tried, name)
+		if failures[name] == errHang {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		if err := failures[name]; err != nil {
+			return "", err
+		}
+		return "answer from " + name, nil
+	})
+	assert.NoError(t, err)
+	return client, &tried
+}
+
+func TestClient_Fallback_PrimaryAnswers(t *testing.T) {
+	client, tried := chainClient(t, nil)
+
+	resp, err := client.Chat(context.Background(), "q")
+	assert.NoError(t, err)
+	assert.Equal(t, "answer from ollama:phi4", resp)
+	assert.Equal(t, []string{"ollama:phi4"}, *tried)
+	assert.Equal(t, llmConfig.Backend{Provider: "ollama", Model: "phi4"}, client.LastBackend())
+}
+
+func TestClient_Fallback_MovesOnWhenUnavailable(t *testing.T) {
+	client, tried := chainClient(t, map[string]error{
+		"ollama:phi4":   errors.New("503 Service Unavailable: server busy"),
+		"ollama:llama3": errors.New(`model "llama3" not found, try pulling it first`),
+	})
+
+	resp, err := client.Chat(context.Background(), "q")
+	assert.NoError(t, err)
+	assert.Equal(t, "answer from openai:gpt-4o-mini", resp)
+	assert.Equal(t, []string{"ollama:phi4", "ollama:llama3", "openai:gpt-4o-mini"}, *tried)
+	assert.Equal(t, llmConfig.Backend{Provider: "openai", Model: "gpt-4o-mini"}, client.LastBackend())
+}
+
+func TestClient_Fallback_MovesOnWhenPrimaryHangs(t *testing.T) {
+	client, tried := chainClient(t, map[string]error{"ollama:phi4": errHang})
+	client.config.Client.Timeout = 20 * time.Millisecond
+
+	resp, err := client.Chat(context.Background(), "q")
+	assert.NoError(t, err)
+	assert.Equal(t, "answer from ollama:llama3", resp)
+	assert.Equal(t, []string{"ollama:phi4", "ollama:llama3"}, *tried)
+}
+
+func TestClient_Fallback_StopsOnPermanentError(t *testing.T) {
+	client, tried := chainClient(t, map[string]error{
+		"ollama:phi4": errors.New("API returned unexpected status code: 400: maximum context length exceeded"),
+	})
+
+	_, err := client.Chat(context.Background(), "q")
+	assert.ErrorIs(t, err, ErrContextTooLong)
+	assert.ErrorContains(t, err, "ollama:phi4")
+	assert.Equal(t, []string{"ollama:phi4"}, *tried)
+}
+
+func TestClient_Fallback_AllFail(t *testing.T) {
+	busy := errors.New("503 Service Unavailable")
+	client, tried := chainClient(t, map[string]error{"ollama:phi4": busy, "ollama:llama3": busy}, "openai:gpt-4o-mini")
+
+	_, err := client.Chat(context.Background(), "q")
+	assert.ErrorContains(t, err, "all backends failed")
+	assert.ErrorContains(t, err, "openai:gpt-4o-mini: failed to initialize LLM provider: missing API key")
+	assert.ErrorIs(t, err, ErrTransient)
+	assert.Equal(t, []string{"ollama:phi4", "ollama:llama3"}, *tried)
+}
+
+func TestCached_RecordsAnsweringBackend(t *testing.T) {
+	client, _ := chainClient(t, map[string]error{"ollama:phi4": errors.New("connection refused")})
+	cache := NewCache(t.TempDir())
+	cached := NewCached(client, cache, CacheKey{Provider: "ollama", Model: "phi4"})
+
+	_, err := cached.Chat(context.Background(), "q")
+	assert.NoError(t, err)
+	assert.Equal(t, "ollama:llama3", cached.LastBackend().String())
+
+	// A fresh wrapper sees the backend stored alongside the cached response.
+	replay := NewCached(client, cache, CacheKey{Provider: "ollama", Model: "phi4"})
+	_, err = replay.Chat(context.Background(), "q")
+	assert.NoError(t, err)
+	assert.Equal(t, "ollama:llama3", replay.LastBackend().String())
+}
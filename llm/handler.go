@@ -13,3 +13,20 @@ func defaultStreamHandler(ctx context.Context, chunk []byte) error {
 	fmt.Print(string(chunk))
 	return nil
 }
+
+type streamHandlerKey struct{}
+
+// WithStreamHandler returns a context whose requests stream chunks to h,
+// in place of the client's own handler. Concurrent callers sharing a
+// client use it to stream to different places.
+func WithStreamHandler(ctx context.Context, h StreamHandler) context.Context {
+	return context.WithValue(ctx, streamHandlerKey{}, h)
+}
+
+// streamHandlerFrom returns the handler set on ctx, or fallback.
+func streamHandlerFrom(ctx context.Context, fallback StreamHandler) StreamHandler {
+	if h, ok := ctx.Value(streamHandlerKey{}).(StreamHandler); ok && h != nil {
+		return h
+	}
+	return fallback
+}
@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// History is an ordered, role-aware list of chat messages.
+// An optional system message is always kept at the front.
+type History struct {
+	messages []wrapper.MessageContent
+}
+
+// NewHistory returns a history seeded with an optional system prompt.
+func NewHistory(system string) *History {
+	h := &History{}
+	h.SetSystem(system)
+	return h
+}
+
+// SetSystem replaces the system prompt. An empty string removes it.
+func (h *History) SetSystem(text string) {
+	hasSystem := len(h.messages) > 0 && h.messages[0].Role == wrapper.RoleSystem
+	switch {
+	case text == "" && hasSystem:
+		h.messages = h.messages[1:]
+	case text == "":
+	case hasSystem:
+		h.messages[0] = wrapper.TextMessage(wrapper.RoleSystem, text)
+	default:
+		h.messages = append([]wrapper.MessageContent{wrapper.TextMessage(wrapper.RoleSystem, text)}, h.messages...)
+	}
+}
+
+// System returns the current system prompt, if any.
+func (h *History) System() string {
+	if len(h.messages) > 0 && h.messages[0].Role == wrapper.RoleSystem {
+		return wrapper.MessageText(h.messages[0])
+	}
+	return ""
+}
+
+// AddUser appends a user turn.
+func (h *History) AddUser(text string) {
+	h.messages = append(h.messages, wrapper.TextMessage(wrapper.RoleUser, text))
+}
+
+// AddAssistant appends an assistant turn.
+func (h *History) AddAssistant(text string) {
+	h.messages = append(h.messages, wrapper.TextMessage(wrapper.RoleAssistant, text))
+}
+
+// Pop removes the most recent message, e.g. a user turn whose call failed.
+func (h *History) Pop() {
+	if n := len(h.messages); n > 0 && h.messages[n-1].Role != wrapper.RoleSystem {
+		h.messages = h.messages[:n-1]
+	}
+}
+
+// Reset drops every turn but keeps the system prompt.
+func (h *History) Reset() {
+	system := h.System()
+	h.messages = nil
+	h.SetSystem(system)
+}
+
+// Len returns the number of messages, including the system prompt.
+func (h *History) Len() int {
+	return len(h.messages)
+}
+
+// Messages returns a copy of the history suitable for a model call.
+func (h *History) Messages() []wrapper.MessageContent {
+	return append([]wrapper.MessageContent(nil), h.messages...)
+}
+
+// Transcript renders the history as Markdown, one section per message.
+func (h *History) Transcript() string {
+	var sb strings.Builder
+	for i, msg := range h.messages {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "### %s\n\n%s\n", roleTitle(msg.Role), wrapper.MessageText(msg))
+	}
+	return sb.String()
+}
+
+// roleTitle maps a message role to a human-readable heading.
+func roleTitle(role wrapper.ChatMessageType) string {
+	switch role {
+	case wrapper.RoleSystem:
+		return "System"
+	case wrapper.RoleUser:
+		return "User"
+	case wrapper.RoleAssistant:
+		return "Assistant"
+	default:
+		return string(role)
+	}
+}
@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+func TestHistory_SystemPromptStaysFirst(t *testing.T) {
+	h := NewHistory("")
+	h.AddUser("hi")
+	h.SetSystem("be brief")
+
+	msgs := h.Messages()
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, wrapper.RoleSystem, msgs[0].Role)
+	assert.Equal(t, "be brief", h.System())
+
+	h.SetSystem("be verbose")
+	assert.Equal(t, 2, h.Len())
+	assert.Equal(t, "be verbose", h.System())
+
+	h.SetSystem("")
+	assert.Equal(t, 1, h.Len())
+	assert.Equal(t, "", h.System())
+}
+
+func TestHistory_ResetKeepsSystem(t *testing.T) {
+	h := NewHistory("sys")
+	h.AddUser("q")
+	h.AddAssistant("a")
+	assert.Equal(t, 3, h.Len())
+
+	h.Reset()
+	assert.Equal(t, 1, h.Len())
+	assert.Equal(t, "sys", h.System())
+}
+
+func TestHistory_PopNeverRemovesSystem(t *testing.T) {
+	h := NewHistory("sys")
+	h.AddUser("q")
+	h.Pop()
+	h.Pop()
+	assert.Equal(t, 1, h.Len())
+}
+
+func TestHistory_MessagesIsACopy(t *testing.T) {
+	h := NewHistory("")
+	h.AddUser("q")
+	msgs := h.Messages()
+	msgs[0] = wrapper.TextMessage(wrapper.RoleAssistant, "changed")
+	assert.Equal(t, wrapper.RoleUser, h.Messages()[0].Role)
+}
+
+func TestHistory_Transcript(t *testing.T) {
+	h := NewHistory("sys")
+	h.AddUser("q")
+	h.AddAssistant("a")
+	assert.Equal(t, "### System\n\nsys\n\n### User\n\nq\n\n### Assistant\n\na\n", h.Transcript())
+}
@@ -0,0 +1,168 @@
+package llm
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"raja.aiml/ai.explorer/llm/cassette"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// retryHint carries details of a failed HTTP response from the transport
+// back to the retry loop, since providers do not expose response headers.
+type retryHint struct {
+	mu         sync.Mutex
+	statusCode int
+	retryAfter time.Duration
+}
+
+type retryHintKey struct{}
+
+func (h *retryHint) set(status int, after time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.statusCode, h.retryAfter = status, after
+}
+
+func (h *retryHint) get() (int, time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.statusCode, h.retryAfter
+}
+
+// retryAfterTransport records the status and Retry-After header of error
+// responses into the request's retryHint, if one is attached.
+type retryAfterTransport struct {
+	base http.RoundTripper
+	now  func() time.Time
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode < http.StatusBadRequest {
+		return resp, err
+	}
+	if hint, ok := req.Context().Value(retryHintKey{}).(*retryHint); ok {
+		hint.set(resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After"), t.now()))
+	}
+	return resp, nil
+}
+
+// newHTTPClient returns the HTTP client used for provider requests,
+// recording or replaying traffic when the config asks for it.
+func newHTTPClient(cfg llmConfig.ClientConfig) *http.Client {
+	var base http.RoundTripper = http.DefaultTransport
+	switch {
+	case cfg.ReplayDir != "":
+		base = cassette.NewReplayer(cfg.ReplayDir)
+	case cfg.RecordDir != "":
+		base = cassette.NewRecorder(cfg.RecordDir, base)
+	}
+	return &http.Client{Transport: &retryAfterTransport{base: base, now: time.Now}}
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// generateFunc makes a single request against model.
+type generateFunc func(ctx context.Context, model wrapper.Model, opts []wrapper.CallOption) (string, error)
+
+// withRetry runs call against model according to the client's retry policy.
+// Each attempt gets its own timeout. Non-retryable errors, a cancelled parent
+// context, or output that has already been streamed end the loop immediately;
+// streamed reports the latter.
+func (c *Client) withRetry(ctx context.Context, model wrapper.Model, call generateFunc) (resp string, streamed bool, err error) {
+	policy := c.config.Client.Retry
+	attempts := max(policy.MaxAttempts, 1)
+	sleep, random := c.sleep, c.random
+	if sleep == nil {
+		sleep = sleepContext
+	}
+	if random == nil {
+		random = rand.Float64
+	}
+
+	opts := c.callOptions(ctx, func() { streamed = true })
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, model, opts, call)
+		if err == nil {
+			return resp, false, nil
+		}
+		e := err.(*Error)
+		e.Attempts = attempt
+
+		if attempt >= attempts || !Retryable(e) || streamed || ctx.Err() != nil {
+			return "", streamed, e
+		}
+		delay := e.RetryAfter
+		if delay <= 0 {
+			delay = policy.Backoff(attempt, random)
+		}
+		if sleep(ctx, delay) != nil {
+			return "", streamed, e
+		}
+	}
+}
+
+// attempt makes a single call and classifies any failure.
+func (c *Client) attempt(ctx context.Context, model wrapper.Model, opts []wrapper.CallOption, call generateFunc) (string, error) {
+	if c.config.Client.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Client.Timeout)
+		defer cancel()
+	}
+	hint := &retryHint{}
+	ctx = context.WithValue(ctx, retryHintKey{}, hint)
+
+	recorder := &usageModel{Model: model}
+	resp, err := call(ctx, recorder, opts)
+	if err == nil {
+		c.mu.Lock()
+		c.usage, c.usageReported = recorder.usage, recorder.ok
+		c.mu.Unlock()
+		return resp, nil
+	}
+	e := Classify(err)
+	if status, after := hint.get(); status != 0 {
+		if e.StatusCode == 0 {
+			e.StatusCode = status
+			e.Kind = classifyKind(err, strings.ToLower(err.Error()), status)
+		}
+		if after > 0 {
+			e.RetryAfter = after
+		}
+	}
+	return "", e
+}
@@ -0,0 +1,179 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+	llmConfig "raja.aiml/ai.explorer/llm/config"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// retryClient returns a client whose generator replays errs in order and
+// records the delays it would have slept for.
+func retryClient(policy llmConfig.RetryPolicy, errs ...error) (*Client, *int, *[]time.Duration) {
+	calls := 0
+	var delays []time.Duration
+	client := &Client{
+		config: llmConfig.Config{Client: llmConfig.ClientConfig{Timeout: time.Second, Retry: policy}},
+		callGen: func(ctx context.Context, _ wrapper.Model, _ string, _ ...wrapper.CallOption) (string, error) {
+			calls++
+			if calls <= len(errs) {
+				return "", errs[calls-1]
+			}
+			return "ok", nil
+		},
+		sleep: func(_ context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+		random: func() float64 { return 0.5 },
+	}
+	return client, &calls, &delays
+}
+
+var testPolicy = llmConfig.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+func TestClient_Chat_RetriesTransientErrors(t *testing.T) {
+	client, calls, delays := retryClient(testPolicy,
+		errors.New("503 Service Unavailable: loading model"),
+		fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED),
+	)
+
+	resp, err := client.Chat(context.Background(), "q")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.Equal(t, 3, *calls)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, *delays)
+}
+
+func TestClient_Chat_GivesUpAfterMaxAttempts(t *testing.T) {
+	overloaded := errors.New("API returned unexpected status code: 500: server overloaded")
+	client, calls, _ := retryClient(testPolicy, overloaded, overloaded, overloaded)
+
+	_, err := client.Chat(context.Background(), "q")
+	assert.ErrorIs(t, err, ErrTransient)
+	assert.ErrorIs(t, err, overloaded)
+	assert.ErrorContains(t, err, "(after 3 attempts)")
+	assert.Equal(t, 3, *calls)
+
+	var e *Error
+	assert.ErrorAs(t, err, &e)
+	assert.Equal(t, 500, e.StatusCode)
+	assert.Equal(t, 3, e.Attempts)
+}
+
+func TestClient_Chat_NeverRetriesPermanentErrors(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		kind error
+	}{
+		{errors.New("API returned unexpected status code: 401: Incorrect API key provided"), ErrAuth},
+		{errors.New(`model "phi9" not found, try pulling it first`), ErrModelNotFound},
+		{errors.New("API returned unexpected status code: 400: This model's maximum context length is 8192 tokens"), ErrContextTooLong},
+		{errors.New("something odd"), nil},
+	} {
+		client, calls, delays := retryClient(testPolicy, tc.err, tc.err)
+		_, err := client.Chat(context.Background(), "q")
+		assert.Error(t, err)
+		if tc.kind != nil {
+			assert.ErrorIs(t, err, tc.kind, tc.err.Error())
+		}
+		assert.False(t, Retryable(err))
+		assert.Equal(t, 1, *calls, tc.err.Error())
+		assert.Empty(t, *delays)
+	}
+}
+
+func TestClient_Chat_HonoursRetryAfterInMessage(t *testing.T) {
+	client, calls, delays := retryClient(testPolicy,
+		errors.New("API returned unexpected status code: 429: Rate limit reached. Please try again in 1.5s."),
+	)
+
+	_, err := client.Chat(context.Background(), "q")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, []time.Duration{1500 * time.Millisecond}, *delays)
+}
+
+func TestClient_Chat_NoRetryByDefault(t *testing.T) {
+	client, calls, _ := retryClient(llmConfig.RetryPolicy{}, errors.New("503 Service Unavailable"))
+	_, err := client.Chat(context.Background(), "q")
+	assert.ErrorIs(t, err, ErrTransient)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestClient_Chat_StopsWhenParentContextDone(t *testing.T) {
+	client, calls, _ := retryClient(testPolicy, context.DeadlineExceeded, context.DeadlineExceeded)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.Chat(ctx, "q")
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestClient_Chat_NoRetryAfterStreaming(t *testing.T) {
+	calls := 0
+	client := &Client{
+		config: llmConfig.Config{Client: llmConfig.ClientConfig{Retry: testPolicy}},
+		callGen: func(ctx context.Context, _ wrapper.Model, _ string, opts ...wrapper.CallOption) (string, error) {
+			calls++
+			var o llms.CallOptions
+			for _, opt := range opts {
+				opt(&o)
+			}
+			_ = o.StreamingFunc(ctx, []byte("partial"))
+			return "", errors.New("connection reset by peer")
+		},
+		sleep: func(context.Context, time.Duration) error { return nil },
+	}
+	client.SetStreamHandler(func(context.Context, []byte) error { return nil })
+
+	_, err := client.Chat(context.Background(), "q")
+	assert.ErrorIs(t, err, ErrTransient)
+	assert.Equal(t, 1, calls, "retrying would duplicate streamed output")
+}
+
+func TestClassify(t *testing.T) {
+	assert.ErrorIs(t, Classify(errors.New("429 Too Many Requests")), ErrRateLimited)
+	assert.ErrorIs(t, Classify(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)), ErrTimeout)
+	assert.Nil(t, Classify(context.Canceled).Kind)
+
+	e := Classify(errors.New("x"))
+	assert.Same(t, e, Classify(e), "classified errors are returned unchanged")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 7*time.Second, parseRetryAfter("7", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+}
+
+func TestRetryAfterTransport_RecordsHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "4")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	hint := &retryHint{}
+	ctx := context.WithValue(context.Background(), retryHintKey{}, hint)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, nil)
+	resp, err := newHTTPClient(llmConfig.ClientConfig{}).Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	status, after := hint.get()
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Equal(t, 4*time.Second, after)
+}
@@ -0,0 +1,31 @@
+package llm
+
+import (
+	"context"
+
+	"raja.aiml/ai.explorer/llm/wrapper"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// UsageReporter is implemented by clients that can report the token usage
+// of their most recent response.
+type UsageReporter interface {
+	// LastUsage returns the usage of the last response; ok is false when
+	// the provider reported none.
+	LastUsage() (usage tokens.Usage, ok bool)
+}
+
+// usageModel records the token usage reported with each response.
+type usageModel struct {
+	wrapper.Model
+	usage tokens.Usage
+	ok    bool
+}
+
+func (m *usageModel) GenerateContent(ctx context.Context, messages []wrapper.MessageContent, opts ...wrapper.CallOption) (*wrapper.ContentResponse, error) {
+	resp, err := m.Model.GenerateContent(ctx, messages, opts...)
+	if err == nil && resp != nil && len(resp.Choices) > 0 {
+		m.usage, m.ok = tokens.UsageFromGenerationInfo(resp.Choices[0].GenerationInfo)
+	}
+	return resp, err
+}
@@ -2,7 +2,9 @@ package wrapper
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/llms"
@@ -15,6 +17,14 @@ type (
 	CallOption      = llms.CallOption
 	MessageContent  = llms.MessageContent
 	ContentResponse = llms.ContentResponse
+	ChatMessageType = llms.ChatMessageType
+)
+
+// Chat roles understood by every provider.
+const (
+	RoleSystem    = llms.ChatMessageTypeSystem
+	RoleUser      = llms.ChatMessageTypeHuman
+	RoleAssistant = llms.ChatMessageTypeAI
 )
 
 // ---------- LLM Provider Abstraction ----------
@@ -25,20 +35,82 @@ type Provider interface {
 }
 
 // LangchaingoProvider is a concrete LLM provider using langchaingo.
-type LangchaingoProvider struct{}
+type LangchaingoProvider struct {
+	// HTTPClient, if set, is used for all requests made by the model.
+	HTTPClient *http.Client
+	// OllamaURL, if set, is the Ollama server to use instead of OLLAMA_HOST.
+	OllamaURL string
+	// OpenAIToken, if set, is the OpenAI API key to use instead of
+	// OPENAI_API_KEY. No other OpenAI setting is then read from the
+	// environment either.
+	OpenAIToken string
+	// OpenAIBaseURL, if set, is an OpenAI-compatible API to use instead
+	// of OPENAI_BASE_URL or the OpenAI API.
+	OpenAIBaseURL string
+}
 
 // Init returns a new Model for the given provider and model name.
 func (p *LangchaingoProvider) Init(providerName, modelName string) (Model, error) {
 	switch providerName {
 	case "ollama":
-		return ollama.New(ollama.WithModel(modelName))
+		return p.ollama(ollama.WithModel(modelName))
 	case "openai":
-		return openai.New(openai.WithModel(modelName))
+		return p.openai(openai.WithModel(modelName))
+	case ProviderEcho, ProviderMock, ProviderReplay:
+		return NewOfflineModel(providerName, modelName)
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %s", providerName)
 	}
 }
 
+// InitEmbedder returns an Embedder for the given provider and embedding
+// model.
+func (p *LangchaingoProvider) InitEmbedder(providerName, modelName string) (*EmbedderImpl, error) {
+	var (
+		client embeddings.EmbedderClient
+		err    error
+	)
+	switch providerName {
+	case "ollama":
+		client, err = p.ollama(ollama.WithModel(modelName))
+	case "openai":
+		client, err = p.openai(openai.WithEmbeddingModel(modelName))
+	default:
+		return nil, fmt.Errorf("LLM provider %s does not support embeddings", providerName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	base, err := embeddings.NewEmbedder(client)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbedderImpl{Base: base}, nil
+}
+
+func (p *LangchaingoProvider) ollama(opts ...ollama.Option) (*ollama.LLM, error) {
+	if p.HTTPClient != nil {
+		opts = append(opts, ollama.WithHTTPClient(p.HTTPClient))
+	}
+	if p.OllamaURL != "" {
+		opts = append(opts, ollama.WithServerURL(p.OllamaURL))
+	}
+	return ollama.New(opts...)
+}
+
+func (p *LangchaingoProvider) openai(opts ...openai.Option) (*openai.LLM, error) {
+	if p.HTTPClient != nil {
+		opts = append(opts, openai.WithHTTPClient(p.HTTPClient))
+	}
+	switch {
+	case p.OpenAIToken != "":
+		opts = append(opts, openai.WithToken(p.OpenAIToken), openai.WithOrganization(""), openai.WithBaseURL(p.OpenAIBaseURL))
+	case p.OpenAIBaseURL != "":
+		opts = append(opts, openai.WithBaseURL(p.OpenAIBaseURL))
+	}
+	return openai.New(opts...)
+}
+
 // ---------- Embedding Abstraction ----------
 
 // Embedder defines a minimal interface for generating vector embeddings.
@@ -80,6 +152,37 @@ func GenerateFromSinglePrompt(ctx context.Context, model Model, prompt string, o
 	return llms.GenerateFromSinglePrompt(ctx, model, prompt, opts...)
 }
 
+// TextMessage builds a single-part text message for the given role.
+func TextMessage(role ChatMessageType, text string) MessageContent {
+	return llms.TextParts(role, text)
+}
+
+// MessageText concatenates the text parts of a message, ignoring other parts.
+func MessageText(msg MessageContent) string {
+	var text string
+	for _, part := range msg.Parts {
+		if tc, ok := part.(llms.TextContent); ok {
+			text += tc.Text
+		}
+	}
+	return text
+}
+
+// EncodeMessages serialises a message history as a stable JSON list of
+// role/text pairs, used to key cached and recorded responses.
+func EncodeMessages(messages []MessageContent) string {
+	type msg struct {
+		Role string `json:"role"`
+		Text string `json:"text"`
+	}
+	out := make([]msg, len(messages))
+	for i, m := range messages {
+		out[i] = msg{Role: string(m.Role), Text: MessageText(m)}
+	}
+	data, _ := json.Marshal(out)
+	return string(data)
+}
+
 // WithTemperature wraps llms.WithTemperature
 func WithTemperature(temp float64) CallOption {
 	return llms.WithTemperature(temp)
@@ -49,6 +49,8 @@ func TestProvider_Init(t *testing.T) {
 	}{
 		{"openai", "openai", "gpt-3.5", false},
 		{"ollama", "ollama", "phi4", false},
+		{"echo", "echo", "any", false},
+		{"mock without fixtures", "mock", "missing.yaml", true},
 		{"invalid", "fake", "none", true},
 	}
 
@@ -74,6 +76,29 @@ func TestProvider_Init(t *testing.T) {
 	}
 }
 
+func TestProvider_ExplicitSettings(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+
+	_, err := (&wrapper.LangchaingoProvider{}).Init("openai", "gpt-4o-mini")
+	assert.Error(t, err, "without a token openai falls back to the empty environment")
+
+	prov := &wrapper.LangchaingoProvider{OpenAIToken: "key", OpenAIBaseURL: "http://localhost:8080/v1"}
+	model, err := prov.Init("openai", "gpt-4o-mini")
+	assert.NoError(t, err)
+	assert.NotNil(t, model)
+
+	embedder, err := prov.InitEmbedder("openai", "text-embedding-3-small")
+	assert.NoError(t, err)
+	assert.NotNil(t, embedder)
+
+	embedder, err = (&wrapper.LangchaingoProvider{OllamaURL: "http://localhost:11434"}).InitEmbedder("ollama", "nomic-embed-text")
+	assert.NoError(t, err)
+	assert.NotNil(t, embedder)
+
+	_, err = prov.InitEmbedder("echo", "any")
+	assert.EqualError(t, err, "LLM provider echo does not support embeddings")
+}
+
 func TestGenerateFromSinglePrompt(t *testing.T) {
 	ctx := context.Background()
 	prompt := "test"
@@ -128,3 +153,17 @@ func newMockContentResponse(text string) *llms.ContentResponse {
 		},
 	}
 }
+
+func TestTextMessage_RoundTrip(t *testing.T) {
+	msg := wrapper.TextMessage(wrapper.RoleSystem, "be brief")
+	assert.Equal(t, wrapper.RoleSystem, msg.Role)
+	assert.Equal(t, "be brief", wrapper.MessageText(msg))
+}
+
+func TestMessageText_IgnoresNonTextParts(t *testing.T) {
+	msg := llms.MessageContent{
+		Role:  wrapper.RoleUser,
+		Parts: []llms.ContentPart{llms.TextPart("look at "), llms.ImageURLPart("http://x/y.png"), llms.TextPart("this")},
+	}
+	assert.Equal(t, "look at this", wrapper.MessageText(msg))
+}
@@ -0,0 +1,307 @@
+package wrapper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+	"gopkg.in/yaml.v3"
+)
+
+// Offline providers answer without a model server. For mock and replay the
+// model name is the path to the fixtures file or recording.
+const (
+	ProviderEcho   = "echo"
+	ProviderMock   = "mock"
+	ProviderReplay = "replay"
+)
+
+// OfflineProviders lists the providers that need no server.
+var OfflineProviders = []string{ProviderEcho, ProviderMock, ProviderReplay}
+
+// MockFixtures is the YAML fixtures file read by the mock provider.
+// Responses are tried in order; the first whose Match regex finds the
+// latest user message wins, otherwise Default is returned.
+//
+//	stream_delay: 20ms
+//	default: "I don't know."
+//	responses:
+//	  - match: "(?i)bgp"
+//	    response: "BGP is a path-vector protocol."
+//	  - match: "overload"
+//	    error: "503 Service Unavailable"
+//	  - match: "slow"
+//	    delay: 1m     # stalls until the call times out...
+//	    times: 1      # ...once, then the next match answers
+//	  - match: "slow"
+//	    response: "Sorry for the wait."
+type MockFixtures struct {
+	StreamDelay time.Duration  `yaml:"stream_delay"`
+	Default     *string        `yaml:"default"`
+	Responses   []MockResponse `yaml:"responses"`
+
+	mu sync.Mutex // guards the use counts of Responses
+}
+
+// MockResponse is a single canned answer, or a canned failure when Error is set.
+type MockResponse struct {
+	Match    string `yaml:"match"`
+	Response string `yaml:"response"`
+	Error    string `yaml:"error"`
+	// Delay is waited before answering, or until the call is cancelled.
+	Delay time.Duration `yaml:"delay"`
+	// Times limits how many prompts this response answers; 0 means no limit.
+	Times int `yaml:"times"`
+
+	re   *regexp.Regexp
+	used int
+}
+
+// LoadMockFixtures reads and compiles a fixtures file.
+func LoadMockFixtures(path string) (*MockFixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("mock provider: failed to read fixtures (pass the file as the model name): %w", err)
+	}
+	var fixtures MockFixtures
+	if err := yaml.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("mock provider: failed to parse fixtures %s: %w", path, err)
+	}
+	for i := range fixtures.Responses {
+		r := &fixtures.Responses[i]
+		if r.re, err = regexp.Compile(r.Match); err != nil {
+			return nil, fmt.Errorf("mock provider: response %d: invalid match pattern: %w", i+1, err)
+		}
+	}
+	return &fixtures, nil
+}
+
+// Respond picks the canned answer for a prompt.
+func (f *MockFixtures) Respond(ctx context.Context, prompt string) (string, error) {
+	r := f.match(prompt)
+	if r == nil {
+		if f.Default != nil {
+			return *f.Default, nil
+		}
+		return "", fmt.Errorf("mock provider: no fixture matches prompt %q", truncate(prompt, 60))
+	}
+	if r.Delay > 0 {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(r.Delay):
+		}
+	}
+	if r.Error != "" {
+		return "", errors.New(r.Error)
+	}
+	return r.Response, nil
+}
+
+// match returns the first response for prompt that has uses left, and
+// counts the use.
+func (f *MockFixtures) match(prompt string) *MockResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.Responses {
+		r := &f.Responses[i]
+		if !r.re.MatchString(prompt) || (r.Times > 0 && r.used >= r.Times) {
+			continue
+		}
+		r.used++
+		return r
+	}
+	return nil
+}
+
+// Recording holds responses captured by an earlier run, keyed by request.
+type Recording map[string]string
+
+// recordedEntry mirrors the fields of a response cache entry.
+type recordedEntry struct {
+	Request   string    `json:"request"`
+	Response  string    `json:"response"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoadRecording reads recorded responses from either a response cache
+// directory (as written with --cache) or a JSONL file of
+// {"request": ..., "response": ...} records. Newer entries win.
+func LoadRecording(path string) (Recording, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay provider: failed to open recording (pass it as the model name): %w", err)
+	}
+	var entries []recordedEntry
+	if info.IsDir() {
+		entries, err = readRecordingDir(path)
+	} else {
+		entries, err = readRecordingFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("replay provider: %w", err)
+	}
+
+	rec := Recording{}
+	newest := map[string]time.Time{}
+	for _, e := range entries {
+		if seen, ok := newest[e.Request]; ok && e.CreatedAt.Before(seen) {
+			continue
+		}
+		rec[e.Request], newest[e.Request] = e.Response, e.CreatedAt
+	}
+	return rec, nil
+}
+
+func readRecordingDir(dir string) ([]recordedEntry, error) {
+	var entries []recordedEntry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var e recordedEntry
+		if json.Unmarshal(data, &e) == nil && e.Request != "" {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func readRecordingFile(path string) ([]recordedEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []recordedEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e recordedEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Respond returns the recorded response for messages.
+func (r Recording) Respond(_ context.Context, messages []MessageContent) (string, error) {
+	key := EncodeMessages(messages)
+	if len(messages) == 1 && messages[0].Role == RoleUser {
+		key = MessageText(messages[0])
+	}
+	if resp, ok := r[key]; ok {
+		return resp, nil
+	}
+	return "", fmt.Errorf("replay provider: no recorded response for prompt %q", truncate(lastUserText(messages), 60))
+}
+
+// offlineModel implements Model on top of a response function and
+// simulates streaming by emitting the response word by word.
+type offlineModel struct {
+	respond    func(ctx context.Context, messages []MessageContent) (string, error)
+	chunkDelay time.Duration
+}
+
+// NewOfflineModel returns the model for an offline provider. For mock and
+// replay, source is the fixtures file or recording path.
+func NewOfflineModel(providerName, source string) (Model, error) {
+	switch providerName {
+	case ProviderEcho:
+		return &offlineModel{respond: func(_ context.Context, messages []MessageContent) (string, error) {
+			return lastUserText(messages), nil
+		}}, nil
+	case ProviderMock:
+		fixtures, err := LoadMockFixtures(source)
+		if err != nil {
+			return nil, err
+		}
+		return &offlineModel{
+			respond: func(ctx context.Context, messages []MessageContent) (string, error) {
+				return fixtures.Respond(ctx, lastUserText(messages))
+			},
+			chunkDelay: fixtures.StreamDelay,
+		}, nil
+	case ProviderReplay:
+		rec, err := LoadRecording(source)
+		if err != nil {
+			return nil, err
+		}
+		return &offlineModel{respond: rec.Respond}, nil
+	default:
+		return nil, fmt.Errorf("unsupported offline provider: %s", providerName)
+	}
+}
+
+// GenerateContent answers messages, streaming the reply if requested.
+func (m *offlineModel) GenerateContent(ctx context.Context, messages []MessageContent, options ...CallOption) (*ContentResponse, error) {
+	resp, err := m.respond(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if opts.StreamingFunc != nil {
+		for _, chunk := range streamChunks.FindAllString(resp, -1) {
+			if m.chunkDelay > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(m.chunkDelay):
+				}
+			}
+			if err := opts.StreamingFunc(ctx, []byte(chunk)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &ContentResponse{Choices: []*llms.ContentChoice{{Content: resp}}}, nil
+}
+
+// Call implements the single-prompt half of Model.
+func (m *offlineModel) Call(ctx context.Context, prompt string, options ...CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// streamChunks splits a response into words, each with its trailing space.
+var streamChunks = regexp.MustCompile(`\s*\S+\s*|\s+`)
+
+// lastUserText returns the text of the latest user message.
+func lastUserText(messages []MessageContent) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == RoleUser {
+			return MessageText(messages[i])
+		}
+	}
+	return ""
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,154 @@
+package wrapper_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+// generate runs a prompt through model, collecting streamed chunks.
+func generate(t *testing.T, model wrapper.Model, prompt string) (string, []string, error) {
+	t.Helper()
+	var chunks []string
+	resp, err := wrapper.GenerateFromSinglePrompt(context.Background(), model, prompt,
+		wrapper.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+			chunks = append(chunks, string(chunk))
+			return nil
+		}))
+	return resp, chunks, err
+}
+
+func TestEchoProvider(t *testing.T) {
+	model, err := wrapper.NewOfflineModel(wrapper.ProviderEcho, "")
+	require.NoError(t, err)
+
+	resp, chunks, err := generate(t, model, "hello offline world")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello offline world", resp)
+	assert.Equal(t, []string{"hello ", "offline ", "world"}, chunks)
+}
+
+func TestMockProvider(t *testing.T) {
+	path := writeFile(t, "mock.yaml", `
+default: "no idea"
+responses:
+  - match: "(?i)bgp"
+    response: "BGP is a path-vector protocol."
+  - match: "overload"
+    error: "503 Service Unavailable"
+`)
+	model, err := (&wrapper.LangchaingoProvider{}).Init(wrapper.ProviderMock, path)
+	require.NoError(t, err)
+
+	resp, chunks, err := generate(t, model, "Explain bgp please")
+	assert.NoError(t, err)
+	assert.Equal(t, "BGP is a path-vector protocol.", resp)
+	assert.Equal(t, resp, strings.Join(chunks, ""))
+	assert.Len(t, chunks, 5)
+
+	resp, _, err = generate(t, model, "something else")
+	assert.NoError(t, err)
+	assert.Equal(t, "no idea", resp)
+
+	_, _, err = generate(t, model, "simulate overload")
+	assert.EqualError(t, err, "503 Service Unavailable")
+}
+
+func TestMockProvider_DelayAndTimes(t *testing.T) {
+	fixtures, err := wrapper.LoadMockFixtures(writeFile(t, "slow.yaml", `
+responses:
+  - match: "slow"
+    delay: 1m
+    times: 1
+  - match: "slow"
+    response: "Sorry for the wait."
+`))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = fixtures.Respond(ctx, "slow please")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	resp, err := fixtures.Respond(context.Background(), "slow please")
+	assert.NoError(t, err)
+	assert.Equal(t, "Sorry for the wait.", resp)
+}
+
+func TestMockProvider_Errors(t *testing.T) {
+	_, err := wrapper.LoadMockFixtures(writeFile(t, "bad.yaml", "responses:\n  - match: \"(\"\n"))
+	assert.ErrorContains(t, err, "invalid match pattern")
+
+	fixtures, err := wrapper.LoadMockFixtures(writeFile(t, "empty.yaml", "responses: []\n"))
+	require.NoError(t, err)
+	_, err = fixtures.Respond(context.Background(), "anything")
+	assert.ErrorContains(t, err, "no fixture matches")
+}
+
+func TestReplayProvider_CacheDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "ab"), 0755))
+	chat := wrapper.EncodeMessages([]wrapper.MessageContent{
+		wrapper.TextMessage(wrapper.RoleSystem, "be brief"),
+		wrapper.TextMessage(wrapper.RoleUser, "hi"),
+	})
+	entries := map[string]string{
+		"ab/1.json": `{"request":"what is git","response":"a VCS","created_at":"2025-01-01T00:00:00Z"}`,
+		"ab/2.json": `{"request":"what is git","response":"a distributed VCS","created_at":"2025-02-01T00:00:00Z"}`,
+		"ab/3.json": `{"request":` + mustJSON(chat) + `,"response":"hello"}`,
+	}
+	for name, body := range entries {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(body), 0644))
+	}
+
+	model, err := wrapper.NewOfflineModel(wrapper.ProviderReplay, dir)
+	require.NoError(t, err)
+
+	resp, chunks, err := generate(t, model, "what is git")
+	assert.NoError(t, err)
+	assert.Equal(t, "a distributed VCS", resp, "newest recording wins")
+	assert.Equal(t, []string{"a ", "distributed ", "VCS"}, chunks)
+
+	out, err := model.GenerateContent(context.Background(), []wrapper.MessageContent{
+		wrapper.TextMessage(wrapper.RoleSystem, "be brief"),
+		wrapper.TextMessage(wrapper.RoleUser, "hi"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", out.Choices[0].Content)
+
+	_, _, err = generate(t, model, "never asked")
+	assert.ErrorContains(t, err, "no recorded response")
+}
+
+func TestReplayProvider_JSONL(t *testing.T) {
+	path := writeFile(t, "recording.jsonl", `{"request":"ping","response":"pong"}`+"\n\n")
+	rec, err := wrapper.LoadRecording(path)
+	require.NoError(t, err)
+	assert.Equal(t, wrapper.Recording{"ping": "pong"}, rec)
+
+	_, err = wrapper.LoadRecording(writeFile(t, "bad.jsonl", "{not json\n"))
+	assert.ErrorContains(t, err, "bad.jsonl:1")
+
+	_, err = wrapper.LoadRecording(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "failed to open recording")
+}
+
+func mustJSON(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
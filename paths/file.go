@@ -1,7 +1,7 @@
 package paths
 
 import (
-	"log"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,14 +9,15 @@ import (
 
 // Declare package-level variables for dependency injection.
 var mkdirAll = os.MkdirAll
-var fatalf = log.Fatalf
 
-// EnsureDirectoryExists checks if a directory exists and creates it if necessary.
-func EnsureDirectoryExists(filePath string) {
+// EnsureDirectoryExists creates the directory that will hold filePath, if
+// it does not exist yet.
+func EnsureDirectoryExists(filePath string) error {
 	dir := filepath.Dir(filePath)
 	if err := mkdirAll(dir, os.ModePerm); err != nil {
-		fatalf("Error creating directory %s: %v", dir, err)
+		return fmt.Errorf("error creating directory %s: %w", dir, err)
 	}
+	return nil
 }
 
 // FormatList converts a slice of strings into a formatted list.
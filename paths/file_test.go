@@ -42,7 +42,9 @@ func TestEnsureDirectoryExistsSuccess(t *testing.T) {
 	}
 
 	testFilePath := filepath.Join("some", "dir", "file.txt")
-	EnsureDirectoryExists(testFilePath)
+	if err := EnsureDirectoryExists(testFilePath); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
 
 	if !called {
 		t.Error("Expected mkdirAll to be called")
@@ -56,40 +58,25 @@ func TestEnsureDirectoryExistsSuccess(t *testing.T) {
 
 // TestEnsureDirectoryExistsError tests the error branch of EnsureDirectoryExists.
 func TestEnsureDirectoryExistsError(t *testing.T) {
-	// Save original functions and restore them later.
+	// Save the original mkdirAll function and restore it after the test.
 	originalMkdirAll := mkdirAll
-	originalFatalf := fatalf
-	defer func() {
-		mkdirAll = originalMkdirAll
-		fatalf = originalFatalf
-	}()
+	defer func() { mkdirAll = originalMkdirAll }()
 
 	// Override mkdirAll to return an error.
+	fakeErr := errors.New("fake error")
 	mkdirAll = func(path string, perm os.FileMode) error {
-		return errors.New("fake error")
-	}
-
-	// Override fatalf to capture the error message and panic instead of calling os.Exit.
-	var fatalMsg string
-	fatalf = func(format string, v ...interface{}) {
-		fatalMsg = fmt.Sprintf(format, v...)
-		panic("fatal")
+		return fakeErr
 	}
 
 	testFilePath := filepath.Join("err", "dir", "file.txt")
-	// Use recover to catch the panic from fatalf.
-	defer func() {
-		if r := recover(); r == nil {
-			t.Error("Expected EnsureDirectoryExists to panic")
-		} else {
-			// Verify that the captured fatal message contains the expected directory.
-			expectedSubstr := fmt.Sprintf("Error creating directory %s:", filepath.Dir(testFilePath))
-			if !strings.Contains(fatalMsg, expectedSubstr) {
-				t.Errorf("Expected fatal message to contain %q, got %q", expectedSubstr, fatalMsg)
-			}
-		}
-	}()
+	err := EnsureDirectoryExists(testFilePath)
+	if !errors.Is(err, fakeErr) {
+		t.Fatalf("Expected the mkdirAll error, got %v", err)
+	}
 
-	EnsureDirectoryExists(testFilePath)
-	// If no panic occurs, the test will fail in the deferred function.
+	// Verify that the error names the directory.
+	expectedSubstr := fmt.Sprintf("error creating directory %s:", filepath.Dir(testFilePath))
+	if !strings.Contains(err.Error(), expectedSubstr) {
+		t.Errorf("Expected error to contain %q, got %q", expectedSubstr, err.Error())
+	}
 }
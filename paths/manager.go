@@ -4,6 +4,8 @@ import "fmt"
 
 // Default paths
 const (
+	// ResourcesDir holds the prompt categories, shared partials and layouts.
+	ResourcesDir     = "resources"
 	BasePath         = "resources/templates"
 	ConfigPathFormat = BasePath + "/configs/%s.yaml"
 	OutputPathFormat = BasePath + "/output/%s/prompt.txt"
@@ -46,15 +48,21 @@ func GetTemplatePath(customPath string) string {
 // PathResolver defines how paths are derived for prompts.
 type PathResolver struct {
 	PromptCategory string
+	// Root is the resources tree; it defaults to ResourcesDir.
+	Root string
 }
 
 // Derive returns template, config, and output file paths.
 func (r PathResolver) Derive(topic string) (template, config, output string) {
-	base := fmt.Sprintf("resources/%s/%s", r.PromptCategory, topic)
+	root := r.Root
+	if root == "" {
+		root = ResourcesDir
+	}
+	base := fmt.Sprintf("%s/%s/%s", root, r.PromptCategory, topic)
 
 	if r.PromptCategory == "topics" {
 		// Template is shared across topics
-		template = "resources/topics/template.yaml"
+		template = root + "/topics/template.yaml"
 	} else {
 		template = fmt.Sprintf("%s/template.yaml", base)
 	}
@@ -78,6 +78,7 @@ func TestPathResolver_Derive(t *testing.T) {
 	tests := []struct {
 		name           string
 		promptCategory string
+		root           string
 		topic          string
 		wantTmpl       string
 		wantCfg        string
@@ -99,6 +100,15 @@ func TestPathResolver_Derive(t *testing.T) {
 			wantCfg:        "resources/chart/flowchart/config.yaml",
 			wantOut:        "resources/chart/flowchart/prompt.txt",
 		},
+		{
+			name:           "custom root",
+			promptCategory: "topics",
+			root:           "/tmp/res",
+			topic:          "demo",
+			wantTmpl:       "/tmp/res/topics/template.yaml",
+			wantCfg:        "/tmp/res/topics/demo/config.yaml",
+			wantOut:        "/tmp/res/topics/demo/prompt.txt",
+		},
 		{
 			name:           "empty promptType",
 			promptCategory: "",
@@ -111,7 +121,7 @@ func TestPathResolver_Derive(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resolver := PathResolver{PromptCategory: tt.promptCategory}
+			resolver := PathResolver{PromptCategory: tt.promptCategory, Root: tt.root}
 			gotTmpl, gotCfg, gotOut := resolver.Derive(tt.topic)
 
 			assert.Equal(t, tt.wantTmpl, gotTmpl)
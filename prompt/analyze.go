@@ -0,0 +1,756 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/flosch/pongo2/v6"
+)
+
+// Path segments with special meaning in a Ref.
+const (
+	// eachElement stands for every element of a list.
+	eachElement = "[]"
+	// eachValue stands for every value of a map.
+	eachValue = "*"
+)
+
+// Ref is a config path a template reads, such as ["tasks", "[]", "title"]
+// for `{{ task.title }}` inside `{% for task in tasks %}`.
+type Ref struct {
+	Path []string
+	// Whole is set when the template prints or tests the value itself, so
+	// everything beneath it counts as used. Loop sources are not whole.
+	Whole bool
+	// Optional is set when a default filter or an enclosing if tag covers
+	// a missing value.
+	Optional bool
+	// Loop is set for the source of a for tag.
+	Loop bool
+	// Line is the 1-based line of the tag that reads the path.
+	Line int
+}
+
+func (r Ref) String() string {
+	return formatPath(r.Path)
+}
+
+// Analysis lists the config paths a template reads.
+type Analysis struct {
+	Refs []Ref
+}
+
+// Paths returns the distinct paths the template reads, sorted.
+func (a *Analysis) Paths() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, r := range a.Refs {
+		if s := r.String(); !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`(?s)\{\{(.*?)\}\}|\{%(.*?)%\}|\{#.*?#\}`)
+	identPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// keywords are expression words that are not variables.
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "is": true,
+	"true": true, "false": true, "True": true, "False": true,
+	"none": true, "None": true, "nil": true,
+}
+
+// builtins are names pongo2 defines inside templates.
+var builtins = map[string]bool{"forloop": true}
+
+// binding is what a local name stands for: a config path, or nil for a
+// value computed in the template.
+type binding []string
+
+// analyzer walks a template's tags, tracking local names in scopes and the
+// paths tested by enclosing if tags.
+type analyzer struct {
+	scopes []map[string]binding
+	guards [][][]string
+	refs   []Ref
+	line   int
+
+	// loader follows includes and extends when set. files holds the
+	// templates being read, and at the line of the outermost include, which
+	// is where refs from included files are reported.
+	loader *Loader
+	files  []string
+	at     int
+	// overridden holds the blocks a child template defines, which replace
+	// those of the template it extends. skipping counts the nested blocks
+	// inside an overridden one.
+	overridden map[string]bool
+	skipping   int
+	parent     string
+}
+
+// AnalyzeTemplate statically lists the variables, loop sources and
+// attribute paths a pongo2 template reads.
+func AnalyzeTemplate(src string) (*Analysis, error) {
+	return analyzeWith(nil, "", src)
+}
+
+// analyzeWith analyzes src, read from file, following literal includes
+// and extends through loader when it is not nil.
+func analyzeWith(loader *Loader, file, src string) (*Analysis, error) {
+	a := &analyzer{scopes: []map[string]binding{{}}, loader: loader, overridden: map[string]bool{}}
+	if file != "" {
+		a.files = []string{absPath(file)}
+	}
+	if err := a.scan(file, src); err != nil {
+		return nil, err
+	}
+	return &Analysis{Refs: a.refs}, nil
+}
+
+// scan analyzes the tags in src, then the template it extends, if any.
+func (a *analyzer) scan(file, src string) error {
+	a.line, a.parent = 1, ""
+	inComment, pos := false, 0
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(src, -1) {
+		a.line += strings.Count(src[pos:m[0]], "\n")
+		pos = m[0]
+		switch {
+		case m[2] >= 0 && !inComment && a.skipping == 0:
+			a.expression(trimTag(src[m[2]:m[3]]), true)
+		case m[4] >= 0:
+			body := trimTag(src[m[4]:m[5]])
+			name, rest, _ := strings.Cut(body, " ")
+			if inComment {
+				inComment = name != "endcomment"
+				continue
+			}
+			if name == "comment" {
+				inComment = true
+				continue
+			}
+			if a.skip(name, strings.TrimSpace(rest)) {
+				continue
+			}
+			if err := a.tag(file, name, strings.TrimSpace(rest)); err != nil {
+				var perr *ParseError
+				if errors.As(err, &perr) {
+					return err
+				}
+				return &ParseError{Line: a.refLine(), Err: err}
+			}
+		}
+	}
+	if a.parent == "" {
+		return nil
+	}
+	// The parent renders around the child's blocks.
+	parent := a.parent
+	return a.inline(file, parent, func(path, src string) error { return a.scan(path, src) })
+}
+
+// skip tracks block tags, reporting whether the tag lies inside a block
+// that a child template overrides.
+func (a *analyzer) skip(name, args string) bool {
+	switch name {
+	case "block":
+		if a.skipping > 0 || a.overridden[args] {
+			a.skipping++
+			return true
+		}
+		a.overridden[args] = true
+	case "endblock":
+		if a.skipping > 0 {
+			a.skipping--
+			return true
+		}
+	}
+	return a.skipping > 0
+}
+
+// inline reads the template name refers to from file and analyzes it with
+// visit. Refs inside it are reported at the including tag's line. Names
+// that are not literal, or files that cannot be read, are skipped.
+func (a *analyzer) inline(file, name string, visit func(path, src string) error) error {
+	if a.loader == nil {
+		return nil
+	}
+	path := a.loader.Abs(file, name)
+	for _, loading := range a.files {
+		if loading == path {
+			return fmt.Errorf("include cycle: %s", name)
+		}
+	}
+	data, err := a.loader.read(path)
+	if err != nil {
+		return nil
+	}
+
+	line, at := a.line, a.at
+	if a.at == 0 {
+		a.at = a.line
+	}
+	a.files = append(a.files, path)
+	err = visit(path, string(data))
+	a.files = a.files[:len(a.files)-1]
+	a.line, a.at = line, at
+	return err
+}
+
+// refLine is the line refs are reported at: the outermost include tag
+// while inside an included file.
+func (a *analyzer) refLine() int {
+	if a.at > 0 {
+		return a.at
+	}
+	return a.line
+}
+
+// trimTag strips whitespace-control dashes and spaces from a tag body.
+func trimTag(s string) string {
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(s), "-"))
+}
+
+func (a *analyzer) tag(file, name, args string) error {
+	switch name {
+	case "include":
+		return a.include(file, args)
+	case "extends":
+		if name, ok := literal(args); ok {
+			a.parent = name
+		}
+	case "import":
+		a.importTag(args)
+	case "for":
+		return a.forTag(args)
+	case "endfor", "endwith", "endmacro":
+		if len(a.scopes) > 1 {
+			a.scopes = a.scopes[:len(a.scopes)-1]
+		}
+	case "with":
+		a.push()
+		a.assignments(args)
+	case "set":
+		target, expr, ok := strings.Cut(args, "=")
+		if !ok {
+			return fmt.Errorf("invalid set tag: %q", args)
+		}
+		a.bind(strings.TrimSpace(target), a.expression(expr, true))
+	case "macro":
+		macro, _, _ := strings.Cut(args, "(")
+		a.bind(strings.TrimSpace(macro), nil)
+		a.push()
+		if open, end := strings.Index(args, "("), strings.LastIndex(args, ")"); open >= 0 && end > open {
+			// Anything after the parameters, such as export, is not one.
+			params := args[open+1 : end]
+			for _, param := range strings.Split(params, ",") {
+				pname, def, _ := strings.Cut(param, "=")
+				a.expression(def, true)
+				a.bind(strings.TrimSpace(pname), nil)
+			}
+		}
+	case "if":
+		// Tested values may be absent, and so may what the branch prints.
+		start := len(a.refs)
+		a.expression(args, true)
+		var tested [][]string
+		for i := start; i < len(a.refs); i++ {
+			a.refs[i].Optional = true
+			tested = append(tested, a.refs[i].Path)
+		}
+		a.guards = append(a.guards, tested)
+	case "elif":
+		start := len(a.refs)
+		a.expression(args, true)
+		for i := start; i < len(a.refs); i++ {
+			a.refs[i].Optional = true
+			a.guards[len(a.guards)-1] = append(a.guards[len(a.guards)-1], a.refs[i].Path)
+		}
+	case "endif":
+		if len(a.guards) > 0 {
+			a.guards = a.guards[:len(a.guards)-1]
+		}
+	case "ifequal", "ifnotequal", "filter", "firstof", "cycle":
+		a.expression(args, true)
+	}
+	return nil
+}
+
+// include analyzes `include "name" [with a=expr ...] [only]` in place, so
+// the included file sees the includer's loop variables and bindings.
+func (a *analyzer) include(file, args string) error {
+	name, ok := literal(args)
+	if !ok {
+		a.expression(args, true)
+		return nil
+	}
+	a.push()
+	if _, with, ok := strings.Cut(args, " with "); ok {
+		a.assignments(strings.TrimSuffix(strings.TrimSpace(with), " only"))
+	}
+	err := a.inline(file, name, func(path, src string) error {
+		// Included files have their own blocks and parents.
+		overridden, skipping, parent := a.overridden, a.skipping, a.parent
+		a.overridden, a.skipping = map[string]bool{}, 0
+		err := a.scan(path, src)
+		a.overridden, a.skipping, a.parent = overridden, skipping, parent
+		return err
+	})
+	a.scopes = a.scopes[:len(a.scopes)-1]
+	return err
+}
+
+// importTag binds the macro names `import "file" a, b as c` brings in.
+func (a *analyzer) importTag(args string) {
+	name, ok := literal(args)
+	if !ok {
+		return
+	}
+	rest := strings.TrimSpace(args[strings.Index(args, name)+len(name)+1:])
+	for _, item := range strings.Split(rest, ",") {
+		macro, alias, ok := strings.Cut(item, " as ")
+		if ok {
+			macro = alias
+		}
+		a.bind(strings.TrimSpace(macro), nil)
+	}
+}
+
+// literal returns the quoted template name at the start of args.
+func literal(args string) (string, bool) {
+	if len(args) < 2 || (args[0] != '"' && args[0] != '\'') {
+		return "", false
+	}
+	end := strings.IndexByte(args[1:], args[0])
+	if end < 0 {
+		return "", false
+	}
+	return args[1 : end+1], true
+}
+
+// guarded reports whether an enclosing if tag tests path or a parent.
+func (a *analyzer) guarded(path []string) bool {
+	for _, tested := range a.guards {
+		for _, g := range tested {
+			if matchPrefix(path, g) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forTag binds the loop variables to elements of the loop source.
+func (a *analyzer) forTag(args string) error {
+	vars, source, ok := strings.Cut(args, " in ")
+	if !ok {
+		return fmt.Errorf("invalid for tag: %q", args)
+	}
+	source = strings.TrimSpace(source)
+	source = strings.TrimSuffix(strings.TrimSuffix(source, " sorted"), " reversed")
+	start := len(a.refs)
+	path := a.expression(source, false)
+	for i := start; i < len(a.refs); i++ {
+		a.refs[i].Loop = !a.refs[i].Whole
+	}
+
+	a.push()
+	names := strings.Split(vars, ",")
+	for i, n := range names {
+		var b binding
+		switch {
+		case path == nil:
+		case len(names) == 1:
+			b = appendPath(path, eachElement)
+		case i == 1:
+			b = appendPath(path, eachValue)
+		}
+		a.bind(strings.TrimSpace(n), b)
+	}
+	return nil
+}
+
+// assignments handles `with a=expr b=expr` and `with expr as name`.
+func (a *analyzer) assignments(args string) {
+	if expr, name, ok := strings.Cut(args, " as "); ok {
+		a.bind(strings.TrimSpace(name), a.expression(expr, true))
+		return
+	}
+	for _, part := range strings.Fields(args) {
+		if name, expr, ok := strings.Cut(part, "="); ok {
+			a.bind(name, a.expression(expr, true))
+		}
+	}
+}
+
+// expression records the paths read by expr. If expr is a single path it
+// is returned, so callers can bind names to it.
+func (a *analyzer) expression(expr string, whole bool) binding {
+	var (
+		paths      []binding
+		lastRef    = -1
+		lastPath   binding
+		filterNext bool
+		other      bool
+	)
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil
+			}
+			i += end + 2
+			other = true
+		case unicode.IsDigit(rune(c)):
+			for i < len(expr) && (unicode.IsDigit(rune(expr[i])) || expr[i] == '.') {
+				i++
+			}
+			other = true
+		case c == '|':
+			filterNext = true
+			i++
+		case identPattern.MatchString(expr[i:]):
+			path, n := readPath(expr[i:])
+			i += n
+			if filterNext {
+				filterNext = false
+				if path[0] == "default" && lastRef >= 0 {
+					a.refs[lastRef].Optional = true
+				}
+				other = true
+				continue
+			}
+			if keywords[path[0]] || builtins[path[0]] {
+				other = true
+				continue
+			}
+			resolved, ok := a.resolve(path)
+			if !ok {
+				other = true
+				continue
+			}
+			a.refs = append(a.refs, Ref{Path: resolved, Whole: whole, Optional: a.guarded(resolved), Line: a.refLine()})
+			lastRef, lastPath = len(a.refs)-1, resolved
+			paths = append(paths, resolved)
+		default:
+			if !unicode.IsSpace(rune(c)) {
+				other = true
+			}
+			i++
+		}
+	}
+	if len(paths) == 1 && !other {
+		return lastPath
+	}
+	return nil
+}
+
+// readPath reads a dotted path such as example.metadata.tone or list.0.
+func readPath(s string) ([]string, int) {
+	var path []string
+	i := 0
+	for {
+		seg := identPattern.FindString(s[i:])
+		if seg == "" {
+			j := i
+			for j < len(s) && unicode.IsDigit(rune(s[j])) {
+				j++
+			}
+			seg = s[i:j]
+		}
+		if seg == "" {
+			break
+		}
+		path = append(path, seg)
+		i += len(seg)
+		if i >= len(s) || s[i] != '.' {
+			break
+		}
+		i++
+	}
+	return path, i
+}
+
+// resolve maps a path's first name through the local scopes. ok is false
+// for names computed in the template.
+func (a *analyzer) resolve(path []string) ([]string, bool) {
+	for i := len(a.scopes) - 1; i >= 0; i-- {
+		if b, ok := a.scopes[i][path[0]]; ok {
+			if b == nil {
+				return nil, false
+			}
+			return appendPath(b, path[1:]...), true
+		}
+	}
+	return path, true
+}
+
+func (a *analyzer) push() {
+	a.scopes = append(a.scopes, map[string]binding{})
+}
+
+func (a *analyzer) bind(name string, b binding) {
+	if name != "" {
+		a.scopes[len(a.scopes)-1][name] = b
+	}
+}
+
+func appendPath(base []string, segs ...string) []string {
+	out := make([]string, 0, len(base)+len(segs))
+	return append(append(out, base...), segs...)
+}
+
+// formatPath renders a path as config keys, e.g. tasks[].title.
+func formatPath(path []string) string {
+	var b strings.Builder
+	for i, seg := range path {
+		switch {
+		case seg == eachElement:
+			b.WriteString("[]")
+		case isIndex(seg):
+			b.WriteString("[" + seg + "]")
+		default:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(seg)
+		}
+	}
+	return b.String()
+}
+
+func isIndex(seg string) bool {
+	_, err := strconv.Atoi(seg)
+	return err == nil
+}
+
+// Finding is a config path a template reads, located at a template line.
+type Finding struct {
+	Path string
+	Line int
+}
+
+// Check compares the paths the template reads with the values in ctx. It
+// returns the paths the template reads that ctx lacks, with list indices
+// filled in and in template order, and the sorted config keys no path
+// reads.
+func (a *Analysis) Check(ctx pongo2.Context) (missing, unused []string) {
+	for _, m := range a.Missing(ctx) {
+		missing = append(missing, m.Path)
+	}
+	return missing, a.Unused(ctx)
+}
+
+// Missing returns the paths the template reads that ctx lacks, in template
+// order. Paths covered by a default filter or an if tag are not required.
+func (a *Analysis) Missing(ctx pongo2.Context) []Finding {
+	var out []Finding
+	found := map[string]bool{}
+	for _, r := range a.Refs {
+		if r.Optional {
+			continue
+		}
+		for _, m := range missingPaths(map[string]any(ctx), r.Path, nil) {
+			if !found[m] {
+				found[m] = true
+				out = append(out, Finding{Path: m, Line: r.Line})
+			}
+		}
+	}
+	return out
+}
+
+// Unused returns the sorted config keys in ctx that no path reads.
+func (a *Analysis) Unused(ctx pongo2.Context) []string {
+	unused := a.unusedKeys(map[string]any(ctx), nil)
+	sort.Strings(unused)
+	return unused
+}
+
+// EmptyLoops returns the for-tag sources that are present in ctx but have
+// no items, so the loop renders nothing. Loops inside an if tag that tests
+// their source are expected to be skipped and are not reported.
+func (a *Analysis) EmptyLoops(ctx pongo2.Context) []Finding {
+	var out []Finding
+	found := map[string]bool{}
+	for _, r := range a.Refs {
+		if !r.Loop || r.Optional {
+			continue
+		}
+		for _, v := range valuesAt(map[string]any(ctx), r.Path, nil) {
+			if !found[v.path] && isEmptyCollection(v.value) {
+				found[v.path] = true
+				out = append(out, Finding{Path: v.path, Line: r.Line})
+			}
+		}
+	}
+	return out
+}
+
+// located is a value found in the config and where it was found.
+type located struct {
+	path  string
+	value any
+}
+
+// valuesAt returns every value path reaches in value.
+func valuesAt(value any, path, at []string) []located {
+	if len(path) == 0 {
+		return []located{{formatPath(at), value}}
+	}
+	seg, rest := path[0], path[1:]
+	var out []located
+	switch v := value.(type) {
+	case []any:
+		for i, elem := range v {
+			if seg == eachElement || seg == eachValue || seg == strconv.Itoa(i) {
+				out = append(out, valuesAt(elem, rest, appendPath(at, strconv.Itoa(i)))...)
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if seg == eachValue || seg == key {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			out = append(out, valuesAt(v[key], rest, appendPath(at, key))...)
+		}
+	}
+	return out
+}
+
+func isEmptyCollection(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// missingPaths walks path through value and returns where it breaks off.
+func missingPaths(value any, path, at []string) []string {
+	if len(path) == 0 {
+		return nil
+	}
+	seg, rest := path[0], path[1:]
+	switch v := value.(type) {
+	case []any:
+		if seg == eachElement || seg == eachValue {
+			var out []string
+			for i, elem := range v {
+				out = append(out, missingPaths(elem, rest, appendPath(at, strconv.Itoa(i)))...)
+			}
+			return out
+		}
+		if i, err := strconv.Atoi(seg); err == nil && i >= 0 && i < len(v) {
+			return missingPaths(v[i], rest, appendPath(at, seg))
+		}
+	case map[string]any:
+		if seg == eachValue {
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			var out []string
+			for _, key := range keys {
+				out = append(out, missingPaths(v[key], rest, appendPath(at, key))...)
+			}
+			return out
+		}
+		if elem, ok := v[seg]; ok {
+			return missingPaths(elem, rest, appendPath(at, seg))
+		}
+	}
+	return []string{formatPath(appendPath(at, seg))}
+}
+
+// unusedKeys lists the map keys under value that no ref reads.
+func (a *Analysis) unusedKeys(value any, at []string) []string {
+	var out []string
+	switch v := value.(type) {
+	case []any:
+		seen := map[string]bool{}
+		for _, elem := range v {
+			for _, key := range a.unusedKeys(elem, appendPath(at, eachElement)) {
+				if !seen[key] {
+					seen[key] = true
+					out = append(out, key)
+				}
+			}
+		}
+	case map[string]any:
+		for key, elem := range v {
+			path := appendPath(at, key)
+			switch a.usage(path) {
+			case unusedPath:
+				out = append(out, formatPath(path))
+			case partlyUsed:
+				out = append(out, a.unusedKeys(elem, path)...)
+			}
+		}
+	}
+	return out
+}
+
+type pathUsage int
+
+const (
+	unusedPath pathUsage = iota
+	partlyUsed
+	fullyUsed
+)
+
+// usage reports how the refs read the config key at path.
+func (a *Analysis) usage(path []string) pathUsage {
+	result := unusedPath
+	for _, r := range a.Refs {
+		switch {
+		case len(r.Path) <= len(path) && r.Whole && matchPrefix(path, r.Path):
+			return fullyUsed
+		case matchPrefix(r.Path, path):
+			// A deeper ref, or a loop source, reads only part of the value.
+			result = partlyUsed
+		}
+	}
+	return result
+}
+
+// matchPrefix reports whether prefix matches the start of path, letting
+// wildcards and list indices match one another.
+func matchPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i, seg := range prefix {
+		other := path[i]
+		if seg == other || seg == eachValue || other == eachValue {
+			continue
+		}
+		if (seg == eachElement || isIndex(seg)) && (other == eachElement || isIndex(other)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
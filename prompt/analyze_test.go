@@ -0,0 +1,86 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/flosch/pongo2/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeTemplate_Paths(t *testing.T) {
+	src := `{# comment {{ ignored }} #}
+{{ topic|upper }} for {{ audience|default:"everyone" }}
+{% for task in tasks %}- {{ task.title }}: {{ task.description|truncatechars:40 }} ({{ forloop.Counter }}){% endfor %}
+{% for keyword in example.metadata.intent_keywords %}{{ keyword }}{% endfor %}
+{% for name, spec in models %}{{ name }}={{ spec.context }}{% endfor %}
+{% with first=steps.0 %}{{ first.title }}{% endwith %}
+{% set label = "Query" %}{{ label }}: {{ user_query }}
+{% if tone and tone != "neutral" %}Tone: {{ tone }}{% endif %}
+{% comment %}{{ not_read }}{% endcomment %}`
+
+	a, err := AnalyzeTemplate(src)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"audience",
+		"example.metadata.intent_keywords",
+		"example.metadata.intent_keywords[]",
+		"models",
+		"models.*.context",
+		"steps[0]",
+		"steps[0].title",
+		"tasks",
+		"tasks[].description",
+		"tasks[].title",
+		"tone",
+		"topic",
+		"user_query",
+	}, a.Paths())
+}
+
+func TestAnalysis_Check(t *testing.T) {
+	src := `{{ topic }} {{ analogies|default:"none" }}
+{% for task in tasks %}{{ task.title }}{% if task.note %} ({{ task.note }}){% endif %}{% endfor %}
+{% for k in example.metadata.intent_keywords %}{{ k }}{% endfor %}{{ example.metadata.tone }}
+{{ settings }}`
+	a, err := AnalyzeTemplate(src)
+	require.NoError(t, err)
+
+	ctx := pongo2.Context{
+		"tasks": []any{
+			map[string]any{"title": "A", "description": "unused"},
+			map[string]any{"description": "no title"},
+		},
+		"example":  map[string]any{"metadata": map[string]any{"intent_keywords": []any{"x"}}},
+		"settings": map[string]any{"nested": map[string]any{"deep": 1}},
+		"extra":    "value",
+	}
+	missing, unused := a.Check(ctx)
+	assert.Equal(t, []string{"topic", "tasks[1].title", "example.metadata.tone"}, missing)
+	assert.Equal(t, []string{"extra", "tasks[].description"}, unused)
+}
+
+func TestAnalyzeTemplate_Errors(t *testing.T) {
+	_, err := AnalyzeTemplate(`{% for x items %}{% endfor %}`)
+	assert.ErrorContains(t, err, "invalid for tag")
+
+	_, err = AnalyzeTemplate(`{% set x %}`)
+	assert.ErrorContains(t, err, "invalid set tag")
+}
+
+func TestTemplate_CheckStrict(t *testing.T) {
+	tpl, err := ParseTemplate([]byte("system: You teach {{ subject }}.\nuser: Explain {{ topic }}.\n"))
+	require.NoError(t, err)
+
+	assert.NoError(t, tpl.CheckStrict(pongo2.Context{"subject": "networks", "topic": "BGP"}))
+
+	err = tpl.CheckStrict(pongo2.Context{"topic": "BGP", "tone": "dry"})
+	var strictErr *StrictError
+	require.ErrorAs(t, err, &strictErr)
+	assert.Equal(t, []string{"subject"}, strictErr.Missing)
+	assert.Equal(t, []string{"tone"}, strictErr.Unused)
+	assert.EqualError(t, err, "strict mode: missing from config: subject; unused by template: tone")
+
+	// The query is passed in by the caller, so a template may ignore it.
+	assert.NoError(t, tpl.CheckStrict(pongo2.Context{"subject": "networks", "topic": "BGP", QueryKey: "Why?"}))
+}
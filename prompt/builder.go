@@ -1,84 +1,239 @@
 package prompt
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"io/fs"
+	"path/filepath"
 
 	"github.com/flosch/pongo2/v6"
-	"gopkg.in/yaml.v3"
 	"raja.aiml/ai.explorer/logger"
-	"raja.aiml/ai.explorer/paths"
 )
 
 // Builder implements the Renderer interface using pongo2 and YAML config.
+// It is safe for concurrent use as long as its fields are not changed.
 type Builder struct {
-	ReadFile  func(path string) ([]byte, error)
-	WriteFile func(path string, data []byte, perm os.FileMode) error
-	Logger    logger.Logger
+	ReadFile func(path string) ([]byte, error)
+	Logger   logger.Logger
+	// TemplateRoot is where includes, extends and imports are looked up
+	// when they are not found next to the including template.
+	TemplateRoot string
+	// LoadSchema returns the config schema for a template, reading files
+	// with ReadFile, or nil when it has none. Configs are validated against
+	// it before rendering; its ignored keywords are logged as warnings.
+	LoadSchema func(readFile func(path string) ([]byte, error), templatePath string) (*Schema, error)
+	// Layers are merged over the config before validation.
+	Layers Layers
+	// Strict fails rendering when the template reads variables the config
+	// lacks or the config has keys the template never reads.
+	Strict bool
+	// LookupEnv resolves ${VAR} references in configs; it defaults to
+	// os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
 }
 
-// Ensure Builder satisfies Renderer interface.
+// Ensure Builder satisfies the Renderer interface.
 var _ Renderer = (*Builder)(nil)
 
 // --- Public API ---
 
-func (b *Builder) RenderToFile(templatePath, configPath, outputPath string, userQuery ...string) {
-	tpl := b.mustParseTemplate(templatePath)
-	ctx := b.mustParseConfig(configPath, userQuery...)
-	b.renderAndWrite(tpl, ctx, outputPath)
+// Render renders the request and returns the prompt.
+func (b *Builder) Render(ctx context.Context, req Request) (string, error) {
+	out, err := b.render(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
 }
 
-func (b *Builder) RenderToStdout(templatePath, configPath string, userQuery ...string) {
-	tpl := b.mustParseTemplate(templatePath)
-	ctx := b.mustParseConfig(configPath, userQuery...)
-
-	out, err := tpl.Execute(ctx)
+// RenderTo renders the request and writes the prompt to w. Nothing is
+// written when rendering fails.
+func (b *Builder) RenderTo(ctx context.Context, w io.Writer, req Request) error {
+	out, err := b.Render(ctx, req)
 	if err != nil {
-		b.Logger.Fatalf("template rendering failed: %v", err)
+		return err
+	}
+	if _, err := io.WriteString(w, out); err != nil {
+		return &WriteError{Err: err}
 	}
-	fmt.Println(out)
+	return nil
+}
+
+// RenderToString renders the template in memory and returns the result.
+func (b *Builder) RenderToString(templatePath, configPath string, userQuery ...string) (string, error) {
+	return b.Render(context.Background(), newRequest(templatePath, configPath, userQuery...))
+}
+
+// RenderSections renders the template in memory, keeping its system and
+// user sections apart.
+func (b *Builder) RenderSections(templatePath, configPath string, userQuery ...string) (Rendered, error) {
+	return b.render(context.Background(), newRequest(templatePath, configPath, userQuery...))
+}
+
+// LoadTemplate reads and parses a template file, including its metadata.
+func (b *Builder) LoadTemplate(path string) (*Template, error) {
+	return b.parseTemplate(path)
+}
+
+// LoadConfig loads a config the way rendering it with templatePath does:
+// layers merged, references resolved and the schema checked. Problems with
+// the config are returned as a *ConfigError wrapping an
+// *InterpolationError, a *SchemaError or a read or parse failure; a schema
+// that fails to load is returned as is.
+func (b *Builder) LoadConfig(templatePath, configPath string) (pongo2.Context, error) {
+	return b.loadConfig(Request{Template: templatePath, Config: configPath})
 }
 
 // --- Internal helpers ---
 
-func (b *Builder) mustParseTemplate(path string) *pongo2.Template {
-	data, err := b.ReadFile(path)
+func newRequest(templatePath, configPath string, userQuery ...string) Request {
+	req := Request{Template: templatePath, Config: configPath}
+	if len(userQuery) > 0 {
+		req.Query = userQuery[0]
+	}
+	return req
+}
+
+func (b *Builder) render(ctx context.Context, req Request) (Rendered, error) {
+	if err := ctx.Err(); err != nil {
+		return Rendered{}, err
+	}
+	tpl, err := b.parseTemplate(req.Template)
 	if err != nil {
-		b.Logger.Fatalf("failed to read template file: %v", err)
+		return Rendered{}, err
+	}
+	data, err := b.loadConfig(req)
+	if err != nil {
+		return Rendered{}, err
+	}
+	if err := b.checkStrict(tpl, data); err != nil {
+		return Rendered{}, &ConfigError{Path: req.Config, Err: err}
 	}
 
-	tpl, err := pongo2.FromString(string(data))
+	out, err := tpl.Render(data)
 	if err != nil {
-		b.Logger.Fatalf("failed to parse template: %v", err)
+		return Rendered{}, &RenderError{Path: req.Template, Err: err}
 	}
-	return tpl
+	return out, nil
 }
 
-func (b *Builder) mustParseConfig(path string, userQuery ...string) pongo2.Context {
+func (b *Builder) parseTemplate(path string) (*Template, error) {
 	data, err := b.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &TemplateNotFoundError{Path: path, Err: err}
+	}
 	if err != nil {
-		b.Logger.Fatalf("failed to read config file: %v", err)
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+	tpl, err := ParseTemplateIn(&Loader{Root: b.TemplateRoot, Origin: path, ReadFile: b.ReadFile}, data)
+	var perr *ParseError
+	if errors.As(err, &perr) && perr.File == "" {
+		perr.File = path
 	}
+	return tpl, err
+}
 
-	var parsed map[string]any
-	if err := yaml.Unmarshal(data, &parsed); err != nil {
-		b.Logger.Fatalf("failed to parse YAML config: %v", err)
+// checkStrict validates ctx against the template in strict mode.
+func (b *Builder) checkStrict(tpl *Template, ctx pongo2.Context) error {
+	if !b.Strict {
+		return nil
 	}
+	return tpl.CheckStrict(ctx)
+}
 
-	if len(userQuery) > 0 && userQuery[0] != "" {
-		parsed["user_query"] = userQuery[0]
+// loadConfig parses the config, merges the layers over it and validates
+// the result against the template's schema. The query is added after
+// validation, as it is not part of the config. Problems with the config
+// are returned as a *ConfigError.
+func (b *Builder) loadConfig(req Request) (pongo2.Context, error) {
+	data, err := b.readConfig(req.Config)
+	if err != nil {
+		return nil, &ConfigError{Path: req.Config, Err: err}
 	}
+	layers := b.Layers
+	if req.Vars != nil {
+		layers.Vars = req.Vars
+	}
+	if err := layers.apply(data, b.ReadFile, b.interpolate); err != nil {
+		return nil, &ConfigError{Path: req.Config, Err: err}
+	}
+	if err := b.validateConfig(req.Template, req.Config, data); err != nil {
+		return nil, err
+	}
+	return withQuery(data, req.Query), nil
+}
 
-	return pongo2.Context(parsed)
+// validateConfig checks ctx against the template's schema, if it has one.
+// A schema that fails to load is the template's problem, not the config's.
+func (b *Builder) validateConfig(templatePath, configPath string, ctx pongo2.Context) error {
+	if b.LoadSchema == nil {
+		return nil
+	}
+	schema, err := b.LoadSchema(b.ReadFile, templatePath)
+	if err != nil || schema == nil {
+		return err
+	}
+	if b.Logger != nil {
+		for _, k := range schema.Ignored {
+			b.Logger.Printf("warning: schema for %s: %s", templatePath, k)
+		}
+	}
+	if err := schema.Check(map[string]any(ctx)); err != nil {
+		return &ConfigError{Path: configPath, Err: fmt.Errorf("invalid config %s: %w", configPath, err)}
+	}
+	return nil
 }
 
-func (b *Builder) renderAndWrite(tpl *pongo2.Template, ctx pongo2.Context, outPath string) {
-	out, err := tpl.Execute(ctx)
+func (b *Builder) readConfig(path string) (pongo2.Context, error) {
+	data, err := b.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	parsed, err := decodeContext(data)
 	if err != nil {
-		b.Logger.Fatalf("template rendering failed: %v", err)
+		return nil, fmt.Errorf("failed to parse YAML config: %w", err)
+	}
+	if err := b.interpolate(path, parsed); err != nil {
+		return nil, err
 	}
-	paths.EnsureDirectoryExists(outPath)
-	if err := b.WriteFile(outPath, []byte(out), 0644); err != nil {
-		b.Logger.Fatalf("failed to write output file: %v", err)
+	return pongo2.Context(parsed), nil
+}
+
+// interpolate resolves ${VAR}, @file: and @glob: references in a config
+// read from path. Unset variables are an error in strict mode and a
+// warning otherwise.
+func (b *Builder) interpolate(path string, ctx map[string]any) error {
+	dir := filepath.Dir(path)
+	if path == "-" {
+		dir = ""
+	}
+	in := &Interpolator{Dir: dir, LookupEnv: b.LookupEnv, ReadFile: b.ReadFile}
+	missing, err := in.Interpolate(ctx)
+	if err != nil {
+		return &InterpolationError{Config: path, Err: err}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	missingErr := &MissingEnvError{Config: path, Names: missing}
+	if b.Strict {
+		return missingErr
+	}
+	if b.Logger != nil {
+		b.Logger.Printf("warning: %v", missingErr)
+	}
+	return nil
+}
+
+// QueryKey is the context key that holds the --query text.
+const QueryKey = "user_query"
+
+func withQuery(ctx pongo2.Context, userQuery ...string) pongo2.Context {
+	if len(userQuery) > 0 && userQuery[0] != "" {
+		ctx[QueryKey] = userQuery[0]
 	}
+	return ctx
 }
@@ -3,6 +3,7 @@ package prompt
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -34,131 +35,97 @@ func writeTempFile(t *testing.T, dir, name, content string) string {
 	return full
 }
 
-func assertPanics(t *testing.T, fn func(), msg string) {
-	t.Helper()
-	defer func() {
-		if r := recover(); r == nil {
-			t.Error(msg)
-		}
-	}()
-	fn()
-}
-
 // --- Tests: Internal helpers ---
 
-func Test_Builder_mustParseTemplate_Success(t *testing.T) {
-	logger := &fakeLogger{}
+func Test_Builder_parseTemplate_Success(t *testing.T) {
 	builder := &Builder{
 		ReadFile: func(string) ([]byte, error) { return []byte("Hi {{ name }}"), nil },
-		Logger:   logger,
+		Logger:   &fakeLogger{},
 	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			t.Fatalf("unexpected panic: %v", r)
-		}
-	}()
-
-	tpl := builder.mustParseTemplate("ok.tmpl")
+	tpl, err := builder.parseTemplate("ok.tmpl")
+	assert.NoError(t, err)
 	out, err := tpl.Execute(pongo2.Context{"name": "Go"})
 	assert.NoError(t, err)
 	assert.Equal(t, "Hi Go", out)
 }
 
-func Test_Builder_mustParseTemplate_ReadError(t *testing.T) {
+func Test_Builder_parseTemplate_ReadError(t *testing.T) {
 	builder := &Builder{
 		ReadFile: func(string) ([]byte, error) { return nil, errors.New("read error") },
 		Logger:   &fakeLogger{},
 	}
 
-	assertPanics(t, func() {
-		builder.mustParseTemplate("bad.txt")
-	}, "expected panic on template read error")
+	_, err := builder.parseTemplate("bad.txt")
+	assert.EqualError(t, err, "failed to read template file: read error")
 }
 
-func Test_Builder_mustParseTemplate_ParseError(t *testing.T) {
+func Test_Builder_parseTemplate_NotFound(t *testing.T) {
+	builder := &Builder{ReadFile: os.ReadFile, Logger: &fakeLogger{}}
+
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+	_, err := builder.parseTemplate(path)
+	var notFound *TemplateNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.Equal(t, path, notFound.Path)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.EqualError(t, err, "template not found: "+path)
+}
+
+func Test_Builder_parseTemplate_ParseError(t *testing.T) {
 	builder := &Builder{
-		ReadFile: func(string) ([]byte, error) { return []byte("{{ broken"), nil },
+		ReadFile: func(string) ([]byte, error) { return []byte("name: demo\nuser: |\n  Hi\n  {{ name|nofilter }}\n"), nil },
 		Logger:   &fakeLogger{},
 	}
 
-	assertPanics(t, func() {
-		builder.mustParseTemplate("bad.txt")
-	}, "expected panic on template parse error")
+	_, err := builder.parseTemplate("bad.yaml")
+	var perr *ParseError
+	assert.ErrorAs(t, err, &perr)
+	assert.Equal(t, "bad.yaml", perr.File)
+	assert.Equal(t, 4, perr.Line)
+	assert.Equal(t, 11, perr.Column, "the column counts the block's indentation")
 }
 
-func Test_Builder_mustParseConfig_Success(t *testing.T) {
+func Test_Builder_readConfig_Success(t *testing.T) {
 	builder := &Builder{
 		ReadFile: func(string) ([]byte, error) { return []byte("key: val"), nil },
 		Logger:   &fakeLogger{},
 	}
 
-	ctx := builder.mustParseConfig("ok.yaml")
+	ctx, err := builder.readConfig("ok.yaml")
+	assert.NoError(t, err)
 	assert.Equal(t, "val", ctx["key"])
 }
 
-func Test_Builder_mustParseConfig_ReadError(t *testing.T) {
+func Test_Builder_readConfig_ReadError(t *testing.T) {
 	builder := &Builder{
 		ReadFile: func(string) ([]byte, error) { return nil, errors.New("read fail") },
 		Logger:   &fakeLogger{},
 	}
 
-	assertPanics(t, func() {
-		builder.mustParseConfig("bad.yaml")
-	}, "expected panic on config read error")
+	_, err := builder.readConfig("bad.yaml")
+	assert.EqualError(t, err, "failed to read config file: read fail")
 }
 
-func Test_Builder_mustParseConfig_ParseError(t *testing.T) {
+func Test_Builder_readConfig_ParseError(t *testing.T) {
 	builder := &Builder{
 		ReadFile: func(string) ([]byte, error) { return []byte("invalid: ["), nil },
 		Logger:   &fakeLogger{},
 	}
 
-	assertPanics(t, func() {
-		builder.mustParseConfig("broken.yaml")
-	}, "expected panic on YAML parse error")
+	_, err := builder.readConfig("broken.yaml")
+	assert.ErrorContains(t, err, "failed to parse YAML config")
 }
 
-func Test_Builder_renderAndWrite_Success(t *testing.T) {
-	var captured []byte
-	tpl, _ := pongo2.FromString(`{{ foo }}!`)
-
+func Test_Builder_loadConfig_ReturnsConfigError(t *testing.T) {
 	builder := &Builder{
-		WriteFile: func(_ string, data []byte, _ os.FileMode) error {
-			captured = data
-			return nil
-		},
-		Logger: &fakeLogger{},
-	}
-
-	builder.renderAndWrite(tpl, pongo2.Context{"foo": "Yo"}, "out.txt")
-	assert.Equal(t, "Yo!", string(captured))
-}
-
-func Test_Builder_renderAndWrite_RenderError(t *testing.T) {
-	tpl, _ := pongo2.FromString(`{{ 'fail'|nonexistent }}`)
-
-	builder := &Builder{
-		WriteFile: os.WriteFile,
-		Logger:    &fakeLogger{},
-	}
-
-	assertPanics(t, func() {
-		builder.renderAndWrite(tpl, pongo2.Context{}, "ignored.txt")
-	}, "expected panic on render failure")
-}
-
-func Test_Builder_renderAndWrite_WriteError(t *testing.T) {
-	tpl, _ := pongo2.FromString(`OK`)
-
-	builder := &Builder{
-		WriteFile: func(string, []byte, os.FileMode) error {
-			return errors.New("disk full")
-		},
-		Logger: &fakeLogger{},
+		ReadFile: func(string) ([]byte, error) { return []byte("invalid: ["), nil },
+		Logger:   &fakeLogger{},
 	}
 
-	assertPanics(t, func() {
-		builder.renderAndWrite(tpl, pongo2.Context{}, "fail.txt")
-	}, "expected panic on write error")
+	_, err := builder.loadConfig(Request{Template: "t.yaml", Config: "broken.yaml"})
+	var cfgErr *ConfigError
+	assert.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, "broken.yaml", cfgErr.Path)
+	assert.ErrorContains(t, err, "failed to parse YAML config")
 }
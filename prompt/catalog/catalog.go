@@ -0,0 +1,158 @@
+// Package catalog finds the categories and topics under a resources tree,
+// laid out as <root>/<category>/<topic>/config.yaml.
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+// Topic is a config and the template it renders with.
+type Topic struct {
+	Category string
+	Name     string
+	Dir      string
+	// Template is the topic's own template.yaml, or its category's. It is
+	// empty when neither exists.
+	Template string
+	Config   string
+	// Schema is the schema next to Template, if any.
+	Schema string
+	Output string
+
+	// Spec is the template's metadata; TemplateErr is set when the
+	// template could not be read or parsed.
+	Spec        prompt.TemplateSpec
+	TemplateErr error
+	// ConfigSize is the config file's size in bytes, and ConfigKeys its
+	// number of top-level keys.
+	ConfigSize int64
+	ConfigKeys int
+	// LastRender is when Output was last written, or zero if never.
+	LastRender time.Time
+}
+
+// ID returns the topic as category/topic.
+func (t Topic) ID() string {
+	return t.Category + "/" + t.Name
+}
+
+// Scan lists every topic under root, sorted by category and name.
+func Scan(root string) ([]Topic, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("failed to read resources: %w", err)
+	}
+	configs, err := filepath.Glob(filepath.Join(root, "*", "*", "config.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(configs)
+
+	topics := make([]Topic, 0, len(configs))
+	for _, cfg := range configs {
+		dir := filepath.Dir(cfg)
+		t := Topic{
+			Category: filepath.Base(filepath.Dir(dir)),
+			Name:     filepath.Base(dir),
+			Dir:      dir,
+			Config:   cfg,
+			Output:   filepath.Join(dir, "prompt.txt"),
+		}
+		t.Template = templateFor(dir)
+		if t.Template != "" {
+			t.Schema, _ = prompt.SchemaPathFor(os.ReadFile, t.Template)
+			t.Spec, t.TemplateErr = readSpec(root, t.Template)
+		}
+		if info, err := os.Stat(cfg); err == nil {
+			t.ConfigSize = info.Size()
+		}
+		t.ConfigKeys = countKeys(cfg)
+		if info, err := os.Stat(t.Output); err == nil {
+			t.LastRender = info.ModTime()
+		}
+		topics = append(topics, t)
+	}
+	return topics, nil
+}
+
+// templateFor returns the topic's template, falling back to its category's.
+func templateFor(dir string) string {
+	for _, path := range []string{
+		filepath.Join(dir, "template.yaml"),
+		filepath.Join(filepath.Dir(dir), "template.yaml"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func readSpec(root, path string) (prompt.TemplateSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return prompt.TemplateSpec{}, err
+	}
+	tpl, err := prompt.ParseTemplateIn(&prompt.Loader{Root: root, Origin: path}, data)
+	if err != nil {
+		return prompt.TemplateSpec{}, err
+	}
+	return tpl.Spec, nil
+}
+
+// countKeys returns the number of top-level keys in a YAML config, or 0 if
+// it cannot be parsed.
+func countKeys(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	var parsed map[string]any
+	if yaml.Unmarshal(data, &parsed) != nil {
+		return 0
+	}
+	return len(parsed)
+}
+
+// Categories returns the distinct categories of topics, sorted.
+func Categories(topics []Topic) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, t := range topics {
+		if !seen[t.Category] {
+			seen[t.Category] = true
+			out = append(out, t.Category)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// InCategory returns the topics of one category.
+func InCategory(topics []Topic, category string) []Topic {
+	var out []Topic
+	for _, t := range topics {
+		if t.Category == category {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Find returns the topic with the given category/topic ID.
+func Find(topics []Topic, id string) (Topic, error) {
+	id = strings.Trim(id, "/")
+	for _, t := range topics {
+		if t.ID() == id {
+			return t, nil
+		}
+	}
+	return Topic{}, fmt.Errorf("unknown topic %q (run `ai-explorer prompt list`)", id)
+}
@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return root
+}
+
+func TestScan(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"topics/template.yaml":           "name: explainer\nversion: 2.0.0\ntemplate: Explain {{ topic }}\n",
+		"topics/schema.yaml":             "type: object\n",
+		"topics/git/config.yaml":         "topic: Git\naudience: students\n",
+		"topics/git/prompt.txt":          "Explain Git",
+		"topics/bgp/config.yaml":         "topic: BGP\n",
+		"router/main/template.yaml":      "recommended: {provider: ollama, model: phi4}\ntemplate: '{{ user_query }}'\n",
+		"router/main/config.yaml":        "{}\n",
+		"broken/x/template.yaml":         "{{ oops",
+		"broken/x/config.yaml":           "a: 1\n",
+		"orphan/y/config.yaml":           "a: 1\n",
+		"partials/constraints.tmpl":      "shared",
+		"codegen/evaluator/instructions": "not a topic",
+	})
+
+	topics, err := Scan(root)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, topic := range topics {
+		ids = append(ids, topic.ID())
+	}
+	assert.Equal(t, []string{"broken/x", "orphan/y", "router/main", "topics/bgp", "topics/git"}, ids)
+	assert.Equal(t, []string{"broken", "orphan", "router", "topics"}, Categories(topics))
+
+	git, err := Find(topics, "topics/git/")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "topics/template.yaml"), git.Template)
+	assert.Equal(t, filepath.Join(root, "topics/schema.yaml"), git.Schema)
+	assert.Equal(t, filepath.Join(root, "topics/git/prompt.txt"), git.Output)
+	assert.Equal(t, "explainer", git.Spec.Name)
+	assert.Equal(t, "2.0.0", git.Spec.Version)
+	assert.Equal(t, int64(30), git.ConfigSize)
+	assert.Equal(t, 2, git.ConfigKeys)
+	assert.False(t, git.LastRender.IsZero())
+
+	bgp, _ := Find(topics, "topics/bgp")
+	assert.True(t, bgp.LastRender.IsZero())
+
+	router, _ := Find(topics, "router/main")
+	assert.Equal(t, "phi4", router.Spec.Recommended.Model)
+	assert.Empty(t, router.Schema)
+
+	broken, _ := Find(topics, "broken/x")
+	assert.Error(t, broken.TemplateErr)
+	orphan, _ := Find(topics, "orphan/y")
+	assert.Empty(t, orphan.Template)
+
+	assert.Len(t, InCategory(topics, "topics"), 2)
+
+	_, err = Find(topics, "topics/nope")
+	assert.EqualError(t, err, "unknown topic \"topics/nope\" (run `ai-explorer prompt list`)")
+}
+
+func TestScan_MissingRoot(t *testing.T) {
+	_, err := Scan(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "failed to read resources")
+}
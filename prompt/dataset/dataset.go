@@ -0,0 +1,123 @@
+// Package dataset renders a template once per row of a CSV or JSONL file,
+// with each row's columns as variables on top of the base config.
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Row is one record of a dataset.
+type Row struct {
+	// Index is the 1-based row number, not counting a CSV header.
+	Index int
+	// Vars are the row's columns. CSV values are strings; JSONL values
+	// keep their types and may nest.
+	Vars map[string]any
+	// Err is set when the row could not be read. Such rows are reported
+	// in the manifest instead of aborting the run.
+	Err error
+}
+
+// Load reads a dataset file, choosing the format by extension: .jsonl,
+// .ndjson and .json are read as JSON lines, anything else as CSV.
+func Load(path string) ([]Row, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return ReadJSONL(f)
+	}
+	return ReadCSV(f)
+}
+
+// ReadCSV reads rows from a CSV file whose first line names the columns.
+// Rows with the wrong number of fields are returned with Err set.
+func ReadCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("dataset is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset header: %w", err)
+	}
+	for i, col := range header {
+		header[i] = strings.TrimSpace(col)
+		if header[i] == "" {
+			return nil, fmt.Errorf("dataset header: column %d has no name", i+1)
+		}
+	}
+
+	var rows []Row
+	for index := 1; ; index++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		row := Row{Index: index}
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			row.Err = fmt.Errorf("invalid CSV: %w", parseErr.Err)
+		case err != nil:
+			return nil, fmt.Errorf("failed to read dataset row %d: %w", index, err)
+		case len(record) != len(header):
+			row.Err = fmt.Errorf("expected %d fields, got %d", len(header), len(record))
+		default:
+			row.Vars = make(map[string]any, len(header))
+			for i, col := range header {
+				row.Vars[col] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ReadJSONL reads one JSON object per line. Blank lines are skipped and do
+// not count as rows; lines that are not objects are returned with Err set.
+func ReadJSONL(r io.Reader) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var rows []Row
+	for index := 1; scanner.Scan(); {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		// Decoding as YAML, as --vars-file does, keeps whole numbers ints.
+		row := Row{Index: index}
+		var vars map[string]any
+		switch err := yaml.Unmarshal([]byte(text), &vars); {
+		case err != nil:
+			row.Err = fmt.Errorf("invalid JSON object: %w", err)
+		case vars == nil:
+			row.Err = errors.New("invalid JSON object: null")
+		default:
+			row.Vars = vars
+		}
+		rows = append(rows, row)
+		index++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,74 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV(t *testing.T) {
+	rows, err := ReadCSV(strings.NewReader(`id, query ,tone
+a,"What is BGP?",dry
+b,"Too", many, fields
+c,"Explain ""DNS""",
+`))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, Row{Index: 1, Vars: map[string]any{"id": "a", "query": "What is BGP?", "tone": "dry"}}, rows[0])
+	assert.Equal(t, 2, rows[1].Index)
+	assert.EqualError(t, rows[1].Err, "expected 3 fields, got 4")
+	assert.Equal(t, map[string]any{"id": "c", "query": `Explain "DNS"`, "tone": ""}, rows[2].Vars)
+}
+
+func TestReadCSV_Errors(t *testing.T) {
+	_, err := ReadCSV(strings.NewReader(""))
+	assert.EqualError(t, err, "dataset is empty")
+
+	_, err = ReadCSV(strings.NewReader("id,,tone\n"))
+	assert.EqualError(t, err, "dataset header: column 2 has no name")
+
+	rows, err := ReadCSV(strings.NewReader("id,query\na,\"unterminated\nb,ok\n"))
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+	assert.ErrorContains(t, rows[0].Err, "invalid CSV")
+}
+
+func TestReadJSONL(t *testing.T) {
+	rows, err := ReadJSONL(strings.NewReader(`{"id": "a", "n": 3, "meta": {"tone": "dry"}}
+
+[1, 2]
+null
+{"id": "d"}
+`))
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, Row{Index: 1, Vars: map[string]any{"id": "a", "n": 3, "meta": map[string]any{"tone": "dry"}}}, rows[0])
+	assert.ErrorContains(t, rows[1].Err, "invalid JSON object")
+	assert.EqualError(t, rows[2].Err, "invalid JSON object: null")
+	assert.Equal(t, Row{Index: 4, Vars: map[string]any{"id": "d"}}, rows[3])
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "data.csv")
+	jsonlPath := filepath.Join(dir, "data.jsonl")
+	require.NoError(t, os.WriteFile(csvPath, []byte("query\nWhat is BGP?\n"), 0644))
+	require.NoError(t, os.WriteFile(jsonlPath, []byte(`{"query": "What is DNS?"}`+"\n"), 0644))
+
+	rows, err := Load(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "What is BGP?", rows[0].Vars["query"])
+
+	rows, err = Load(jsonlPath)
+	require.NoError(t, err)
+	assert.Equal(t, "What is DNS?", rows[0].Vars["query"])
+
+	_, err = Load(filepath.Join(dir, "missing.csv"))
+	assert.ErrorContains(t, err, "failed to open dataset")
+}
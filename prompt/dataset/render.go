@@ -0,0 +1,190 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// Manifest statuses.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// DefaultConcurrency is the worker count used when none is given.
+const DefaultConcurrency = 4
+
+// ManifestFile is the manifest's name in the output directory.
+const ManifestFile = "manifest.jsonl"
+
+// Entry is one line of the manifest.
+type Entry struct {
+	Row    int    `json:"row"`
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Renderer renders one prompt file per row into OutputDir.
+type Renderer struct {
+	OutputDir string
+	// IDColumn names each row's file. Rows without a value in it are
+	// named by their index.
+	IDColumn string
+	// QueryColumn is passed as the user query when a row has it.
+	QueryColumn string
+	Concurrency int
+	// Render renders the template with vars over the config.
+	Render    func(vars map[string]any, userQuery string) (string, error)
+	WriteFile func(path string, data []byte, perm os.FileMode) error
+}
+
+// job is a row and the file it renders to.
+type job struct {
+	pos  int
+	row  Row
+	id   string
+	path string
+}
+
+// Run renders every row in parallel and returns the manifest entries in
+// row order. A failing row gets an error entry; only a cancelled context
+// stops the run.
+func (r *Renderer) Run(ctx context.Context, rows []Row) ([]Entry, error) {
+	entries := make([]Entry, len(rows))
+	var jobs []job
+	for i, j := range r.plan(rows) {
+		entries[i] = Entry{Row: j.row.Index, ID: j.id}
+		switch {
+		case j.row.Err != nil:
+			entries[i].fail(j.row.Err)
+		case j.path == "":
+			entries[i].fail(fmt.Errorf("duplicate id %q", j.id))
+		default:
+			jobs = append(jobs, j)
+		}
+	}
+
+	workers := r.Workers()
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	queue := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				// Each job owns its entry, so no lock is needed.
+				r.render(j, &entries[j.pos])
+			}
+		}()
+	}
+
+feed:
+	for _, j := range jobs {
+		select {
+		case queue <- j:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Workers returns the configured pool size, falling back to DefaultConcurrency.
+func (r *Renderer) Workers() int {
+	if r.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return r.Concurrency
+}
+
+// unsafeName matches characters not kept in file names built from ids.
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// plan names each row's output file. Rows whose id repeats an earlier
+// row's file name get an empty path.
+func (r *Renderer) plan(rows []Row) []job {
+	width := len(strconv.Itoa(len(rows)))
+	seen := map[string]bool{}
+	jobs := make([]job, len(rows))
+	for i, row := range rows {
+		id := ""
+		if v, ok := row.Vars[r.IDColumn]; ok && r.IDColumn != "" && v != nil {
+			id = fmt.Sprint(v)
+		}
+		name := unsafeName.ReplaceAllString(id, "_")
+		if name == "" || name == "." || name == ".." {
+			id = fmt.Sprintf("%0*d", width, row.Index)
+			name = id
+		}
+
+		jobs[i] = job{pos: i, row: row, id: id}
+		if !seen[name] {
+			seen[name] = true
+			jobs[i].path = filepath.Join(r.OutputDir, name+".txt")
+		}
+	}
+	return jobs
+}
+
+func (r *Renderer) render(j job, entry *Entry) {
+	query := ""
+	if v, ok := j.row.Vars[r.QueryColumn]; ok && r.QueryColumn != "" && v != nil {
+		query = fmt.Sprint(v)
+	}
+	out, err := r.Render(j.row.Vars, query)
+	if err != nil {
+		entry.fail(err)
+		return
+	}
+	if err := r.WriteFile(j.path, []byte(out), 0644); err != nil {
+		entry.fail(fmt.Errorf("failed to write output: %w", err))
+		return
+	}
+	entry.Status = StatusOK
+	entry.Output = j.path
+}
+
+func (e *Entry) fail(err error) {
+	e.Status = StatusError
+	e.Error = err.Error()
+}
+
+// Failed returns the number of entries that failed.
+func Failed(entries []Entry) int {
+	n := 0
+	for _, e := range entries {
+		if e.Status != StatusOK {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteManifest writes entries as JSON lines.
+func WriteManifest(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRenderer(dir string) *Renderer {
+	return &Renderer{
+		OutputDir:   dir,
+		IDColumn:    "id",
+		QueryColumn: "query",
+		Concurrency: 3,
+		Render: func(vars map[string]any, query string) (string, error) {
+			if vars["fail"] == true {
+				return "", errors.New("template rendering failed: boom")
+			}
+			return "Q: " + query, nil
+		},
+		WriteFile: os.WriteFile,
+	}
+}
+
+func TestRenderer_Run(t *testing.T) {
+	dir := t.TempDir()
+	rows := make([]Row, 0, 12)
+	rows = append(rows,
+		Row{Index: 1, Vars: map[string]any{"id": "bgp", "query": "What is BGP?"}},
+		Row{Index: 2, Vars: map[string]any{"query": "What is DNS?"}},
+		Row{Index: 3, Vars: map[string]any{"id": "bgp"}},
+		Row{Index: 4, Vars: map[string]any{"id": "x", "fail": true}},
+		Row{Index: 5, Err: errors.New("expected 2 fields, got 3")},
+		Row{Index: 6, Vars: map[string]any{"id": "a/../b c", "query": 42}},
+	)
+	for i := 7; i <= 12; i++ {
+		rows = append(rows, Row{Index: i, Vars: map[string]any{}})
+	}
+
+	entries, err := newRenderer(dir).Run(context.Background(), rows)
+	require.NoError(t, err)
+	require.Len(t, entries, 12)
+
+	assert.Equal(t, Entry{Row: 1, ID: "bgp", Status: StatusOK, Output: filepath.Join(dir, "bgp.txt")}, entries[0])
+	assert.Equal(t, Entry{Row: 2, ID: "02", Status: StatusOK, Output: filepath.Join(dir, "02.txt")}, entries[1])
+	assert.Equal(t, Entry{Row: 3, ID: "bgp", Status: StatusError, Error: `duplicate id "bgp"`}, entries[2])
+	assert.Equal(t, Entry{Row: 4, ID: "x", Status: StatusError, Error: "template rendering failed: boom"}, entries[3])
+	assert.Equal(t, Entry{Row: 5, ID: "05", Status: StatusError, Error: "expected 2 fields, got 3"}, entries[4])
+	assert.Equal(t, filepath.Join(dir, "a_.._b_c.txt"), entries[5].Output)
+	assert.Equal(t, 3, Failed(entries))
+
+	data, err := os.ReadFile(filepath.Join(dir, "bgp.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "Q: What is BGP?", string(data))
+	data, err = os.ReadFile(entries[5].Output)
+	require.NoError(t, err)
+	assert.Equal(t, "Q: 42", string(data))
+}
+
+func TestRenderer_RunsInParallel(t *testing.T) {
+	r := newRenderer(t.TempDir())
+	var (
+		mu      sync.Mutex
+		active  int
+		peak    int
+		once    sync.Once
+		release = make(chan struct{})
+	)
+	r.Render = func(map[string]any, string) (string, error) {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		if active == r.Concurrency {
+			once.Do(func() { close(release) })
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return "", nil
+	}
+
+	rows := []Row{{Index: 1}, {Index: 2}, {Index: 3}, {Index: 4}}
+	entries, err := r.Run(context.Background(), rows)
+	require.NoError(t, err)
+	assert.Equal(t, 0, Failed(entries))
+	assert.Equal(t, 3, peak)
+}
+
+func TestRenderer_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := newRenderer(t.TempDir()).Run(ctx, []Row{{Index: 1}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWriteManifest(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteManifest(&buf, []Entry{
+		{Row: 1, ID: "bgp", Status: StatusOK, Output: "out/bgp.txt"},
+		{Row: 2, ID: "2", Status: StatusError, Error: "boom"},
+	}))
+	assert.Equal(t, `{"row":1,"id":"bgp","status":"ok","output":"out/bgp.txt"}
+{"row":2,"id":"2","status":"error","error":"boom"}
+`, buf.String())
+}
@@ -0,0 +1,180 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Config values may reference the environment and other files:
+//
+//	${TEAM_NAME}            the variable's value
+//	${MODEL:-phi4}          the value, or phi4 when unset or empty
+//	@file:docs/guide.md     the file's content
+//	@glob:examples/*.md     a list with the content of each match, by path
+//
+// File directives must be the whole value. Paths are relative to the
+// config's directory and may themselves use ${...}. Included content is
+// used as is. $${ and @@ escape a literal ${ or leading @.
+const (
+	fileDirective = "@file:"
+	globDirective = "@glob:"
+)
+
+var envReference = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// Interpolator resolves references in config values.
+type Interpolator struct {
+	// Dir is the directory relative paths are resolved against.
+	Dir       string
+	LookupEnv func(key string) (string, bool)
+	ReadFile  func(path string) ([]byte, error)
+	// Glob lists the paths matching a pattern; it defaults to filepath.Glob.
+	Glob func(pattern string) ([]string, error)
+
+	missing map[string]bool
+}
+
+// Interpolate resolves every string value in ctx in place. It returns the
+// names of referenced environment variables that are unset and have no
+// default, sorted; they resolve to "". Unreadable files are errors.
+func (in *Interpolator) Interpolate(ctx map[string]any) ([]string, error) {
+	in.missing = map[string]bool{}
+	if _, err := in.value("", ctx); err != nil {
+		return nil, err
+	}
+	missing := make([]string, 0, len(in.missing))
+	for name := range in.missing {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+func (in *Interpolator) value(path string, v any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return in.resolve(path, v)
+	case []any:
+		for i, item := range v {
+			resolved, err := in.value(fmt.Sprintf("%s[%d]", path, i), item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			resolved, err := in.value(joinKey(path, k), v[k])
+			if err != nil {
+				return nil, err
+			}
+			v[k] = resolved
+		}
+	}
+	return v, nil
+}
+
+func (in *Interpolator) resolve(path, s string) (any, error) {
+	switch {
+	case strings.HasPrefix(s, "@@"):
+		return in.expandEnv(s[1:]), nil
+	case strings.HasPrefix(s, fileDirective):
+		file := in.path(in.expandEnv(strings.TrimPrefix(s, fileDirective)))
+		data, err := in.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to include %s: %w", path, file, err)
+		}
+		return string(data), nil
+	case strings.HasPrefix(s, globDirective):
+		pattern := in.path(in.expandEnv(strings.TrimPrefix(s, globDirective)))
+		glob := in.Glob
+		if glob == nil {
+			glob = filepath.Glob
+		}
+		matches, err := glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid glob %s: %w", path, pattern, err)
+		}
+		sort.Strings(matches)
+		contents := make([]any, 0, len(matches))
+		for _, m := range matches {
+			data, err := in.ReadFile(m)
+			if err != nil {
+				return nil, fmt.Errorf("%s: failed to include %s: %w", path, m, err)
+			}
+			contents = append(contents, string(data))
+		}
+		return contents, nil
+	}
+	return in.expandEnv(s), nil
+}
+
+// expandEnv replaces ${VAR} and ${VAR:-default} references in s.
+func (in *Interpolator) expandEnv(s string) string {
+	return envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		m := envReference.FindStringSubmatch(ref)
+		name, fallback, hasDefault := m[1], m[2], strings.Contains(ref, ":-")
+		value, ok := in.lookupEnv(name)
+		switch {
+		case ok && value != "":
+			return value
+		case hasDefault:
+			return fallback
+		case !ok:
+			in.missing[name] = true
+		}
+		return value
+	})
+}
+
+func (in *Interpolator) lookupEnv(name string) (string, bool) {
+	if in.LookupEnv == nil {
+		return os.LookupEnv(name)
+	}
+	return in.LookupEnv(name)
+}
+
+func (in *Interpolator) path(p string) string {
+	if filepath.IsAbs(p) || in.Dir == "" {
+		return p
+	}
+	return filepath.Join(in.Dir, p)
+}
+
+// InterpolationError is a config reference that could not be resolved,
+// such as an @file: include of a missing file.
+type InterpolationError struct {
+	Config string
+	Err    error
+}
+
+func (e *InterpolationError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Config, e.Err)
+}
+
+func (e *InterpolationError) Unwrap() error {
+	return e.Err
+}
+
+// MissingEnvError reports environment variables a config references that
+// are not set.
+type MissingEnvError struct {
+	Config string
+	Names  []string
+}
+
+func (e *MissingEnvError) Error() string {
+	return fmt.Sprintf("%s: environment variables not set: %s", e.Config, strings.Join(e.Names, ", "))
+}
@@ -0,0 +1,85 @@
+package prompt
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInterpolator(env, files map[string]string) *Interpolator {
+	return &Interpolator{
+		Dir: "cfg",
+		LookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+		ReadFile: func(path string) ([]byte, error) {
+			if data, ok := files[path]; ok {
+				return []byte(data), nil
+			}
+			return nil, errors.New("no such file")
+		},
+		Glob: func(pattern string) ([]string, error) {
+			var out []string
+			for path := range files {
+				if ok, _ := filepath.Match(pattern, path); ok {
+					out = append(out, path)
+				}
+			}
+			return out, nil
+		},
+	}
+}
+
+func TestInterpolator_Interpolate(t *testing.T) {
+	in := newTestInterpolator(
+		map[string]string{"TEAM_NAME": "SRE", "EMPTY": "", "DOCS": "docs"},
+		map[string]string{
+			"cfg/docs/style.md":      "Be brief.",
+			"cfg/examples/b.md":      "second",
+			"cfg/examples/a.md":      "first",
+			"/abs/instructions.txt":  "Evaluate the code.",
+			"cfg/examples/notes.txt": "ignored",
+		},
+	)
+	ctx := map[string]any{
+		"team":         "${TEAM_NAME}",
+		"greeting":     "Hello ${TEAM_NAME}, using ${MODEL:-phi4}",
+		"empty":        "${EMPTY:-fallback}",
+		"style":        "@file:${DOCS}/style.md",
+		"instructions": "@file:/abs/instructions.txt",
+		"examples":     "@glob:examples/*.md",
+		"nested":       map[string]any{"list": []any{"${TEAM_NAME}", 3, "$${LITERAL}"}},
+		"handle":       "@@file:not-a-file",
+		"unset":        "[${NOPE}]",
+	}
+
+	missing, err := in.Interpolate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NOPE"}, missing)
+	assert.Equal(t, map[string]any{
+		"team":         "SRE",
+		"greeting":     "Hello SRE, using phi4",
+		"empty":        "fallback",
+		"style":        "Be brief.",
+		"instructions": "Evaluate the code.",
+		"examples":     []any{"first", "second"},
+		"nested":       map[string]any{"list": []any{"SRE", 3, "${LITERAL}"}},
+		"handle":       "@file:not-a-file",
+		"unset":        "[]",
+	}, ctx)
+}
+
+func TestInterpolator_Errors(t *testing.T) {
+	in := newTestInterpolator(nil, nil)
+
+	_, err := in.Interpolate(map[string]any{"a": map[string]any{"b": []any{"@file:gone.md"}}})
+	assert.EqualError(t, err, "a.b[0]: failed to include cfg/gone.md: no such file")
+
+	in.Glob = func(string) ([]string, error) { return nil, filepath.ErrBadPattern }
+	_, err = in.Interpolate(map[string]any{"x": "@glob:["})
+	assert.ErrorContains(t, err, "x: invalid glob cfg/[")
+}
@@ -0,0 +1,244 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layers stacks more context on top of the primary config, applied in
+// field order: each of Configs, then VarsFile, then Vars, then every Set.
+type Layers struct {
+	// Configs are further YAML configs, deep-merged in order.
+	Configs []string
+	// VarsFile is a JSON or YAML context merged over the configs. "-"
+	// reads it from Stdin.
+	VarsFile string
+	Stdin    io.Reader
+	// Vars is a context merged over VarsFile, such as a dataset row. Its
+	// values are used as is, without interpolation.
+	Vars map[string]any
+	// Sets are key.path=value overrides such as tone=formal or
+	// concepts[0]=Branches. Numbers, booleans and flow collections are
+	// parsed as YAML, so `n=3` sets a number and `tags=[a, b]` a list;
+	// anything else is a string.
+	Sets []string
+}
+
+// IsZero reports whether no layers are set.
+func (l Layers) IsZero() bool {
+	return len(l.Configs) == 0 && l.VarsFile == "" && len(l.Vars) == 0 && len(l.Sets) == 0
+}
+
+// apply merges the layers into ctx, reading files with readFile. Each
+// decoded file is passed to expand along with its path.
+func (l Layers) apply(ctx map[string]any, readFile func(string) ([]byte, error), expand func(string, map[string]any) error) error {
+	for _, path := range l.Configs {
+		data, err := readFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		layer, err := decodeContext(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse YAML config %s: %w", path, err)
+		}
+		if err := expand(path, layer); err != nil {
+			return err
+		}
+		MergeContext(ctx, layer)
+	}
+
+	if l.VarsFile != "" {
+		data, err := l.readVars(readFile)
+		if err != nil {
+			return err
+		}
+		vars, err := decodeContext(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse vars file %s: %w", l.VarsFile, err)
+		}
+		if err := expand(l.VarsFile, vars); err != nil {
+			return err
+		}
+		MergeContext(ctx, vars)
+	}
+
+	if len(l.Vars) > 0 {
+		MergeContext(ctx, l.Vars)
+	}
+
+	for _, expr := range l.Sets {
+		if err := SetValue(ctx, expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l Layers) readVars(readFile func(string) ([]byte, error)) ([]byte, error) {
+	if l.VarsFile != "-" {
+		data, err := readFile(l.VarsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vars file: %w", err)
+		}
+		return data, nil
+	}
+	if l.Stdin == nil {
+		return nil, errors.New("failed to read vars from stdin: no input")
+	}
+	data, err := io.ReadAll(l.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vars from stdin: %w", err)
+	}
+	return data, nil
+}
+
+// decodeContext parses a JSON or YAML mapping. An empty document is an
+// empty context.
+func decodeContext(data []byte) (map[string]any, error) {
+	var parsed map[string]any
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+	if parsed == nil {
+		parsed = map[string]any{}
+	}
+	return parsed, nil
+}
+
+// MergeContext deep-merges src into dst. Mappings merge key by key; lists
+// and scalars in src replace those in dst.
+func MergeContext(dst, src map[string]any) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]any)
+		dstMap, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			MergeContext(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+var setSegment = regexp.MustCompile(`^([^.\[\]]+)|^\.([^.\[\]]+)|^\[(\d+)\]`)
+
+// setStep is one segment of a --set path: a mapping key or a list index.
+type setStep struct {
+	key   string
+	index int
+}
+
+func (s setStep) isIndex() bool { return s.key == "" }
+
+// parseSetPath splits a path such as a.b[2].c into steps.
+func parseSetPath(path string) ([]setStep, error) {
+	var steps []setStep
+	for rest := path; rest != ""; {
+		m := setSegment.FindStringSubmatch(rest)
+		// Only the first segment may omit its leading dot or bracket.
+		if m == nil || (len(steps) == 0) != (m[1] != "") {
+			return nil, fmt.Errorf("invalid --set path %q", path)
+		}
+		switch {
+		case m[1] != "":
+			steps = append(steps, setStep{key: m[1]})
+		case m[2] != "":
+			steps = append(steps, setStep{key: m[2]})
+		default:
+			i, _ := strconv.Atoi(m[3])
+			steps = append(steps, setStep{index: i})
+		}
+		rest = rest[len(m[0]):]
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("invalid --set path %q", path)
+	}
+	return steps, nil
+}
+
+// SetValue applies a key.path=value override to ctx, creating missing
+// mappings and lists along the way. A list index may address an existing
+// item or append one past the end.
+func SetValue(ctx map[string]any, expr string) error {
+	path, raw, ok := strings.Cut(expr, "=")
+	if !ok {
+		return fmt.Errorf("invalid --set %q: expected key.path=value", expr)
+	}
+	steps, err := parseSetPath(strings.TrimSpace(path))
+	if err != nil {
+		return err
+	}
+
+	// ctx is a non-nil mapping, so setAt updates it in place.
+	_, err = setAt(ctx, steps, ParseValue(raw), path)
+	return err
+}
+
+// ParseValue types a --set or prompted value. Text that merely looks
+// like YAML, such as "Note: keep it short", stays a string.
+func ParseValue(raw string) any {
+	var parsed any
+	if err := yaml.Unmarshal([]byte(raw), &parsed); err != nil || parsed == nil {
+		return raw
+	}
+	switch parsed.(type) {
+	case string:
+		return raw
+	case map[string]any, []any:
+		if trimmed := strings.TrimSpace(raw); !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "{") {
+			return raw
+		}
+	}
+	return parsed
+}
+
+// setAt returns node with value stored at steps.
+func setAt(node any, steps []setStep, value any, path string) (any, error) {
+	if len(steps) == 0 {
+		return value, nil
+	}
+	step := steps[0]
+
+	if step.isIndex() {
+		list, ok := node.([]any)
+		if node != nil && !ok {
+			return nil, fmt.Errorf("invalid --set %s: %T is not a list", path, node)
+		}
+		switch {
+		case step.index < len(list):
+			v, err := setAt(list[step.index], steps[1:], value, path)
+			if err != nil {
+				return nil, err
+			}
+			list[step.index] = v
+		case step.index == len(list):
+			v, err := setAt(nil, steps[1:], value, path)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		default:
+			return nil, fmt.Errorf("invalid --set %s: index %d is past the end of a %d-item list", path, step.index, len(list))
+		}
+		return list, nil
+	}
+
+	m, ok := node.(map[string]any)
+	if node != nil && !ok {
+		return nil, fmt.Errorf("invalid --set %s: %T is not a mapping", path, node)
+	}
+	if m == nil {
+		m = map[string]any{}
+	}
+	v, err := setAt(m[step.key], steps[1:], value, path)
+	if err != nil {
+		return nil, err
+	}
+	m[step.key] = v
+	return m, nil
+}
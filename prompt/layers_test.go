@@ -0,0 +1,129 @@
+package prompt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeContext(t *testing.T) {
+	dst := map[string]any{
+		"tone":     "friendly",
+		"concepts": []any{"a", "b"},
+		"meta":     map[string]any{"level": "basic", "lang": "en"},
+	}
+	MergeContext(dst, map[string]any{
+		"concepts": []any{"c"},
+		"meta":     map[string]any{"level": "advanced"},
+		"extra":    true,
+	})
+	assert.Equal(t, map[string]any{
+		"tone":     "friendly",
+		"concepts": []any{"c"},
+		"meta":     map[string]any{"level": "advanced", "lang": "en"},
+		"extra":    true,
+	}, dst)
+}
+
+func TestSetValue(t *testing.T) {
+	ctx := map[string]any{
+		"concepts": []any{"a", "b"},
+		"tasks":    []any{map[string]any{"title": "x"}},
+	}
+	for _, expr := range []string{
+		"tone=formal",
+		"concepts[0]=Branches",
+		"concepts[2]=Note: appended",
+		"tasks[0].title=renamed",
+		"meta.level=advanced",
+		"meta.retries=3",
+		"meta.strict=true",
+		"tags=[git, vcs]",
+		"new[0].name=first",
+		"query=a=b",
+		"empty=",
+	} {
+		require.NoError(t, SetValue(ctx, expr), expr)
+	}
+	assert.Equal(t, map[string]any{
+		"tone":     "formal",
+		"concepts": []any{"Branches", "b", "Note: appended"},
+		"tasks":    []any{map[string]any{"title": "renamed"}},
+		"meta":     map[string]any{"level": "advanced", "retries": 3, "strict": true},
+		"tags":     []any{"git", "vcs"},
+		"new":      []any{map[string]any{"name": "first"}},
+		"query":    "a=b",
+		"empty":    "",
+	}, ctx)
+}
+
+func TestSetValue_Errors(t *testing.T) {
+	ctx := map[string]any{"tone": "formal", "concepts": []any{"a"}}
+	for expr, want := range map[string]string{
+		"tone":             `invalid --set "tone": expected key.path=value`,
+		"=x":               `invalid --set path ""`,
+		"[0]=x":            `invalid --set path "[0]"`,
+		"a..b=x":           `invalid --set path "a..b"`,
+		"concepts[5]=x":    "invalid --set concepts[5]: index 5 is past the end of a 1-item list",
+		"tone.level=x":     "invalid --set tone.level: string is not a mapping",
+		"tone[0]=x":        "invalid --set tone[0]: string is not a list",
+		"concepts.first=x": "invalid --set concepts.first: []interface {} is not a mapping",
+	} {
+		assert.EqualError(t, SetValue(ctx, expr), want, expr)
+	}
+}
+
+func noExpand(string, map[string]any) error { return nil }
+
+func TestLayers_Apply(t *testing.T) {
+	files := map[string]string{
+		"team.yaml":  "tone: formal\nmeta: {level: basic, lang: en}\n",
+		"local.yaml": "meta: {level: advanced}\n",
+		"vars.json":  `{"audience": "SREs", "tone": "dry"}`,
+	}
+	readFile := func(path string) ([]byte, error) {
+		if data, ok := files[path]; ok {
+			return []byte(data), nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	ctx := map[string]any{"tone": "friendly", "audience": "students"}
+	layers := Layers{
+		Configs:  []string{"team.yaml", "local.yaml"},
+		VarsFile: "vars.json",
+		Sets:     []string{"tone=playful"},
+	}
+	require.NoError(t, layers.apply(ctx, readFile, noExpand))
+	assert.Equal(t, map[string]any{
+		"tone":     "playful",
+		"audience": "SREs",
+		"meta":     map[string]any{"level": "advanced", "lang": "en"},
+	}, ctx)
+
+	ctx = map[string]any{"tone": "friendly", "meta": map[string]any{"lang": "en"}}
+	row := Layers{
+		VarsFile: "vars.json",
+		Vars:     map[string]any{"tone": "terse", "meta": map[string]any{"level": "basic"}},
+		Sets:     []string{"audience=all"},
+	}
+	require.NoError(t, row.apply(ctx, readFile, noExpand))
+	assert.Equal(t, map[string]any{
+		"tone":     "terse",
+		"audience": "all",
+		"meta":     map[string]any{"level": "basic", "lang": "en"},
+	}, ctx)
+
+	ctx = map[string]any{}
+	stdin := Layers{VarsFile: "-", Stdin: strings.NewReader("topic: Git\n")}
+	require.NoError(t, stdin.apply(ctx, readFile, noExpand))
+	assert.Equal(t, map[string]any{"topic": "Git"}, ctx)
+
+	assert.ErrorContains(t, Layers{Configs: []string{"missing.yaml"}}.apply(ctx, readFile, noExpand), "failed to read config file")
+	assert.ErrorContains(t, Layers{VarsFile: "-"}.apply(ctx, readFile, noExpand), "failed to read vars from stdin")
+	files["bad.yaml"] = "- a list"
+	assert.ErrorContains(t, Layers{VarsFile: "bad.yaml"}.apply(ctx, readFile, noExpand), "failed to parse vars file bad.yaml")
+}
@@ -0,0 +1,382 @@
+// Package lint checks every topic under a resources tree: template and
+// config syntax, interpolation, config schemas, variables, loops, leftover
+// template tags and prompt size.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"raja.aiml/ai.explorer/prompt"
+	"raja.aiml/ai.explorer/prompt/catalog"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// Severity ranks an issue. Only errors fail a lint run.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rule names, as reported in Issue.Rule.
+const (
+	RuleMissingTemplate   = "missing-template"
+	RuleTemplateParse     = "template-parse"
+	RuleConfigParse       = "config-parse"
+	RuleSchema            = "schema"
+	RuleInterpolation     = "interpolation"
+	RuleUndefinedVariable = "undefined-variable"
+	RuleUnusedVariable    = "unused-variable"
+	RuleEmptyLoop         = "empty-loop"
+	RuleRender            = "render"
+	RuleUnrenderedTag     = "unrendered-tag"
+	RuleTokenBudget       = "token-budget"
+)
+
+// Issue is a single problem, located by file and, when known, line.
+type Issue struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	loc := i.File
+	if i.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", loc, i.Severity, i.Message, i.Rule)
+}
+
+// Report is the outcome of a lint run.
+type Report struct {
+	Topics   int     `json:"topics"`
+	Errors   int     `json:"errors"`
+	Warnings int     `json:"warnings"`
+	Issues   []Issue `json:"issues"`
+}
+
+// Linter checks the topics under Root, laid out as
+// <Root>/<category>/<topic>/config.yaml. A topic uses its own
+// template.yaml, or the one shared by its category.
+type Linter struct {
+	Root string
+	// Query fills user_query for templates that read it.
+	Query string
+	// MaxTokens is the prompt budget. Zero uses the context window of the
+	// template's recommended model, or of Provider and Model.
+	MaxTokens int
+	Provider  string
+	Model     string
+	Counter   *tokens.Counter
+	Windows   *tokens.ContextWindows
+}
+
+// Run lints every topic. It fails only when Root cannot be read.
+func (l *Linter) Run() (*Report, error) {
+	topics, err := catalog.Scan(l.Root)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Topics: len(topics), Issues: []Issue{}}
+	seen := map[Issue]bool{}
+	for _, topic := range topics {
+		// Shared templates would otherwise repeat their problems per topic.
+		for _, issue := range l.lintTopic(topic) {
+			if !seen[issue] {
+				seen[issue] = true
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	for _, issue := range report.Issues {
+		if issue.Severity == SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	return report, nil
+}
+
+func (l *Linter) lintTopic(topic catalog.Topic) []Issue {
+	dir, cfgPath, tmplPath := topic.Dir, topic.Config, topic.Template
+	if tmplPath == "" {
+		return []Issue{{File: cfgPath, Severity: SeverityError, Rule: RuleMissingTemplate,
+			Message: "no template.yaml in the topic or its category"}}
+	}
+
+	var issues []Issue
+	add := func(file string, line int, sev Severity, rule, format string, args ...any) {
+		issues = append(issues, Issue{File: file, Line: line, Severity: sev, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var warnings warningLog
+	var ignored []prompt.IgnoredKeyword
+	builder := &prompt.Builder{
+		ReadFile:     os.ReadFile,
+		Logger:       &warnings,
+		TemplateRoot: l.Root,
+		// Ignored keywords are reported below with their lines, rather
+		// than logged.
+		LoadSchema: func(readFile func(string) ([]byte, error), path string) (*prompt.Schema, error) {
+			schema, err := prompt.LoadSchemaFor(readFile, path)
+			if schema != nil {
+				ignored, schema.Ignored = schema.Ignored, nil
+			}
+			return schema, err
+		},
+	}
+	tpl, tplErr := builder.LoadTemplate(tmplPath)
+	if tplErr != nil {
+		line := 0
+		var perr *prompt.ParseError
+		if errors.As(tplErr, &perr) {
+			line = perr.Line
+		}
+		add(tmplPath, line, SeverityError, RuleTemplateParse, "%v", tplErr)
+	}
+	ctx, cfgErr := builder.LoadConfig(tmplPath, cfgPath)
+	for _, k := range ignored {
+		add(topic.Schema, k.Line, SeverityWarning, RuleSchema, "%s", k)
+	}
+	for _, w := range warnings {
+		add(cfgPath, 0, SeverityWarning, RuleInterpolation, "%s", w)
+	}
+	cfgNode := configNode(cfgPath)
+	var (
+		cfgProblem *prompt.ConfigError
+		interpErr  *prompt.InterpolationError
+		schemaErr  *prompt.SchemaError
+	)
+	switch {
+	case cfgErr == nil:
+	case !errors.As(cfgErr, &cfgProblem):
+		// The config is fine; the template's schema is not.
+		line := 0
+		var perr *prompt.ParseError
+		if errors.As(cfgErr, &perr) {
+			line = perr.Line
+		}
+		add(topic.Schema, line, SeverityError, RuleSchema, "%v", cfgErr)
+	case errors.As(cfgErr, &interpErr):
+		add(cfgPath, 0, SeverityError, RuleInterpolation, "%v", interpErr.Err)
+	case errors.As(cfgErr, &schemaErr):
+		for _, v := range schemaErr.Violations {
+			add(cfgPath, valueLine(cfgNode, v.Path), SeverityError, RuleSchema, "%s", v)
+		}
+	default:
+		add(cfgPath, prompt.YAMLErrorLine(cfgErr), SeverityError, RuleConfigParse, "%v", cfgErr)
+	}
+	if tplErr != nil || cfgErr != nil {
+		return issues
+	}
+
+	analysis, err := tpl.Analyze()
+	if err != nil {
+		var perr *prompt.ParseError
+		line := 0
+		if errors.As(err, &perr) {
+			line = perr.Line
+		}
+		add(tmplPath, line, SeverityError, RuleTemplateParse, "%v", err)
+		return issues
+	}
+	if _, ok := ctx["user_query"]; !ok && reads(analysis, "user_query") {
+		ctx["user_query"] = l.Query
+	}
+
+	for _, m := range analysis.Missing(ctx) {
+		add(tmplPath, m.Line, SeverityError, RuleUndefinedVariable, "%s is not defined in %s", m.Path, cfgPath)
+	}
+	for _, key := range analysis.Unused(ctx) {
+		add(cfgPath, keyLine(cfgNode, key), SeverityWarning, RuleUnusedVariable, "%s is never read by %s", key, tmplPath)
+	}
+	for _, loop := range analysis.EmptyLoops(ctx) {
+		add(tmplPath, loop.Line, SeverityWarning, RuleEmptyLoop, "loop over %s has no items in %s", loop.Path, cfgPath)
+	}
+
+	rendered, err := tpl.Render(ctx)
+	if err != nil {
+		add(tmplPath, 0, SeverityError, RuleRender, "rendering with %s failed: %v", cfgPath, err)
+		return issues
+	}
+	text := rendered.String()
+	for _, tag := range []string{"{{", "{%"} {
+		if !strings.Contains(text, tag) {
+			continue
+		}
+		// Config values are not rendered, so a tag in one reaches the
+		// prompt as is. Report it there; otherwise the template wrote it.
+		if keys := valuesWithTag(map[string]any(ctx), "", tag, text); len(keys) > 0 {
+			for _, key := range keys {
+				add(cfgPath, valueLine(cfgNode, key), SeverityError, RuleUnrenderedTag,
+					"%s contains %q, which reaches the prompt unrendered", key, tag)
+			}
+			continue
+		}
+		for _, line := range strings.Split(text, "\n") {
+			if strings.Contains(line, tag) {
+				add(tmplPath, 0, SeverityError, RuleUnrenderedTag, "rendered prompt for %s still contains %q: %s",
+					dir, tag, strings.TrimSpace(line))
+			}
+		}
+	}
+
+	provider, model := l.Provider, l.Model
+	if rec := tpl.Spec.Recommended; rec.Model != "" {
+		provider, model = rec.Provider, rec.Model
+		if provider == "" {
+			provider = l.Provider
+		}
+	}
+	budget := l.MaxTokens
+	if budget == 0 {
+		budget, _ = l.Windows.Lookup(provider, model)
+	}
+	if count := l.Counter.Count(provider, model, text); budget > 0 && count.Tokens > budget {
+		add(tmplPath, 0, SeverityError, RuleTokenBudget, "rendered prompt for %s is %s, over the %s-token budget for %s:%s",
+			dir, count.Describe(), tokens.FormatInt(budget), provider, model)
+	}
+	return issues
+}
+
+// configNode parses a config for locating its keys only; its values come
+// from prompt.Builder, as when rendering. It is nil if the YAML is invalid.
+func configNode(path string) *yaml.Node {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var root yaml.Node
+	if yaml.Unmarshal(data, &root) != nil {
+		return nil
+	}
+	return &root
+}
+
+// warningLog collects the warnings prompt.Builder logs, such as unset
+// environment variables.
+type warningLog []string
+
+func (w *warningLog) Printf(format string, v ...any) {
+	*w = append(*w, strings.TrimPrefix(fmt.Sprintf(format, v...), "warning: "))
+}
+
+func (w *warningLog) Fatalf(format string, v ...any) {
+	w.Printf(format, v...)
+}
+
+// valuesWithTag returns the paths, such as tasks[0].description, of string
+// values under v that contain tag and appear in the rendered text.
+func valuesWithTag(v any, path, tag, text string) []string {
+	var out []string
+	switch v := v.(type) {
+	case string:
+		if strings.Contains(v, tag) && strings.Contains(text, v) {
+			out = append(out, path)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			out = append(out, valuesWithTag(v[k], child, tag, text)...)
+		}
+	case []any:
+		for i, item := range v {
+			out = append(out, valuesWithTag(item, fmt.Sprintf("%s[%d]", path, i), tag, text)...)
+		}
+	}
+	return out
+}
+
+// reads reports whether the template reads name at the top level.
+func reads(a *prompt.Analysis, name string) bool {
+	for _, r := range a.Refs {
+		if len(r.Path) > 0 && r.Path[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+var keySegment = regexp.MustCompile(`\[\d*\]|[^.\[\]]+`)
+
+// valueLine finds the line of a schema violation's path. Paths that do not
+// exist, such as a missing required key, fall back to their parent.
+func valueLine(node *yaml.Node, path string) int {
+	segs := keySegment.FindAllString(path, -1)
+	for n := len(segs); n > 0; n-- {
+		if line := findKey(node, segs[:n]); line > 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+// keyLine finds the line of a config key such as metadata_fields[].options,
+// using the first list element that has it, or metadata_fields[3].type. It
+// returns 0 if not found.
+func keyLine(node *yaml.Node, key string) int {
+	return findKey(node, keySegment.FindAllString(key, -1))
+}
+
+func findKey(node *yaml.Node, segs []string) int {
+	if node == nil {
+		return 0
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return findKey(node.Content[0], segs)
+	}
+	if len(segs) == 0 {
+		return node.Line
+	}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(strings.Trim(segs[0], "[]")); err == nil {
+			if i < len(node.Content) {
+				return findKey(node.Content[i], segs[1:])
+			}
+		} else if segs[0] == "[]" {
+			for _, elem := range node.Content {
+				if line := findKey(elem, segs[1:]); line > 0 {
+					return line
+				}
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segs[0] {
+				if len(segs) == 1 {
+					return node.Content[i].Line
+				}
+				return findKey(node.Content[i+1], segs[1:])
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,203 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/tokens"
+)
+
+// runeEncoder treats every rune as one token.
+type runeEncoder struct{}
+
+func (runeEncoder) Encode(text string, _, _ []string) []int { return make([]int, len([]rune(text))) }
+func (runeEncoder) Decode([]int) string                     { return "" }
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return root
+}
+
+func newLinter(root string) *Linter {
+	return &Linter{
+		Root:     root,
+		Query:    "What is BGP?",
+		Provider: "ollama",
+		Model:    "phi4",
+		Counter:  &tokens.Counter{Load: func(string) (tokens.Encoder, error) { return runeEncoder{}, nil }},
+		Windows:  &tokens.ContextWindows{Windows: map[string]int{"ollama:*": 4096}},
+	}
+}
+
+func TestLinter_Clean(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"topics/template.yaml":      "template: |\n  Explain {{ topic }}.\n  {% for c in concepts %}- {{ c }}\n  {% endfor %}",
+		"topics/git/config.yaml":    "topic: Git\nconcepts: [commits]\n",
+		"topics/bgp/config.yaml":    "topic: BGP\nconcepts: [peering]\n",
+		"router/main/template.yaml": "Q: {{ user_query }}",
+		"router/main/config.yaml":   "{}\n",
+	})
+
+	report, err := newLinter(root).Run()
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Topics)
+	assert.Empty(t, report.Issues)
+}
+
+func TestLinter_ReportsProblems(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		// Shared by two topics; its parse error is reported once.
+		"broken/template.yaml":   "name: broken\ntemplate: |\n  ok\n  {{ broken\n",
+		"broken/a/config.yaml":   "x: 1\n",
+		"broken/b/config.yaml":   "x: 1\n",
+		"badcfg/t/template.yaml": "Hi {{ name }}",
+		"badcfg/t/config.yaml":   "name: [unclosed\n",
+		"vars/t/template.yaml":   "# banner\ntemplate: |\n  Hi {{ name }}\n  {% for s in steps %}{{ s.title }}{% endfor %}\n",
+		"vars/t/config.yaml":     "steps: []\ntone: dry\n",
+		"tags/t/template.yaml":   "Literal {{ '{{' }} stays",
+		"tags/t/config.yaml":     "{}\n",
+		"tags/v/template.yaml":   "Note: {{ note }}",
+		"tags/v/config.yaml":     "# leftover tag\nnote: 'Use {% raw %} here'\n",
+		"orphan/t/config.yaml":   "{}\n",
+	})
+
+	report, err := newLinter(root).Run()
+	require.NoError(t, err)
+
+	byRule := map[string][]Issue{}
+	for _, issue := range report.Issues {
+		byRule[issue.Rule] = append(byRule[issue.Rule], issue)
+	}
+
+	require.Len(t, byRule[RuleTemplateParse], 1)
+	assert.Equal(t, filepath.Join(root, "broken/template.yaml"), byRule[RuleTemplateParse][0].File)
+	assert.Equal(t, 4, byRule[RuleTemplateParse][0].Line)
+
+	require.Len(t, byRule[RuleConfigParse], 1)
+	assert.Equal(t, 1, byRule[RuleConfigParse][0].Line)
+
+	require.Len(t, byRule[RuleUndefinedVariable], 1)
+	assert.Equal(t, 3, byRule[RuleUndefinedVariable][0].Line)
+	assert.Contains(t, byRule[RuleUndefinedVariable][0].Message, "name is not defined")
+
+	require.Len(t, byRule[RuleUnusedVariable], 1)
+	assert.Equal(t, SeverityWarning, byRule[RuleUnusedVariable][0].Severity)
+	assert.Equal(t, 2, byRule[RuleUnusedVariable][0].Line)
+	assert.Contains(t, byRule[RuleUnusedVariable][0].Message, "tone is never read")
+
+	require.Len(t, byRule[RuleEmptyLoop], 1)
+	assert.Equal(t, 4, byRule[RuleEmptyLoop][0].Line)
+
+	require.Len(t, byRule[RuleUnrenderedTag], 2)
+	assert.Equal(t, filepath.Join(root, "tags/t/template.yaml"), byRule[RuleUnrenderedTag][0].File)
+	assert.Zero(t, byRule[RuleUnrenderedTag][0].Line, "rendered lines are not template lines")
+	assert.Contains(t, byRule[RuleUnrenderedTag][0].Message, `still contains "{{"`)
+	// A tag in a config value is reported where the value is.
+	assert.Equal(t, filepath.Join(root, "tags/v/config.yaml"), byRule[RuleUnrenderedTag][1].File)
+	assert.Equal(t, 2, byRule[RuleUnrenderedTag][1].Line)
+	assert.Equal(t, `note contains "{%", which reaches the prompt unrendered`, byRule[RuleUnrenderedTag][1].Message)
+
+	require.Len(t, byRule[RuleMissingTemplate], 1)
+
+	assert.Equal(t, 6, report.Errors)
+	assert.Equal(t, 2, report.Warnings)
+}
+
+func TestLinter_TokenBudget(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"demo/long/template.yaml": "{{ text }}",
+		"demo/long/config.yaml":   "text: " + string(make([]byte, 0)) + "aaaaaaaaaaaaaaaaaaaa\n",
+	})
+	l := newLinter(root)
+	l.MaxTokens = 10
+
+	report, err := l.Run()
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, RuleTokenBudget, report.Issues[0].Rule)
+	assert.Contains(t, report.Issues[0].Message, "is 20 tokens")
+
+	// Without an explicit budget, the model's context window applies.
+	l.MaxTokens = 0
+	l.Windows = &tokens.ContextWindows{Windows: map[string]int{"ollama:phi4": 15}}
+	report, _ = l.Run()
+	assert.Contains(t, report.Issues[0].Message, "over the 15-token budget for ollama:phi4")
+}
+
+func TestLinter_MissingRoot(t *testing.T) {
+	_, err := newLinter(filepath.Join(t.TempDir(), "nope")).Run()
+	assert.ErrorContains(t, err, "failed to read resources")
+}
+
+func TestLinter_Schema(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"router/template.yaml":       "{% for f in fields %}{{ f.name }}: {{ f.type }}{% endfor %}",
+		"router/schema.yaml":         "required: [fields]\nproperties:\n  fields:\n    items: {required: [name], properties: {type: {type: string}}}\n",
+		"router/main/config.yaml":    "fields:\n  - name: tone\n    type: string\n  - name: urgency\n    type: 3\n",
+		"router/missing/config.yaml": "{}\n",
+		"broken/template.yaml":       "{{ x }}",
+		"broken/schema.json":         "{\n  \"type\": \"object\",\n  \"minItems\": \"two\"\n}",
+		"broken/topic/config.yaml":   "x: 1\n",
+		"loose/template.yaml":        "{{ x }}",
+		"loose/schema.json":          "{\n  \"type\": \"object\",\n  \"oneOf\": []\n}",
+		"loose/topic/config.yaml":    "x: 1\n",
+	})
+
+	report, err := newLinter(root).Run()
+	require.NoError(t, err)
+
+	var schema []Issue
+	for _, issue := range report.Issues {
+		assert.NotEqual(t, RuleInterpolation, issue.Rule, "ignored keywords are not logged as interpolation warnings")
+		if issue.Rule == RuleSchema {
+			schema = append(schema, issue)
+		}
+	}
+	require.Len(t, schema, 4)
+	assert.Equal(t, filepath.Join(root, "broken/schema.json"), schema[0].File)
+	assert.Equal(t, 3, schema[0].Line)
+	assert.Equal(t, SeverityError, schema[0].Severity)
+
+	assert.Equal(t, filepath.Join(root, "loose/schema.json"), schema[1].File)
+	assert.Equal(t, 3, schema[1].Line)
+	assert.Equal(t, SeverityWarning, schema[1].Severity)
+	assert.Equal(t, "(root): oneOf is not supported and is ignored", schema[1].Message)
+
+	assert.Equal(t, filepath.Join(root, "router/main/config.yaml"), schema[2].File)
+	assert.Equal(t, 5, schema[2].Line)
+	assert.Equal(t, "fields[1].type: expected string, got integer", schema[2].Message)
+
+	assert.Equal(t, filepath.Join(root, "router/missing/config.yaml"), schema[3].File)
+	assert.Equal(t, "fields: is required", schema[3].Message)
+}
+
+func TestLinter_Interpolation(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"docs/template.yaml":       "{{ guide }} {{ team }}",
+		"docs/ok/guide.md":         "Be brief.",
+		"docs/ok/config.yaml":      "guide: '@file:guide.md'\nteam: ${AI_EXPLORER_UNSET_VAR:-core}\n",
+		"docs/unset/config.yaml":   "guide: x\nteam: ${AI_EXPLORER_UNSET_VAR}\n",
+		"docs/missing/config.yaml": "guide: '@file:gone.md'\nteam: x\n",
+	})
+
+	report, err := newLinter(root).Run()
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 2)
+
+	assert.Equal(t, filepath.Join(root, "docs/missing/config.yaml"), report.Issues[0].File)
+	assert.Equal(t, SeverityError, report.Issues[0].Severity)
+	assert.Contains(t, report.Issues[0].Message, "guide: failed to include")
+
+	assert.Equal(t, filepath.Join(root, "docs/unset/config.yaml"), report.Issues[1].File)
+	assert.Equal(t, SeverityWarning, report.Issues[1].Severity)
+	assert.Equal(t, RuleInterpolation, report.Issues[1].Rule)
+}
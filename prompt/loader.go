@@ -0,0 +1,152 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/flosch/pongo2/v6"
+)
+
+// Loader resolves the files a template includes, extends or imports. A
+// path is looked up relative to the including template first, then from
+// Root, so `partials/constraints.tmpl` works from any category.
+type Loader struct {
+	Root string
+	// Origin is the template file being parsed. Its sections are parsed
+	// from strings, so their includes resolve relative to Origin.
+	Origin   string
+	ReadFile func(path string) ([]byte, error)
+}
+
+// Ensure Loader satisfies pongo2's TemplateLoader interface.
+var _ pongo2.TemplateLoader = (*Loader)(nil)
+
+// Abs resolves name as included from base, or from Origin when base is
+// empty. Results are absolute, so resolving them again is a no-op.
+func (l *Loader) Abs(base, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	switch {
+	case base == "":
+		base = l.Origin
+	case !filepath.IsAbs(base):
+		// pongo2 names files included from a string template by the path
+		// as written, so resolve that first.
+		base = l.Abs("", base)
+	}
+	candidates := []string{filepath.Join(filepath.Dir(base), name)}
+	if l.Root != "" {
+		candidates = append(candidates, filepath.Join(l.Root, name))
+	}
+	for _, path := range candidates {
+		if _, err := l.read(path); err == nil {
+			return absPath(path)
+		}
+	}
+	// Report the last place looked when the file is missing.
+	return absPath(candidates[len(candidates)-1])
+}
+
+// Get opens a resolved template path.
+func (l *Loader) Get(path string) (io.Reader, error) {
+	data, err := l.read(path)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (l *Loader) read(path string) ([]byte, error) {
+	if l.ReadFile == nil {
+		return os.ReadFile(path)
+	}
+	return l.ReadFile(path)
+}
+
+// set returns a template set that loads through l.
+func (l *Loader) set() *pongo2.TemplateSet {
+	return pongo2.NewSet("prompt:"+l.Origin, l)
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// includePattern matches tags that load another template by a literal
+// path. Paths computed at render time cannot be followed statically.
+var includePattern = regexp.MustCompile(`\{%-?\s*(include|extends|import)\s+(?:"([^"]+)"|'([^']+)')`)
+
+var commentPattern = regexp.MustCompile(`(?s)\{#.*?#\}|\{%-?\s*comment\s*-?%\}.*?\{%-?\s*endcomment\s*-?%\}`)
+
+// stripComments blanks out comments, keeping their line breaks so lines
+// still match the source.
+func stripComments(src string) string {
+	return commentPattern.ReplaceAllStringFunc(src, func(c string) string {
+		return strings.Repeat("\n", strings.Count(c, "\n"))
+	})
+}
+
+// includeName returns the literal path of an include, extends or import
+// tag match.
+func includeName(src string, m []int) string {
+	if m[4] >= 0 {
+		return src[m[4]:m[5]]
+	}
+	return src[m[6]:m[7]]
+}
+
+// checkCycles fails when a section's includes, extends or imports lead
+// back to a template already being loaded. pongo2 loads them while
+// parsing, so a cycle would otherwise recurse without end. The error is
+// reported at the section's tag that starts the cycle.
+func (l *Loader) checkCycles(sec section) error {
+	done := map[string]bool{}
+	// visit follows the tags in src, read from file. stack holds the
+	// resolved paths being loaded; names holds them as written.
+	var visit func(file, src string, stack, names []string) error
+	visit = func(file, src string, stack, names []string) error {
+		src = stripComments(src)
+		for _, m := range includePattern.FindAllStringSubmatchIndex(src, -1) {
+			name := includeName(src, m)
+			path := l.Abs(file, name)
+			chain := append(names[:len(names):len(names)], name)
+			for _, loading := range stack {
+				if loading == path {
+					return fmt.Errorf("include cycle: %s", strings.Join(chain, " -> "))
+				}
+			}
+			if done[path] {
+				continue
+			}
+			data, err := l.read(path)
+			if err != nil {
+				// pongo2 reports missing files when it parses the tag.
+				continue
+			}
+			if err := visit(path, string(data), append(stack[:len(stack):len(stack)], path), chain); err != nil {
+				return err
+			}
+			done[path] = true
+		}
+		return nil
+	}
+
+	src := stripComments(sec.src)
+	for _, m := range includePattern.FindAllStringSubmatchIndex(src, -1) {
+		tag := src[m[0]:m[1]] + "%}"
+		if err := visit(l.Origin, tag, []string{absPath(l.Origin)}, []string{l.Origin}); err != nil {
+			line := sec.line + strings.Count(src[:m[0]], "\n")
+			return &ParseError{Line: line, Err: err}
+		}
+	}
+	return nil
+}
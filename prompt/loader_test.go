@@ -0,0 +1,123 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flosch/pongo2/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeResources lays out files under a temporary resources root.
+func writeResources(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return root
+}
+
+func parseResource(t *testing.T, root, name string) (*Template, error) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return ParseTemplateIn(&Loader{Root: root, Origin: path}, data)
+}
+
+func TestLoader_IncludesAndMacros(t *testing.T) {
+	root := writeResources(t, map[string]string{
+		"partials/lists.tmpl":       `{% macro bullets(title, items) export %}{{ title }}:{% for i in items %} {{ i }}{% endfor %}{% endmacro %}`,
+		"partials/constraints.tmpl": `{% import "lists.tmpl" bullets %}{{ bullets("Constraints", constraints) }}`,
+		"topics/note.tmpl":          `(local note for {{ topic }})`,
+		"topics/template.yaml": `template: |
+  {% include "partials/constraints.tmpl" %}
+  {% include "note.tmpl" %}
+  {% for c in concepts %}{% include "partials/item.tmpl" with label="-" %}{% endfor %}
+`,
+		"partials/item.tmpl": `{{ label }} {{ c }};`,
+	})
+
+	tpl, err := parseResource(t, root, "topics/template.yaml")
+	require.NoError(t, err)
+	out, err := tpl.Execute(pongo2.Context{"constraints": []any{"short", "clear"}, "topic": "Git", "concepts": []any{"a", "b"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Constraints: short clear\n(local note for Git)\n- a;- b;\n", out)
+
+	// Refs inside partials count, reported at the include tag's line.
+	analysis, err := tpl.Analyze()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"concepts", "concepts[]", "constraints", "topic"}, analysis.Paths())
+	missing := analysis.Missing(pongo2.Context{"concepts": []any{"a"}})
+	assert.Equal(t, []Finding{{Path: "constraints", Line: 2}, {Path: "topic", Line: 3}}, missing)
+}
+
+func TestLoader_Extends(t *testing.T) {
+	root := writeResources(t, map[string]string{
+		"layouts/base.tmpl": "# {% block title %}{{ default_title }}{% endblock %}\n" +
+			"{% block body %}{% endblock %}\nAudience: {{ audience }}",
+		"demo/template.yaml": `template: |
+  {% extends "layouts/base.tmpl" %}
+  {% block title %}{{ topic }}{% endblock %}
+  {% block body %}Explain {{ topic }}.{% endblock %}
+`,
+	})
+
+	tpl, err := parseResource(t, root, "demo/template.yaml")
+	require.NoError(t, err)
+	out, err := tpl.Execute(pongo2.Context{"topic": "BGP", "audience": "SREs"})
+	require.NoError(t, err)
+	assert.Equal(t, "# BGP\nExplain BGP.\nAudience: SREs", out)
+
+	// The overridden title block in the layout is not read.
+	analysis, err := tpl.Analyze()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"audience", "topic"}, analysis.Paths())
+}
+
+func TestLoader_Cycles(t *testing.T) {
+	root := writeResources(t, map[string]string{
+		"partials/a.tmpl":    `{# {% include "partials/a.tmpl" %} is only a comment #}A{% include "b.tmpl" %}`,
+		"partials/b.tmpl":    `B{% include "a.tmpl" %}`,
+		"partials/self.tmpl": `{% extends "template.yaml" %}`,
+		"loop/template.yaml": "name: loop\ntemplate: |\n  intro\n  {% include \"partials/a.tmpl\" %}\n",
+		"self/template.yaml": "{% include \"../partials/up.tmpl\" %}",
+		"partials/up.tmpl":   `{% include "../self/template.yaml" %}`,
+	})
+
+	_, err := parseResource(t, root, "loop/template.yaml")
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+	assert.Equal(t, 4, perr.Line)
+	assert.EqualError(t, err, "include cycle: "+filepath.Join(root, "loop/template.yaml")+
+		" -> partials/a.tmpl -> b.tmpl -> a.tmpl")
+
+	_, err = parseResource(t, root, "self/template.yaml")
+	assert.ErrorContains(t, err, "include cycle:")
+}
+
+func TestLoader_MissingInclude(t *testing.T) {
+	root := writeResources(t, map[string]string{
+		"demo/template.yaml": "template: |\n  {% include \"partials/nope.tmpl\" %}\n",
+	})
+	_, err := parseResource(t, root, "demo/template.yaml")
+	assert.ErrorContains(t, err, "partials/nope.tmpl")
+	assert.ErrorContains(t, err, "unable to resolve template")
+}
+
+func Test_Builder_TemplateRoot(t *testing.T) {
+	root := writeResources(t, map[string]string{
+		"partials/greeting.tmpl": "Hello {{ name }}",
+		"demo/template.yaml":     "template: '{% include \"partials/greeting.tmpl\" %}!'",
+		"demo/config.yaml":       "name: Go\n",
+	})
+	builder := &Builder{ReadFile: os.ReadFile, TemplateRoot: root, Logger: &fakeLogger{}}
+	out, err := builder.RenderToString(filepath.Join(root, "demo/template.yaml"), filepath.Join(root, "demo/config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello Go!", out)
+}
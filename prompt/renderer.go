@@ -1,20 +1,101 @@
 package prompt
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
 
 	"raja.aiml/ai.explorer/logger"
+	"raja.aiml/ai.explorer/paths"
 )
 
-// Renderer defines rendering methods for different output targets.
+// Request names a template and config to render together.
+type Request struct {
+	Template string
+	Config   string
+	// Query is exposed to the template as user_query when set.
+	Query string
+	// Vars are merged over the config and its layers, such as one row of
+	// a dataset.
+	Vars map[string]any
+}
+
+// Renderer renders prompts. Failures are reported as *TemplateNotFoundError,
+// *ParseError, *RenderError, *ConfigError or *WriteError where one applies,
+// so callers can tell them apart with errors.As.
 type Renderer interface {
-	RenderToFile(templatePath, configPath, outputPath string, userQuery ...string)
-	RenderToStdout(templatePath, configPath string, userQuery ...string)
+	Render(ctx context.Context, req Request) (string, error)
+	RenderTo(ctx context.Context, w io.Writer, req Request) error
 }
 
-// DefaultRenderer is the standard implementation of Renderer.
-var DefaultRenderer Renderer = &Builder{
-	ReadFile:  os.ReadFile,
-	WriteFile: os.WriteFile,
-	Logger:    logger.New(),
+// TemplateNotFoundError is a template file that does not exist.
+type TemplateNotFoundError struct {
+	Path string
+	Err  error
+}
+
+func (e *TemplateNotFoundError) Error() string {
+	return fmt.Sprintf("template not found: %s", e.Path)
+}
+
+func (e *TemplateNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// RenderError is a template that parsed but failed to render with its
+// config, such as a missing required variable or a failing filter.
+type RenderError struct {
+	Path string
+	Err  error
+}
+
+func (e *RenderError) Error() string {
+	return fmt.Sprintf("template rendering failed: %v", e.Err)
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError is a config that could not be read, merged with its layers,
+// or validated against the template.
+type ConfigError struct {
+	Path string
+	Err  error
 }
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError is a rendered prompt that could not be written out. Path is
+// empty when writing to a stream.
+type WriteError struct {
+	Path string
+	Err  error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("failed to write output: %v", e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultBuilder renders templates from disk. Commands that need template
+// metadata or separate system and user sections use it directly.
+var DefaultBuilder = &Builder{
+	ReadFile:     os.ReadFile,
+	TemplateRoot: paths.ResourcesDir,
+	LoadSchema:   LoadSchemaFor,
+	Logger:       logger.New(),
+}
+
+// DefaultRenderer is the standard implementation of Renderer.
+var DefaultRenderer Renderer = DefaultBuilder
@@ -1,7 +1,9 @@
 package prompt
 
 import (
-	"io"
+	"bytes"
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -11,42 +13,268 @@ import (
 
 // --- Tests: Public rendering interface ---
 
-func Test_Builder_RenderToFile_WritesExpectedOutput(t *testing.T) {
+func Test_Builder_Render_ReturnsOutput(t *testing.T) {
 	dir := t.TempDir()
-	tmpl := writeTempFile(t, dir, "template.tmpl", `Hello {{ name }}`)
+	tmpl := writeTempFile(t, dir, "template.tmpl", `Hello {{ name }}, {{ user_query }}`)
 	cfg := writeTempFile(t, dir, "config.yaml", `name: world`)
-	out := filepath.Join(dir, "output.txt")
 
-	var wrote []byte
-	builder := &Builder{
-		ReadFile:  os.ReadFile,
-		WriteFile: func(_ string, data []byte, _ os.FileMode) error { wrote = data; return nil },
-		Logger:    &fakeLogger{},
-	}
+	builder := &Builder{ReadFile: os.ReadFile, Logger: &fakeLogger{}}
+
+	out, err := builder.Render(context.Background(), Request{Template: tmpl, Config: cfg, Query: "hi"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world, hi", out)
 
-	builder.RenderToFile(tmpl, cfg, out)
-	assert.Contains(t, string(wrote), "Hello world")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = builder.Render(ctx, Request{Template: tmpl, Config: cfg})
+	assert.ErrorIs(t, err, context.Canceled)
 }
 
-func Test_Builder_RenderToStdout_PrintsExpectedOutput(t *testing.T) {
+func Test_Builder_RenderTo_WritesExpectedOutput(t *testing.T) {
 	dir := t.TempDir()
 	tmpl := writeTempFile(t, dir, "template.tmpl", `Hi {{ name }}`)
 	cfg := writeTempFile(t, dir, "config.yaml", `name: stdout`)
 
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
+	builder := &Builder{ReadFile: os.ReadFile, Logger: &fakeLogger{}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, builder.RenderTo(context.Background(), &buf, Request{Template: tmpl, Config: cfg}))
+	assert.Equal(t, "Hi stdout", buf.String())
+
+	// Nothing is written when rendering fails.
+	buf.Reset()
+	err := builder.RenderTo(context.Background(), &buf, Request{Template: tmpl, Config: filepath.Join(dir, "missing.yaml")})
+	var cfgErr *ConfigError
+	assert.ErrorAs(t, err, &cfgErr)
+	assert.Empty(t, buf.String())
+
+	err = builder.RenderTo(context.Background(), failingWriter{}, Request{Template: tmpl, Config: cfg})
+	var writeErr *WriteError
+	assert.ErrorAs(t, err, &writeErr)
+	assert.EqualError(t, err, "failed to write output: disk full")
+}
+
+// failingWriter is a writer whose every write fails.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func Test_Builder_Render_TypedErrors(t *testing.T) {
+	dir := t.TempDir()
+	good := writeTempFile(t, dir, "good.tmpl", `{{ name }}`)
+	broken := writeTempFile(t, dir, "broken.yaml", "template: 'Hi {{ broken'\n")
+	needsQuery := writeTempFile(t, dir, "query.yaml", "required: [user_query]\ntemplate: '{{ user_query }}'\n")
+	cfg := writeTempFile(t, dir, "config.yaml", `name: router`)
+	badCfg := writeTempFile(t, dir, "bad.yaml", `name: [`)
 
-	builder := &Builder{
-		ReadFile:  os.ReadFile,
-		WriteFile: nil,
-		Logger:    &fakeLogger{},
+	builder := &Builder{ReadFile: os.ReadFile, Logger: &fakeLogger{}}
+	render := func(tmpl, cfg string) error {
+		_, err := builder.Render(context.Background(), Request{Template: tmpl, Config: cfg})
+		return err
 	}
-	builder.RenderToStdout(tmpl, cfg)
 
-	_ = w.Close()
-	os.Stdout = oldStdout
+	var notFound *TemplateNotFoundError
+	assert.ErrorAs(t, render(filepath.Join(dir, "missing.tmpl"), cfg), &notFound)
+
+	var perr *ParseError
+	assert.ErrorAs(t, render(broken, cfg), &perr)
+	assert.Equal(t, broken, perr.File)
+	assert.Equal(t, 1, perr.Line)
+	assert.Equal(t, 18, perr.Column, "the column counts the key and quote")
+
+	var cfgErr *ConfigError
+	assert.ErrorAs(t, render(good, badCfg), &cfgErr)
+	assert.Equal(t, badCfg, cfgErr.Path)
+	assert.ErrorAs(t, render(good, filepath.Join(dir, "missing.yaml")), &cfgErr)
+
+	var renderErr *RenderError
+	err := render(needsQuery, cfg)
+	assert.ErrorAs(t, err, &renderErr)
+	assert.Equal(t, needsQuery, renderErr.Path)
+	assert.EqualError(t, err, "template rendering failed: missing required variables: user_query")
+}
+
+func Test_Builder_RenderToString_ReturnsOutput(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := writeTempFile(t, dir, "template.tmpl", `Q: {{ user_query }} ({{ name }})`)
+	cfg := writeTempFile(t, dir, "config.yaml", `name: router`)
+
+	builder := &Builder{ReadFile: os.ReadFile, Logger: &fakeLogger{}}
+
+	out, err := builder.RenderToString(tmpl, cfg, "what is BGP?")
+	assert.NoError(t, err)
+	assert.Equal(t, "Q: what is BGP? (router)", out)
+}
+
+func Test_Builder_RenderToString_ReturnsErrors(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := writeTempFile(t, dir, "template.tmpl", `{{ broken`)
+	cfg := writeTempFile(t, dir, "config.yaml", `name: router`)
+
+	builder := &Builder{ReadFile: os.ReadFile, Logger: &fakeLogger{}}
+
+	_, err := builder.RenderToString(tmpl, cfg)
+	assert.ErrorContains(t, err, "failed to parse template")
+
+	_, err = builder.RenderToString(tmpl, filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to parse template")
+
+	good := writeTempFile(t, dir, "good.tmpl", `ok`)
+	_, err = builder.RenderToString(good, filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read config file")
+}
+
+func Test_Builder_RenderSections_YAMLTemplate(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := writeTempFile(t, dir, "template.yaml", `# ROUTER TEMPLATE
+required: [user_query]
+system: |
+  You route queries for {{ name }}.
+user: |
+  Input Query: {{ user_query }}
+`)
+	cfg := writeTempFile(t, dir, "config.yaml", `name: router`)
+
+	builder := &Builder{ReadFile: os.ReadFile, Logger: &fakeLogger{}}
+
+	out, err := builder.RenderSections(tmpl, cfg, "what is BGP?")
+	assert.NoError(t, err)
+	assert.Equal(t, Rendered{System: "You route queries for router.\n", User: "Input Query: what is BGP?\n"}, out)
 
-	out, _ := io.ReadAll(r)
-	assert.Contains(t, string(out), "Hi stdout")
+	text, err := builder.RenderToString(tmpl, cfg, "what is BGP?")
+	assert.NoError(t, err)
+	assert.Equal(t, "You route queries for router.\n\nInput Query: what is BGP?\n", text)
+	assert.NotContains(t, text, "ROUTER TEMPLATE")
+
+	_, err = builder.RenderSections(tmpl, cfg)
+	assert.ErrorContains(t, err, "missing required variables: user_query")
+}
+
+func Test_Builder_Strict(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := writeTempFile(t, dir, "template.tmpl", `Explain {{ topic }} using {{ analogies }}.`)
+	cfg := writeTempFile(t, dir, "config.yaml", "topic: Git\ntone: friendly\n")
+
+	builder := &Builder{ReadFile: os.ReadFile, Logger: &fakeLogger{}}
+	out, err := builder.RenderToString(tmpl, cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "Explain Git using .", out)
+
+	builder.Strict = true
+	_, err = builder.RenderToString(tmpl, cfg)
+	assert.EqualError(t, err, "strict mode: missing from config: analogies; unused by template: tone")
+	var cfgErr *ConfigError
+	assert.ErrorAs(t, err, &cfgErr, "strict mismatches are config errors")
+	var strictErr *StrictError
+	assert.ErrorAs(t, err, &strictErr)
+
+	_, err = builder.RenderToString(tmpl, cfg, "Why?")
+	assert.EqualError(t, err, "strict mode: missing from config: analogies; unused by template: tone",
+		"the query is not reported as an unused config key")
+}
+
+func Test_Builder_ValidatesSchema(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := writeTempFile(t, dir, "template.yaml", "template: Hi {{ name }}, {{ user_query }}")
+	writeTempFile(t, dir, "schema.json", `{"type": "object", "required": ["name"],
+		"properties": {"name": {"type": "string"}}, "additionalProperties": false}`)
+	good := writeTempFile(t, dir, "good.yaml", "name: Go\n")
+	bad := writeTempFile(t, dir, "bad.yaml", "name: 7\n")
+
+	builder := &Builder{ReadFile: os.ReadFile, LoadSchema: LoadSchemaFor, Logger: &fakeLogger{}}
+	// The query is added after validation, so the schema need not allow it.
+	out, err := builder.RenderToString(tmpl, good, "why?")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi Go, why?", out)
+
+	_, err = builder.RenderToString(tmpl, bad)
+	assert.EqualError(t, err, "invalid config "+bad+": config does not match schema: name: expected string, got integer")
+	var schemaErr *SchemaError
+	assert.ErrorAs(t, err, &schemaErr)
+	var cfgErr *ConfigError
+	assert.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, bad, cfgErr.Path)
+
+	writeTempFile(t, dir, "schema.json", `{"type": "object", "anyOf": [{"required": ["name"]}]}`)
+	log := &fakeLogger{}
+	builder.Logger = log
+	_, err = builder.RenderToString(tmpl, good)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"warning: schema for " + tmpl + ": (root): anyOf is not supported and is ignored"}, log.PrintLog)
+}
+
+func Test_Builder_Layers(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := writeTempFile(t, dir, "template.yaml", "template: '{{ audience }} / {{ tone }} / {{ concepts|join:\",\" }}'")
+	writeTempFile(t, dir, "schema.yaml", "properties:\n  tone: {enum: [friendly, formal]}\n")
+	base := writeTempFile(t, dir, "base.yaml", "audience: students\ntone: friendly\nconcepts: [a, b]\n")
+	team := writeTempFile(t, dir, "team.yaml", "audience: SREs\n")
+
+	builder := &Builder{ReadFile: os.ReadFile, LoadSchema: LoadSchemaFor, Logger: &fakeLogger{}}
+	builder.Layers = Layers{Configs: []string{team}, Sets: []string{"tone=formal", "concepts[1]=c"}}
+	out, err := builder.RenderToString(tmpl, base)
+	assert.NoError(t, err)
+	assert.Equal(t, "SREs / formal / a,c", out)
+
+	// Overrides are validated like the config itself.
+	builder.Layers.Sets = []string{"tone=rude"}
+	_, err = builder.RenderToString(tmpl, base)
+	assert.ErrorContains(t, err, "tone: must be one of friendly, formal")
+}
+
+func Test_Builder_Render_Vars(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := writeTempFile(t, dir, "template.yaml", "template: '{{ audience }} / {{ tone }} / {{ user_query }}'")
+	writeTempFile(t, dir, "schema.yaml", "properties:\n  tone: {enum: [friendly, formal]}\n")
+	base := writeTempFile(t, dir, "base.yaml", "audience: students\ntone: friendly\n")
+
+	builder := &Builder{ReadFile: os.ReadFile, LoadSchema: LoadSchemaFor, Logger: &fakeLogger{}}
+	builder.Layers = Layers{Sets: []string{"audience=SREs"}}
+	vars := map[string]any{"audience": "devs", "tone": "formal"}
+	out, err := builder.Render(context.Background(), Request{Template: tmpl, Config: base, Query: "Why?", Vars: vars})
+	assert.NoError(t, err)
+	assert.Equal(t, "SREs / formal / Why?", out)
+	assert.Nil(t, builder.Layers.Vars, "the builder itself is left unchanged")
+
+	_, err = builder.Render(context.Background(), Request{Template: tmpl, Config: base, Vars: map[string]any{"tone": "rude"}})
+	assert.ErrorContains(t, err, "tone: must be one of friendly, formal")
+}
+
+func Test_Builder_Interpolation(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := writeTempFile(t, dir, "template.tmpl", "{{ team }}: {{ guide }}")
+	writeTempFile(t, dir, "guide.md", "Be brief.")
+	cfg := writeTempFile(t, dir, "config.yaml", "team: ${TEAM_NAME}\nguide: '@file:guide.md'\n")
+	unset := writeTempFile(t, dir, "unset.yaml", "team: ${AI_EXPLORER_UNSET_VAR}\nguide: x\n")
+	t.Setenv("TEAM_NAME", "SRE")
+
+	logger := &fakeLogger{}
+	builder := &Builder{ReadFile: os.ReadFile, Logger: logger}
+	out, err := builder.RenderToString(tmpl, cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "SRE: Be brief.", out)
+
+	// Unset variables warn, and fail in strict mode.
+	out, err = builder.RenderToString(tmpl, unset)
+	assert.NoError(t, err)
+	assert.Equal(t, ": x", out)
+	assert.Equal(t, []string{"warning: " + unset + ": environment variables not set: AI_EXPLORER_UNSET_VAR"}, logger.PrintLog)
+
+	builder.Strict = true
+	_, err = builder.RenderToString(tmpl, unset)
+	var envErr *MissingEnvError
+	assert.ErrorAs(t, err, &envErr)
+	assert.Equal(t, []string{"AI_EXPLORER_UNSET_VAR"}, envErr.Names)
+
+	// LookupEnv replaces the process environment.
+	builder.LookupEnv = func(key string) (string, bool) {
+		return map[string]string{"AI_EXPLORER_UNSET_VAR": "Ops"}[key], key == "AI_EXPLORER_UNSET_VAR"
+	}
+	out, err = builder.RenderToString(tmpl, unset)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ops: x", out)
+	_, err = builder.RenderToString(tmpl, cfg)
+	assert.ErrorAs(t, err, &envErr, "TEAM_NAME is no longer visible")
 }
@@ -0,0 +1,263 @@
+// Package scaffold creates new topics under a resources tree, in the layout
+// paths.PathResolver.Derive expects, with a config skeleton inferred from
+// the topic's template.
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"raja.aiml/ai.explorer/paths"
+	"raja.aiml/ai.explorer/prompt"
+	"raja.aiml/ai.explorer/prompt/catalog"
+)
+
+// AskFunc fills in config values for the fields a template reads. defaults
+// holds the values of the topic copied with From, or is nil.
+type AskFunc func(fields []*prompt.Field, defaults map[string]any) (map[string]any, error)
+
+// Options describe the topic to create.
+type Options struct {
+	// Root is the resources tree; it defaults to paths.ResourcesDir.
+	Root     string
+	Category string
+	Topic    string
+	// From is an existing topic, as a name in Category or as
+	// category/topic. Its template is copied when the new topic needs its
+	// own, and its config is copied in place of a skeleton.
+	From string
+	// Ask, when set, fills the skeleton with values, using the From
+	// config as defaults.
+	Ask AskFunc
+}
+
+// Result lists what Create wrote.
+type Result struct {
+	Dir      string
+	Template string
+	Config   string
+	// Created lists the files written, in order.
+	Created []string
+	// Violations lists where the config does not match the template's
+	// schema, such as placeholders still to be filled in.
+	Violations []prompt.Violation
+}
+
+var writeFile = os.WriteFile // overridable for testing
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// starterTemplate is written for a new topic that needs its own template
+// and has none to copy.
+const starterTemplate = `name: %s
+description: TODO describe what this prompt asks for.
+version: 0.1.0
+
+template: |
+  {{ instructions }}
+
+  Constraints:
+  {%% for item in constraints %%}
+  - {{ item }}
+  {%% endfor %%}
+
+  {{ user_query }}
+`
+
+// Create writes the new topic's directory, its config and, when the
+// category has no shared template, its template. Nothing is written when
+// the topic already exists or any step fails.
+func Create(opts Options) (*Result, error) {
+	root := opts.Root
+	if root == "" {
+		root = paths.ResourcesDir
+	}
+	for _, name := range []string{opts.Category, opts.Topic} {
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid name %q (use letters, digits, '.', '_' and '-')", name)
+		}
+	}
+
+	resolver := paths.PathResolver{PromptCategory: opts.Category, Root: root}
+	tmplPath, cfgPath, _ := resolver.Derive(opts.Topic)
+	res := &Result{Dir: filepath.Dir(cfgPath), Template: tmplPath, Config: cfgPath}
+	if _, err := os.Stat(cfgPath); err == nil {
+		return nil, fmt.Errorf("topic %s/%s already exists: %s", opts.Category, opts.Topic, cfgPath)
+	}
+
+	from, err := findSource(root, opts.Category, opts.From)
+	if err != nil {
+		return nil, err
+	}
+
+	// The template to infer the config from, and whether it must be written.
+	tmplData, err := os.ReadFile(tmplPath)
+	writeTemplate := errors.Is(err, os.ErrNotExist)
+	switch {
+	case writeTemplate && from != nil && from.Template != "":
+		if tmplData, err = os.ReadFile(from.Template); err != nil {
+			return nil, fmt.Errorf("failed to read template file: %w", err)
+		}
+	case writeTemplate:
+		tmplData = []byte(fmt.Sprintf(starterTemplate, opts.Topic))
+	case err != nil:
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+
+	id := opts.Category + "/" + opts.Topic
+	cfgData, err := configFor(root, id, tmplPath, tmplData, from, opts.Ask)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Violations, err = violations(tmplPath, cfgData); err != nil {
+		return nil, err
+	}
+
+	if writeTemplate {
+		res.Created = append(res.Created, tmplPath)
+	}
+	res.Created = append(res.Created, cfgPath)
+	files := map[string][]byte{tmplPath: tmplData, cfgPath: cfgData}
+	if err := writeAll(res.Dir, res.Created, files); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// writeAll writes files in order into dir, creating it. When a write
+// fails it removes what it wrote, and dir if it did not exist before.
+func writeAll(dir string, order []string, files map[string][]byte) (err error) {
+	created := dir
+	for {
+		parent := filepath.Dir(created)
+		if _, statErr := os.Stat(parent); statErr == nil || parent == created {
+			break
+		}
+		created = parent
+	}
+	if _, statErr := os.Stat(dir); statErr == nil {
+		created = ""
+	}
+
+	var written []string
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, path := range written {
+			os.Remove(path)
+		}
+		if created != "" {
+			os.RemoveAll(created)
+		}
+	}()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, path := range order {
+		if err := writeFile(path, files[path], 0644); err != nil {
+			return err
+		}
+		written = append(written, path)
+	}
+	return nil
+}
+
+// violations checks cfgData against the schema next to tmplPath, if any.
+func violations(tmplPath string, cfgData []byte) ([]prompt.Violation, error) {
+	schema, err := prompt.LoadSchemaFor(os.ReadFile, tmplPath)
+	if err != nil || schema == nil {
+		return nil, err
+	}
+	var cfg map[string]any
+	if err := yaml.Unmarshal(cfgData, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if cfg == nil {
+		cfg = map[string]any{}
+	}
+	return schema.Validate(cfg), nil
+}
+
+// findSource returns the topic named by from, or nil when from is empty.
+func findSource(root, category, from string) (*catalog.Topic, error) {
+	if from == "" {
+		return nil, nil
+	}
+	if !strings.Contains(from, "/") {
+		from = category + "/" + from
+	}
+	topics, err := catalog.Scan(root)
+	if err != nil {
+		return nil, err
+	}
+	topic, err := catalog.Find(topics, from)
+	if err != nil {
+		return nil, err
+	}
+	return &topic, nil
+}
+
+// configFor returns the new topic's config: a copy of from's config, or a
+// skeleton of the template's fields filled in by ask.
+func configFor(root, id, tmplPath string, tmplData []byte, from *catalog.Topic, ask AskFunc) ([]byte, error) {
+	var fromData []byte
+	if from != nil {
+		var err error
+		if fromData, err = os.ReadFile(from.Config); err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if ask == nil {
+			return fromData, nil
+		}
+	}
+
+	// Parse the template as if it were already in place, so its includes
+	// resolve as they will when the topic renders.
+	loader := &prompt.Loader{Root: root, Origin: tmplPath, ReadFile: func(path string) ([]byte, error) {
+		if abs, err := filepath.Abs(tmplPath); err == nil && path == abs {
+			return tmplData, nil
+		}
+		return os.ReadFile(path)
+	}}
+	tpl, err := prompt.ParseTemplateIn(loader, tmplData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", tmplPath, err)
+	}
+	analysis, err := tpl.Analyze()
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze template %s: %w", tmplPath, err)
+	}
+
+	fields := analysis.Fields()
+	schema, err := prompt.LoadSchemaFor(os.ReadFile, tmplPath)
+	if err != nil {
+		return nil, err
+	}
+	prompt.ApplySchema(fields, schema)
+
+	var values map[string]any
+	if ask != nil {
+		var defaults map[string]any
+		if err := yaml.Unmarshal(fromData, &defaults); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", from.Config, err)
+		}
+		if values, err = ask(fields, defaults); err != nil {
+			return nil, err
+		}
+	}
+
+	skeleton, err := prompt.Skeleton(fields, values)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("# Config for %s, generated from %s.\n"+
+		"# Replace any TODO, then check it with `ai-explorer prompt lint`.\n\n", id, tmplPath)
+	return append([]byte(header), skeleton...), nil
+}
@@ -0,0 +1,150 @@
+package scaffold
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"raja.aiml/ai.explorer/prompt"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return root
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+var tree = map[string]string{
+	"partials/rules.tmpl":      `{% for r in rules %}- {{ r }}{% endfor %}`,
+	"topics/template.yaml":     "template: |\n  {{ topic }}\n  {% include \"partials/rules.tmpl\" %}\n  {{ tags }}\n",
+	"topics/schema.yaml":       "type: object\nproperties:\n  tags: { type: array }\n",
+	"topics/git/config.yaml":   "# Git\ntopic: Git\nrules: [Be brief]\ntags: [vcs]\n",
+	"chart/flow/template.yaml": "name: flow\ntemplate: Draw {{ diagram }}\n",
+	"chart/flow/config.yaml":   "diagram: a flowchart\n",
+}
+
+func TestCreate_SharedTemplate(t *testing.T) {
+	root := writeTree(t, tree)
+	res, err := Create(Options{Root: root, Category: "topics", Topic: "dns"})
+	require.NoError(t, err)
+
+	cfg := filepath.Join(root, "topics/dns/config.yaml")
+	assert.Equal(t, []string{cfg}, res.Created)
+	assert.Equal(t, filepath.Join(root, "topics/template.yaml"), res.Template)
+	assert.Equal(t, "# Config for topics/dns, generated from "+res.Template+`.
+# Replace any TODO, then check it with `+"`ai-explorer prompt lint`"+`.
+
+topic: "" # TODO: read on line 2
+rules: # TODO: list, looped over on line 3
+  - "" # TODO: read on line 3
+tags: # TODO: list, read on line 4
+  - ""
+`, readFile(t, cfg))
+
+	_, err = Create(Options{Root: root, Category: "topics", Topic: "dns"})
+	assert.ErrorContains(t, err, "topic topics/dns already exists")
+}
+
+func TestCreate_OwnTemplate(t *testing.T) {
+	root := writeTree(t, tree)
+
+	res, err := Create(Options{Root: root, Category: "chart", Topic: "seq"})
+	require.NoError(t, err)
+	tmpl := filepath.Join(root, "chart/seq/template.yaml")
+	assert.Equal(t, []string{tmpl, filepath.Join(root, "chart/seq/config.yaml")}, res.Created)
+	assert.Contains(t, readFile(t, tmpl), "name: seq\n")
+	assert.Contains(t, readFile(t, res.Config), "instructions: \"\" # TODO: read on line 6\n")
+
+	res, err = Create(Options{Root: root, Category: "chart", Topic: "gantt", From: "flow"})
+	require.NoError(t, err)
+	assert.Equal(t, tree["chart/flow/template.yaml"], readFile(t, res.Template))
+	assert.Equal(t, tree["chart/flow/config.yaml"], readFile(t, res.Config))
+}
+
+func TestCreate_FromWithAsk(t *testing.T) {
+	root := writeTree(t, tree)
+	var gotDefaults map[string]any
+	ask := func(fields []*prompt.Field, defaults map[string]any) (map[string]any, error) {
+		gotDefaults = defaults
+		assert.True(t, fields[2].IsList(), "schema marks tags as a list")
+		return map[string]any{"topic": "DNS", "rules": defaults["rules"]}, nil
+	}
+
+	res, err := Create(Options{Root: root, Category: "topics", Topic: "dns", From: "topics/git", Ask: ask})
+	require.NoError(t, err)
+	assert.Equal(t, "Git", gotDefaults["topic"])
+	assert.Contains(t, readFile(t, res.Config), "topic: DNS\nrules:\n  - Be brief\ntags: # TODO")
+}
+
+func TestCreate_Errors(t *testing.T) {
+	root := writeTree(t, tree)
+
+	_, err := Create(Options{Root: root, Category: "topics", Topic: "../x"})
+	assert.EqualError(t, err, `invalid name "../x" (use letters, digits, '.', '_' and '-')`)
+
+	_, err = Create(Options{Root: root, Category: "topics", Topic: "dns", From: "nope"})
+	assert.ErrorContains(t, err, `unknown topic "topics/nope"`)
+	assert.NoDirExists(t, filepath.Join(root, "topics/dns"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "topics/template.yaml"), []byte("template: '{{ oops'"), 0644))
+	_, err = Create(Options{Root: root, Category: "topics", Topic: "dns"})
+	assert.ErrorContains(t, err, "failed to parse template")
+	assert.NoDirExists(t, filepath.Join(root, "topics/dns"))
+}
+
+func TestCreate_RemovesFilesWhenAWriteFails(t *testing.T) {
+	root := writeTree(t, tree)
+	defer func(orig func(string, []byte, os.FileMode) error) { writeFile = orig }(writeFile)
+	writeFile = func(path string, data []byte, perm os.FileMode) error {
+		if filepath.Base(path) == "config.yaml" {
+			return errors.New("disk full")
+		}
+		return os.WriteFile(path, data, perm)
+	}
+
+	_, err := Create(Options{Root: root, Category: "chart", Topic: "seq"})
+	assert.EqualError(t, err, "disk full")
+	assert.NoDirExists(t, filepath.Join(root, "chart/seq"), "the template written first is removed too")
+
+	_, err = Create(Options{Root: root, Category: "charts", Topic: "seq"})
+	assert.Error(t, err)
+	assert.NoDirExists(t, filepath.Join(root, "charts"), "new parent directories are removed")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "chart/kept"), 0755))
+	_, err = Create(Options{Root: root, Category: "chart", Topic: "kept"})
+	assert.Error(t, err)
+	assert.DirExists(t, filepath.Join(root, "chart/kept"), "directories that existed are kept")
+	assert.NoFileExists(t, filepath.Join(root, "chart/kept/template.yaml"))
+}
+
+func TestCreate_ReportsSchemaViolations(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"topics/template.yaml":   "{{ topic }} {% for c in concepts %}{{ c }}{% endfor %}",
+		"topics/schema.yaml":     "required: [topic]\nproperties:\n  topic: {type: string, minLength: 1}\n  concepts: {type: array, minItems: 1}\n",
+		"topics/git/config.yaml": "topic: Git\nconcepts: [Commits]\n",
+	})
+
+	res, err := Create(Options{Root: root, Category: "topics", Topic: "dns"})
+	require.NoError(t, err)
+	assert.Equal(t, []prompt.Violation{{Path: "topic", Message: "expected at least 1 character(s), got 0"}}, res.Violations)
+	assert.FileExists(t, res.Config, "the skeleton is written anyway")
+
+	res, err = Create(Options{Root: root, Category: "topics", Topic: "bgp", From: "git"})
+	require.NoError(t, err)
+	assert.Empty(t, res.Violations)
+}
@@ -0,0 +1,437 @@
+package prompt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaFiles are the names a config schema may have, looked up next to a
+// template in this order.
+var SchemaFiles = []string{"schema.json", "schema.yaml"}
+
+// Schema is the subset of JSON Schema used to describe category configs:
+// type, enum, required, properties, additionalProperties, items, the
+// min/max item counts, minLength, maxLength, pattern, minimum and maximum.
+// Other keywords, such as $ref, oneOf or format, are listed in Ignored and
+// not checked.
+type Schema struct {
+	SchemaURI   string `yaml:"$schema,omitempty"`
+	ID          string `yaml:"$id,omitempty"`
+	Comment     string `yaml:"$comment,omitempty"`
+	Title       string `yaml:"title,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Default     any    `yaml:"default,omitempty"`
+	Examples    []any  `yaml:"examples,omitempty"`
+
+	Type                 SchemaTypes        `yaml:"type,omitempty"`
+	Enum                 []any              `yaml:"enum,omitempty"`
+	Required             []string           `yaml:"required,omitempty"`
+	Properties           map[string]*Schema `yaml:"properties,omitempty"`
+	AdditionalProperties *Additional        `yaml:"additionalProperties,omitempty"`
+	Items                *Schema            `yaml:"items,omitempty"`
+	MinItems             *int               `yaml:"minItems,omitempty"`
+	MaxItems             *int               `yaml:"maxItems,omitempty"`
+	MinLength            *int               `yaml:"minLength,omitempty"`
+	MaxLength            *int               `yaml:"maxLength,omitempty"`
+	Pattern              string             `yaml:"pattern,omitempty"`
+	Minimum              *float64           `yaml:"minimum,omitempty"`
+	Maximum              *float64           `yaml:"maximum,omitempty"`
+
+	// Ignored lists the keywords outside the supported subset, throughout
+	// the document. It is set on the schema ParseSchema returns.
+	Ignored []IgnoredKeyword `yaml:"-"`
+
+	pattern *regexp.Regexp
+	// unknown are this schema's own unsupported keywords.
+	unknown []IgnoredKeyword
+}
+
+// IgnoredKeyword is a schema keyword that is not supported, so configs are
+// not checked against it.
+type IgnoredKeyword struct {
+	// Path locates the schema that has the keyword, e.g. metadata_fields[].
+	Path    string
+	Keyword string
+	// Line is the 1-based line of the keyword in the schema file.
+	Line int
+}
+
+func (k IgnoredKeyword) String() string {
+	return fmt.Sprintf("%s: %s is not supported and is ignored", displayPath(k.Path), k.Keyword)
+}
+
+// schemaKeywords are the keys Schema decodes.
+var schemaKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "type": true, "enum": true, "required": true,
+	"properties": true, "additionalProperties": true, "items": true, "minItems": true,
+	"maxItems": true, "minLength": true, "maxLength": true, "pattern": true,
+	"minimum": true, "maximum": true,
+}
+
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	type plain Schema
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !schemaKeywords[key.Value] {
+			s.unknown = append(s.unknown, IgnoredKeyword{Keyword: key.Value, Line: key.Line})
+		}
+	}
+	return nil
+}
+
+// SchemaTypes is the `type` keyword, either a single name or a list.
+type SchemaTypes []string
+
+func (t *SchemaTypes) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = SchemaTypes{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// Additional is the `additionalProperties` keyword: false forbids unknown
+// keys, a schema validates them.
+type Additional struct {
+	Forbidden bool
+	Schema    *Schema
+}
+
+func (a *Additional) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+		var allowed bool
+		if err := node.Decode(&allowed); err != nil {
+			return err
+		}
+		a.Forbidden = !allowed
+		return nil
+	}
+	a.Schema = &Schema{}
+	return node.Decode(a.Schema)
+}
+
+var schemaTypeNames = map[string]bool{
+	"string": true, "integer": true, "number": true, "boolean": true,
+	"array": true, "object": true, "null": true,
+}
+
+// ParseSchema parses a JSON or YAML schema document. Unsupported keywords
+// are skipped and listed in the schema's Ignored field.
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, &ParseError{Line: YAMLErrorLine(err), Err: fmt.Errorf("failed to parse schema: %w", err)}
+	}
+	if err := s.compile("", &s.Ignored); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return &s, nil
+}
+
+// compile checks type names and compiles patterns throughout the schema,
+// collecting its unsupported keywords into ignored.
+func (s *Schema) compile(path string, ignored *[]IgnoredKeyword) error {
+	for _, k := range s.unknown {
+		k.Path = path
+		*ignored = append(*ignored, k)
+	}
+	for _, t := range s.Type {
+		if !schemaTypeNames[t] {
+			return fmt.Errorf("%s: unknown type %q", displayPath(path), t)
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", displayPath(path), err)
+		}
+		s.pattern = re
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := s.Properties[name].compile(joinKey(path, name), ignored); err != nil {
+			return err
+		}
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		if err := s.AdditionalProperties.Schema.compile(joinKey(path, "*"), ignored); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path+"[]", ignored)
+	}
+	return nil
+}
+
+// LoadSchemaFor loads the schema next to templatePath with readFile. It
+// returns nil when the template's directory has none.
+func LoadSchemaFor(readFile func(path string) ([]byte, error), templatePath string) (*Schema, error) {
+	path, data, err := findSchema(readFile, templatePath)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	s, err := ParseSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SchemaPathFor returns the schema file next to templatePath, if any,
+// looking it up with readFile as LoadSchemaFor does.
+func SchemaPathFor(readFile func(path string) ([]byte, error), templatePath string) (string, bool) {
+	path, _, _ := findSchema(readFile, templatePath)
+	return path, path != ""
+}
+
+// findSchema reads the first of SchemaFiles next to templatePath. path is
+// empty when there is none; a file that exists but cannot be read is
+// returned with its error.
+func findSchema(readFile func(path string) ([]byte, error), templatePath string) (path string, data []byte, err error) {
+	dir := filepath.Dir(templatePath)
+	for _, name := range SchemaFiles {
+		path := filepath.Join(dir, name)
+		data, err := readFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return path, nil, fmt.Errorf("failed to read schema file: %w", err)
+		}
+		return path, data, nil
+	}
+	return "", nil, nil
+}
+
+// Violation is a config value that does not match its schema.
+type Violation struct {
+	// Path locates the value, e.g. metadata_fields[3].type.
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return displayPath(v.Path) + ": " + v.Message
+}
+
+// SchemaError lists every schema violation in a config.
+type SchemaError struct {
+	Violations []Violation
+}
+
+func (e *SchemaError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return "config does not match schema: " + strings.Join(parts, "; ")
+}
+
+// Check validates value, returning a *SchemaError listing every violation.
+func (s *Schema) Check(value any) error {
+	if v := s.Validate(value); len(v) > 0 {
+		return &SchemaError{Violations: v}
+	}
+	return nil
+}
+
+// Validate returns every violation in value, in document order for lists
+// and sorted by key for mappings.
+func (s *Schema) Validate(value any) []Violation {
+	var out []Violation
+	s.validate("", value, &out)
+	return out
+}
+
+func (s *Schema) validate(path string, value any, out *[]Violation) {
+	fail := func(format string, args ...any) {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !s.matchesType(value) {
+		fail("expected %s, got %s", strings.Join(s.Type, " or "), typeName(value))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		fail("must be one of %s", formatEnum(s.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.MinLength != nil && len([]rune(v)) < *s.MinLength {
+			fail("expected at least %d character(s), got %d", *s.MinLength, len([]rune(v)))
+		}
+		if s.MaxLength != nil && len([]rune(v)) > *s.MaxLength {
+			fail("expected at most %d character(s), got %d", *s.MaxLength, len([]rune(v)))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("does not match pattern %s", s.Pattern)
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("expected at least %d item(s), got %d", *s.MinItems, len(v))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("expected at most %d item(s), got %d", *s.MaxItems, len(v))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, out)
+			}
+		}
+	case map[string]any:
+		s.validateObject(path, v, out)
+	default:
+		if n, ok := toFloat(value); ok {
+			if s.Minimum != nil && n < *s.Minimum {
+				fail("must be at least %g", *s.Minimum)
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				fail("must be at most %g", *s.Maximum)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateObject(path string, obj map[string]any, out *[]Violation) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			*out = append(*out, Violation{Path: joinKey(path, name), Message: "is required"})
+		}
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if prop, ok := s.Properties[k]; ok {
+			prop.validate(joinKey(path, k), obj[k], out)
+			continue
+		}
+		switch extra := s.AdditionalProperties; {
+		case extra == nil:
+		case extra.Forbidden:
+			*out = append(*out, Violation{Path: joinKey(path, k), Message: "is not allowed"})
+		case extra.Schema != nil:
+			extra.Schema.validate(joinKey(path, k), obj[k], out)
+		}
+	}
+}
+
+func (s *Schema) matchesType(value any) bool {
+	actual := typeName(value)
+	for _, t := range s.Type {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns the JSON Schema type of a decoded YAML value.
+func typeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string, time.Time:
+		return "string"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case float32, float64:
+		if f, _ := toFloat(v); f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	if _, ok := toFloat(value); ok {
+		return "integer"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func inEnum(enum []any, value any) bool {
+	for _, e := range enum {
+		if a, ok := toFloat(e); ok {
+			if b, ok := toFloat(value); ok && a == b {
+				return true
+			}
+			continue
+		}
+		if fmt.Sprint(e) == fmt.Sprint(value) && typeName(e) == typeName(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []any) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		parts[i] = fmt.Sprint(e)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
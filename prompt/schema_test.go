@@ -0,0 +1,164 @@
+package prompt
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const routerSchema = `
+type: object
+required: [tasks, metadata_fields]
+properties:
+  tasks:
+    type: array
+    minItems: 1
+    items: { type: string }
+  metadata_fields:
+    type: array
+    items:
+      type: object
+      required: [name, type]
+      additionalProperties: false
+      properties:
+        name: { type: string, pattern: "^[a-z_]+$" }
+        type: { type: string, enum: [string, list, enum] }
+        weight: { type: number, minimum: 0, maximum: 1 }
+  labels:
+    type: object
+    additionalProperties: { type: [string, "null"] }
+`
+
+func decodeConfig(t *testing.T, src string) map[string]any {
+	t.Helper()
+	var cfg map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(src), &cfg))
+	return cfg
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s, err := ParseSchema([]byte(routerSchema))
+	require.NoError(t, err)
+
+	valid := decodeConfig(t, `
+tasks: [classify]
+metadata_fields:
+  - {name: tone, type: string, weight: 0.5}
+  - {name: urgency, type: enum, weight: 1}
+labels: {a: x, b: null}
+`)
+	assert.Empty(t, s.Validate(valid))
+	assert.NoError(t, s.Check(valid))
+
+	invalid := decodeConfig(t, `
+tasks: []
+metadata_fields:
+  - {name: tone, type: string}
+  - {name: Bad-Name, type: number, weight: 2}
+  - {type: [list], extra: 1}
+  - {name: ok, type: 3}
+labels: {a: [x]}
+`)
+	var got []string
+	for _, v := range s.Validate(invalid) {
+		got = append(got, v.String())
+	}
+	assert.Equal(t, []string{
+		"labels.a: expected string or null, got array",
+		"metadata_fields[1].name: does not match pattern ^[a-z_]+$",
+		"metadata_fields[1].type: must be one of string, list, enum",
+		"metadata_fields[1].weight: must be at most 1",
+		"metadata_fields[2].name: is required",
+		"metadata_fields[2].extra: is not allowed",
+		"metadata_fields[2].type: expected string, got array",
+		"metadata_fields[3].type: expected string, got integer",
+		"tasks: expected at least 1 item(s), got 0",
+	}, got)
+
+	err = s.Check(decodeConfig(t, "tasks: [x]\n"))
+	assert.EqualError(t, err, "config does not match schema: metadata_fields: is required")
+}
+
+func TestSchema_RootAndNumbers(t *testing.T) {
+	s, err := ParseSchema([]byte(`{"type": "object", "properties": {"n": {"type": "integer"}, "f": {"type": "number"}}}`))
+	require.NoError(t, err)
+	assert.Empty(t, s.Validate(map[string]any{"n": 3, "f": 2}))
+	assert.Equal(t, []Violation{{Path: "n", Message: "expected integer, got number"}},
+		s.Validate(map[string]any{"n": 1.5}))
+	assert.Equal(t, "(root): expected object, got string", s.Validate("nope")[0].String())
+}
+
+func TestParseSchema_IgnoresUnsupportedKeywords(t *testing.T) {
+	s, err := ParseSchema([]byte(`{
+  "$defs": {"name": {"type": "string"}},
+  "type": "object",
+  "properties": {
+    "name": {"$ref": "#/$defs/name", "maxLength": 5},
+    "tags": {"items": {"anyOf": [{"type": "string"}], "format": "hostname"}}
+  }
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, []IgnoredKeyword{
+		{Path: "", Keyword: "$defs", Line: 2},
+		{Path: "name", Keyword: "$ref", Line: 5},
+		{Path: "tags[]", Keyword: "anyOf", Line: 6},
+		{Path: "tags[]", Keyword: "format", Line: 6},
+	}, s.Ignored)
+	assert.Equal(t, "name: $ref is not supported and is ignored", s.Ignored[1].String())
+
+	assert.Equal(t, []Violation{{Path: "name", Message: "expected at most 5 character(s), got 6"}},
+		s.Validate(map[string]any{"name": "router", "tags": []any{1}}), "maxLength is checked, anyOf is not")
+}
+
+func TestParseSchema_Errors(t *testing.T) {
+	_, err := ParseSchema([]byte("type: object\nminItems: two\n"))
+	assert.ErrorContains(t, err, "failed to parse schema")
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+	assert.Equal(t, 2, perr.Line)
+
+	_, err = ParseSchema([]byte("properties:\n  a: {type: text}\n"))
+	assert.EqualError(t, err, `failed to parse schema: a: unknown type "text"`)
+
+	_, err = ParseSchema([]byte("items: {pattern: '('}\n"))
+	assert.ErrorContains(t, err, "[]: invalid pattern")
+}
+
+func TestLoadSchemaFor(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "template.yaml")
+
+	s, err := LoadSchemaFor(os.ReadFile, tmpl)
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.yaml"), []byte("type: object\n"), 0644))
+	s, err = LoadSchemaFor(os.ReadFile, tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaTypes{"object"}, s.Type)
+
+	// schema.json wins over schema.yaml.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.json"), []byte(`{"type": "array"`), 0644))
+	_, err = LoadSchemaFor(os.ReadFile, tmpl)
+	assert.ErrorContains(t, err, filepath.Join(dir, "schema.json")+": failed to parse schema")
+
+	readFile := func(path string) ([]byte, error) {
+		if path == filepath.Join("mem", "schema.yaml") {
+			return []byte("type: array\n"), nil
+		}
+		return nil, fs.ErrNotExist
+	}
+	s, err = LoadSchemaFor(readFile, filepath.Join("mem", "template.yaml"))
+	require.NoError(t, err, "the schema is read with readFile, not from disk")
+	assert.Equal(t, SchemaTypes{"array"}, s.Type)
+	path, ok := SchemaPathFor(readFile, filepath.Join("mem", "template.yaml"))
+	assert.True(t, ok, "lookup agrees with loading")
+	assert.Equal(t, filepath.Join("mem", "schema.yaml"), path)
+	_, ok = SchemaPathFor(readFile, filepath.Join("other", "template.yaml"))
+	assert.False(t, ok)
+}
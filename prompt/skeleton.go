@@ -0,0 +1,287 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Field is a config key a template reads, with the keys read beneath it.
+type Field struct {
+	// Key is the map key, "[]" for every element of a list, an index such
+	// as "0" for one element, or "*" for every value of a map.
+	Key      string
+	Children []*Field
+	// Line is the first template line that reads the key.
+	Line int
+	// Loop is set when a for tag loops over the value.
+	Loop bool
+	// Whole is set when the template prints or tests the value itself.
+	Whole bool
+	// Optional is set when every read has a default or an if guard.
+	Optional bool
+	// List is set when the config schema declares the value an array.
+	List bool
+}
+
+// IsList reports whether the value is a list.
+func (f *Field) IsList() bool {
+	if f.List {
+		return true
+	}
+	for _, c := range f.Children {
+		if c.Key == eachElement || isIndex(c.Key) {
+			return true
+		}
+	}
+	return f.Loop && len(f.Children) == 0
+}
+
+// IsMap reports whether the value is a map with named keys.
+func (f *Field) IsMap() bool {
+	return !f.IsList() && len(f.Children) > 0 && f.Child(eachValue) == nil
+}
+
+// Child returns the child with the given key, or nil.
+func (f *Field) Child(key string) *Field {
+	for _, c := range f.Children {
+		if c.Key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// Fields returns the config keys the template reads as a tree, in the
+// order the template first reads them. QueryKey is left out, as it comes
+// from --query rather than the config.
+func (a *Analysis) Fields() []*Field {
+	root := &Field{}
+	direct := map[*Field]bool{}
+	for _, r := range a.Refs {
+		if len(r.Path) == 0 || r.Path[0] == QueryKey {
+			continue
+		}
+		f := root
+		for _, seg := range r.Path {
+			child := f.Child(seg)
+			if child == nil {
+				child = &Field{Key: seg, Line: r.Line, Optional: true}
+				f.Children = append(f.Children, child)
+			}
+			if r.Line < child.Line {
+				child.Line = r.Line
+			}
+			f = child
+		}
+		f.Loop = f.Loop || r.Loop
+		f.Whole = f.Whole || r.Whole
+		f.Optional = f.Optional && r.Optional
+		direct[f] = true
+	}
+	settle(root, direct)
+	return root.Children
+}
+
+// ApplySchema marks the fields schema declares as arrays as lists. A
+// template may only read a list whole, for instance by passing it to a
+// macro, so the analysis alone cannot tell.
+func ApplySchema(fields []*Field, schema *Schema) {
+	if schema == nil {
+		return
+	}
+	for _, f := range fields {
+		applySchema(f, schema.Properties[f.Key])
+	}
+}
+
+func applySchema(f *Field, s *Schema) {
+	if s == nil {
+		return
+	}
+	for _, t := range s.Type {
+		if t == "array" {
+			f.List = true
+		}
+	}
+	for _, c := range f.Children {
+		switch {
+		case c.Key == eachElement || isIndex(c.Key):
+			applySchema(c, s.Items)
+		case c.Key == eachValue:
+			if s.AdditionalProperties != nil {
+				applySchema(c, s.AdditionalProperties.Schema)
+			}
+		default:
+			applySchema(c, s.Properties[c.Key])
+		}
+	}
+}
+
+// settle orders children by first read and marks keys that are only read
+// through their children optional when all those children are.
+func settle(f *Field, direct map[*Field]bool) bool {
+	sort.SliceStable(f.Children, func(i, j int) bool {
+		return f.Children[i].Line < f.Children[j].Line
+	})
+	optional := true
+	for _, c := range f.Children {
+		optional = settle(c, direct) && optional
+	}
+	if !direct[f] {
+		f.Optional = optional
+	}
+	return f.Optional
+}
+
+// Skeleton returns a config with every key in fields. Keys with a value
+// in values get that value; the rest get an empty placeholder and a
+// comment saying where the template reads them.
+func Skeleton(fields []*Field, values map[string]any) ([]byte, error) {
+	root, err := skeletonMap(fields, values)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func skeletonMap(fields []*Field, values map[string]any) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range fields {
+		value, ok := values[f.Key]
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: f.Key}
+		val, err := skeletonValue(f, value, ok)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Key, err)
+		}
+		// Comment collections on the key line, scalars after the value.
+		if val.Kind != yaml.ScalarNode {
+			key.LineComment, val.LineComment = val.LineComment, ""
+		}
+		node.Content = append(node.Content, key, val)
+	}
+	return node, nil
+}
+
+// skeletonValue builds the node for f from value, when ok, or from
+// placeholders. A placeholder's comment is set on the returned node;
+// skeletonMap moves it to the key line for collections.
+func skeletonValue(f *Field, value any, ok bool) (*yaml.Node, error) {
+	if ok && (f.Whole || len(f.Children) == 0) && !f.Loop {
+		return encodeValue(value)
+	}
+
+	switch {
+	case f.IsList():
+		items, _ := value.([]any)
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		count := len(items)
+		for _, c := range f.Children {
+			if n, err := strconv.Atoi(c.Key); err == nil && n >= count {
+				count = n + 1
+			}
+		}
+		placeholder := count == 0
+		if placeholder {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			elem := f.Child(strconv.Itoa(i))
+			if elem == nil {
+				elem = f.Child(eachElement)
+			}
+			if elem == nil {
+				elem = &Field{}
+			}
+			var item any
+			has := i < len(items)
+			if has {
+				item = items[i]
+			}
+			child, err := skeletonValue(elem, item, has)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			node.Content = append(node.Content, child)
+		}
+		if placeholder {
+			node.LineComment = placeholderComment(f, "list")
+		}
+		return node, nil
+
+	case f.Child(eachValue) != nil:
+		entries, _ := value.(map[string]any)
+		elem := f.Child(eachValue)
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := make([]string, 0, len(entries))
+		for k := range entries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if len(keys) == 0 {
+			node.LineComment = placeholderComment(f, "map")
+			keys = []string{"key"}
+		}
+		for _, k := range keys {
+			v, has := entries[k]
+			child, err := skeletonValue(elem, v, has)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, child)
+		}
+		return node, nil
+
+	case len(f.Children) > 0:
+		entries, _ := value.(map[string]any)
+		return skeletonMap(f.Children, entries)
+	}
+
+	return &yaml.Node{
+		Kind:        yaml.ScalarNode,
+		Style:       yaml.DoubleQuotedStyle,
+		Tag:         "!!str",
+		LineComment: placeholderComment(f, ""),
+	}, nil
+}
+
+func encodeValue(value any) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// placeholderComment says where the template reads a placeholder, e.g.
+// "TODO: list, looped over on line 12". Elements of a list the template
+// never indexes into get none; the list's comment covers them.
+func placeholderComment(f *Field, kind string) string {
+	if f.Line == 0 {
+		return ""
+	}
+	todo := "TODO: "
+	if f.Optional {
+		todo = "optional, "
+	}
+	if kind != "" {
+		todo += kind + ", "
+	}
+	read := "read"
+	if f.Loop {
+		read = "looped over"
+	}
+	return fmt.Sprintf("%s%s on line %d", todo, read, f.Line)
+}
@@ -0,0 +1,112 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const skeletonTemplate = `template: |
+  {{ topic }} for {{ audience|default:"everyone" }}
+  {% for task in tasks %}
+  - {{ task.title }}: {{ task.description }}
+  {% endfor %}
+  {% if meta.tone %}{{ meta.tone }}{% endif %}
+  {{ first.0 }} {{ notes }} {{ user_query }}
+`
+
+func skeletonFields(t *testing.T) []*Field {
+	t.Helper()
+	tpl, err := ParseTemplate([]byte(skeletonTemplate))
+	require.NoError(t, err)
+	a, err := tpl.Analyze()
+	require.NoError(t, err)
+	return a.Fields()
+}
+
+func TestAnalysis_Fields(t *testing.T) {
+	fields := skeletonFields(t)
+
+	var keys []string
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	assert.Equal(t, []string{"topic", "audience", "tasks", "meta", "first", "notes"}, keys)
+
+	audience, tasks, meta, first := fields[1], fields[2], fields[3], fields[4]
+	assert.True(t, audience.Optional)
+	assert.Equal(t, 2, audience.Line)
+
+	assert.True(t, tasks.Loop)
+	assert.True(t, tasks.IsList())
+	elem := tasks.Child("[]")
+	require.NotNil(t, elem)
+	assert.NotNil(t, elem.Child("title"))
+	assert.NotNil(t, elem.Child("description"))
+
+	assert.True(t, meta.IsMap())
+	assert.True(t, meta.Optional)
+	assert.True(t, first.IsList())
+	assert.False(t, fields[5].IsList())
+}
+
+func TestApplySchema(t *testing.T) {
+	fields := skeletonFields(t)
+	schema, err := ParseSchema([]byte(`
+type: object
+properties:
+  notes: { type: array, items: { type: string } }
+  tasks: { type: array, items: { type: object, properties: { title: { type: [string, "null"] } } } }
+`))
+	require.NoError(t, err)
+
+	ApplySchema(fields, schema)
+	assert.True(t, fields[5].List)
+	assert.True(t, fields[5].IsList())
+	assert.False(t, fields[2].Child("[]").Child("title").List)
+}
+
+func TestSkeleton_Placeholders(t *testing.T) {
+	out, err := Skeleton(skeletonFields(t), nil)
+	require.NoError(t, err)
+	assert.Equal(t, `topic: "" # TODO: read on line 2
+audience: "" # optional, read on line 2
+tasks: # TODO: list, looped over on line 3
+  - title: "" # TODO: read on line 4
+    description: "" # TODO: read on line 4
+meta:
+  tone: "" # optional, read on line 6
+first:
+  - "" # TODO: read on line 7
+notes: "" # TODO: read on line 7
+`, string(out))
+}
+
+func TestSkeleton_Values(t *testing.T) {
+	out, err := Skeleton(skeletonFields(t), map[string]any{
+		"topic": "BGP",
+		"tasks": []any{
+			map[string]any{"title": "Peers"},
+			map[string]any{"title": "Routes", "description": "How routes spread"},
+		},
+		"meta":  map[string]any{"tone": "dry"},
+		"notes": []any{"a", "b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `topic: BGP
+audience: "" # optional, read on line 2
+tasks:
+  - title: Peers
+    description: "" # TODO: read on line 4
+  - title: Routes
+    description: How routes spread
+meta:
+  tone: dry
+first:
+  - "" # TODO: read on line 7
+notes:
+  - a
+  - b
+`, string(out))
+}
@@ -0,0 +1,362 @@
+package prompt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flosch/pongo2/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateSpec is the YAML layout of a template file. The prompt body is
+// either a single `template` or a `user` section, optionally preceded by a
+// `system` section.
+type TemplateSpec struct {
+	Name        string      `yaml:"name,omitempty"`
+	Description string      `yaml:"description,omitempty"`
+	Version     string      `yaml:"version,omitempty"`
+	Required    []string    `yaml:"required,omitempty"`
+	Recommended Recommended `yaml:"recommended,omitempty"`
+	Template    string      `yaml:"template,omitempty"`
+	System      string      `yaml:"system,omitempty"`
+	User        string      `yaml:"user,omitempty"`
+}
+
+// Recommended is the model a template was written for. Commands use it
+// unless the matching flag is set.
+type Recommended struct {
+	Provider    string   `yaml:"provider,omitempty"`
+	Model       string   `yaml:"model,omitempty"`
+	Temperature *float64 `yaml:"temperature,omitempty"`
+}
+
+// IsZero reports whether no recommendation was declared.
+func (r Recommended) IsZero() bool {
+	return r.Provider == "" && r.Model == "" && r.Temperature == nil
+}
+
+// Template is a parsed template file.
+type Template struct {
+	Spec TemplateSpec
+	// Plain is set for templates without YAML metadata, whose whole file
+	// is the prompt body.
+	Plain bool
+
+	system *pongo2.Template
+	user   *pongo2.Template
+	// sections holds the pongo2 source of each section, for analysis.
+	sections []section
+	// loader resolves includes, or is nil for the default pongo2 set.
+	loader *Loader
+}
+
+// section is a pongo2 source and where in the file its text starts.
+type section struct {
+	src  string
+	line int
+	// indent is the number of file columns before the text: the
+	// indentation of a block scalar, on every line, or the offset of a
+	// one-line value, on its first line only.
+	indent int
+	block  bool
+}
+
+// column maps a 1-based column on a line of the section to the file.
+func (s section) column(line, col int) int {
+	if col > 0 && (line == 1 || s.block) {
+		return col + s.indent
+	}
+	return col
+}
+
+// ParseError is a template file that failed to parse, located in the file
+// when possible.
+type ParseError struct {
+	// File is the template file, when known.
+	File string
+	// Line is the 1-based line in the template file, or 0 when unknown.
+	Line int
+	// Column is the 1-based column on Line, or 0 when unknown.
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// YAMLErrorLine extracts the line number from a YAML error, or returns 0.
+func YAMLErrorLine(err error) int {
+	if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// parseSection compiles a section, reporting errors at their file line.
+// Errors inside included files keep pongo2's file and line in the message.
+func parseSection(loader *Loader, sec section, what string) (*pongo2.Template, error) {
+	set := pongo2.DefaultSet
+	if loader != nil {
+		if err := loader.checkCycles(sec); err != nil {
+			return nil, err
+		}
+		set = loader.set()
+	}
+	tpl, err := set.FromString(sec.src)
+	if err != nil {
+		parseErr := &ParseError{Err: fmt.Errorf("failed to parse %s: %w", what, err)}
+		var perr *pongo2.Error
+		if errors.As(err, &perr) && perr.Line > 0 && perr.Filename == "<string>" {
+			parseErr.Line = sec.line + perr.Line - 1
+			parseErr.Column = sec.column(perr.Line, perr.Column)
+		}
+		return nil, parseErr
+	}
+	return tpl, nil
+}
+
+// Rendered is a rendered prompt, split into its system and user parts.
+type Rendered struct {
+	System string
+	User   string
+}
+
+// String joins the system and user parts into a single prompt.
+func (r Rendered) String() string {
+	if r.System == "" {
+		return r.User
+	}
+	return strings.TrimRight(r.System, "\n") + "\n\n" + r.User
+}
+
+// sectionKeys are the top-level keys that mark a file as a YAML template.
+var sectionKeys = []string{"template", "system", "user"}
+
+// ParseTemplate parses a template file. Files that are a YAML mapping with a
+// `template`, `system` or `user` key are read as a TemplateSpec; anything
+// else is a plain-text template. Includes resolve from the working
+// directory; use ParseTemplateIn to resolve them from the template.
+func ParseTemplate(data []byte) (*Template, error) {
+	return ParseTemplateIn(nil, data)
+}
+
+// ParseTemplateIn parses a template file whose includes, extends and
+// imports are loaded through loader.
+func ParseTemplateIn(loader *Loader, data []byte) (*Template, error) {
+	var root yaml.Node
+	if !isTemplateSpec(data, &root) {
+		sec := section{src: string(data), line: 1}
+		tpl, err := parseSection(loader, sec, "template")
+		if err != nil {
+			return nil, err
+		}
+		return &Template{Plain: true, user: tpl, sections: []section{sec}, loader: loader}, nil
+	}
+
+	var spec TemplateSpec
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&spec); err != nil && !errors.Is(err, io.EOF) {
+		return nil, &ParseError{Line: YAMLErrorLine(err), Err: fmt.Errorf("failed to parse template metadata: %w", err)}
+	}
+
+	body := spec.Template
+	switch {
+	case spec.Template != "" && spec.User != "":
+		return nil, &ParseError{Err: errors.New("failed to parse template metadata: use either `template` or `user`, not both")}
+	case spec.Template == "" && spec.User == "":
+		return nil, &ParseError{Err: errors.New("failed to parse template metadata: missing `template` or `user` section")}
+	case spec.User != "":
+		body = spec.User
+	}
+
+	bodyKey := "template"
+	if spec.User != "" {
+		bodyKey = "user"
+	}
+	user := locateSection(&root, data, bodyKey, body)
+	system := locateSection(&root, data, "system", spec.System)
+
+	t := &Template{Spec: spec, sections: []section{system, user}, loader: loader}
+	var err error
+	if t.user, err = parseSection(loader, user, "template"); err != nil {
+		return nil, err
+	}
+	if spec.System != "" {
+		if t.system, err = parseSection(loader, system, "system section"); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// locateSection returns the section holding src, the value of key, with
+// the file position its text starts at. Block scalars (| and >) start on
+// the line after their key.
+func locateSection(root *yaml.Node, data []byte, key, src string) section {
+	sec := section{src: src, line: 1}
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != key {
+			continue
+		}
+		value := root.Content[i+1]
+		sec.line = value.Line
+		switch {
+		case value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+			sec.line++
+			sec.block = true
+			sec.indent = blockIndent(data, sec.line)
+		case value.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0:
+			sec.indent = value.Column
+		default:
+			sec.indent = value.Column - 1
+		}
+		break
+	}
+	return sec
+}
+
+// blockIndent returns the indentation of the first non-blank line of data
+// from the 1-based line on.
+func blockIndent(data []byte, line int) int {
+	lines := strings.Split(string(data), "\n")
+	for i := line - 1; i >= 0 && i < len(lines); i++ {
+		if text := strings.TrimRight(lines[i], "\r"); strings.TrimSpace(text) != "" {
+			return len(text) - len(strings.TrimLeft(text, " "))
+		}
+	}
+	return 0
+}
+
+// isTemplateSpec reports whether data is a YAML mapping with a section key,
+// leaving the parsed document in root.
+func isTemplateSpec(data []byte, root *yaml.Node) bool {
+	var top map[string]any
+	if err := yaml.Unmarshal(data, root); err != nil || root.Decode(&top) != nil {
+		return false
+	}
+	for _, key := range sectionKeys {
+		if _, ok := top[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns the required variables absent from ctx, sorted.
+func (t *Template) Missing(ctx pongo2.Context) []string {
+	var missing []string
+	for _, name := range t.Spec.Required {
+		if _, ok := ctx[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Render renders the template's sections with ctx.
+func (t *Template) Render(ctx pongo2.Context) (Rendered, error) {
+	if missing := t.Missing(ctx); len(missing) > 0 {
+		return Rendered{}, fmt.Errorf("missing required variables: %s", strings.Join(missing, ", "))
+	}
+	var out Rendered
+	var err error
+	if t.system != nil {
+		if out.System, err = t.system.Execute(ctx); err != nil {
+			return Rendered{}, err
+		}
+	}
+	if out.User, err = t.user.Execute(ctx); err != nil {
+		return Rendered{}, err
+	}
+	return out, nil
+}
+
+// Execute renders the template into a single prompt string.
+func (t *Template) Execute(ctx pongo2.Context) (string, error) {
+	out, err := t.Render(ctx)
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// Analyze statically lists the config paths the template reads.
+func (t *Template) Analyze() (*Analysis, error) {
+	combined := &Analysis{}
+	for _, sec := range t.sections {
+		a, err := analyzeWith(t.loader, t.origin(), sec.src)
+		if err != nil {
+			var perr *ParseError
+			if errors.As(err, &perr) {
+				return nil, &ParseError{Line: perr.Line + sec.line - 1, Err: perr.Err}
+			}
+			return nil, err
+		}
+		for _, r := range a.Refs {
+			r.Line += sec.line - 1
+			combined.Refs = append(combined.Refs, r)
+		}
+	}
+	return combined, nil
+}
+
+func (t *Template) origin() string {
+	if t.loader == nil {
+		return ""
+	}
+	return t.loader.Origin
+}
+
+// StrictError lists the mismatches strict rendering found between a
+// template and its config.
+type StrictError struct {
+	Missing []string
+	Unused  []string
+}
+
+func (e *StrictError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing from config: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unused) > 0 {
+		parts = append(parts, "unused by template: "+strings.Join(e.Unused, ", "))
+	}
+	return "strict mode: " + strings.Join(parts, "; ")
+}
+
+// CheckStrict fails with a *StrictError when the template reads paths ctx
+// lacks or ctx has keys the template never reads. QueryKey is never
+// reported as unused: it holds the --query text, not a config key.
+func (t *Template) CheckStrict(ctx pongo2.Context) error {
+	analysis, err := t.Analyze()
+	if err != nil {
+		return fmt.Errorf("failed to analyze template: %w", err)
+	}
+	missing, unused := analysis.Check(ctx)
+	unused = slices.DeleteFunc(unused, func(key string) bool { return key == QueryKey })
+	if len(missing) > 0 || len(unused) > 0 {
+		return &StrictError{Missing: missing, Unused: unused}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/flosch/pongo2/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplate_PlainText(t *testing.T) {
+	for _, src := range []string{
+		"Hello {{ name }}",
+		"{{ greeting }}, {{ name }}",
+		"Question: {{ q }}\nAnswer briefly.",
+	} {
+		tpl, err := ParseTemplate([]byte(src))
+		require.NoError(t, err, src)
+		assert.True(t, tpl.Plain, src)
+	}
+
+	tpl, _ := ParseTemplate([]byte("Hello {{ name }}"))
+	out, err := tpl.Execute(pongo2.Context{"name": "Go"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello Go", out)
+}
+
+func TestParseTemplate_Metadata(t *testing.T) {
+	src := `# Banner comments are YAML comments, not prompt text.
+name: greeter
+description: Says hello
+version: 1.0.0
+required: [name]
+recommended:
+  provider: openai
+  model: gpt-4o-mini
+  temperature: 0.2
+template: |
+  Hello {{ name }}
+`
+	tpl, err := ParseTemplate([]byte(src))
+	require.NoError(t, err)
+	assert.False(t, tpl.Plain)
+	assert.Equal(t, "greeter", tpl.Spec.Name)
+	assert.Equal(t, "1.0.0", tpl.Spec.Version)
+	assert.Equal(t, "openai", tpl.Spec.Recommended.Provider)
+	assert.Equal(t, "gpt-4o-mini", tpl.Spec.Recommended.Model)
+	assert.Equal(t, 0.2, *tpl.Spec.Recommended.Temperature)
+
+	out, err := tpl.Execute(pongo2.Context{"name": "Go"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello Go\n", out)
+
+	_, err = tpl.Execute(pongo2.Context{})
+	assert.EqualError(t, err, "missing required variables: name")
+}
+
+func TestParseTemplate_SystemAndUser(t *testing.T) {
+	tpl, err := ParseTemplate([]byte("system: You answer about {{ topic }}.\nuser: Explain {{ topic }}.\n"))
+	require.NoError(t, err)
+
+	out, err := tpl.Render(pongo2.Context{"topic": "BGP"})
+	assert.NoError(t, err)
+	assert.Equal(t, Rendered{System: "You answer about BGP.", User: "Explain BGP."}, out)
+	assert.Equal(t, "You answer about BGP.\n\nExplain BGP.", out.String())
+	assert.True(t, tpl.Spec.Recommended.IsZero())
+}
+
+func TestParseTemplate_Errors(t *testing.T) {
+	cases := map[string]string{
+		"template: a\nuser: b\n":          "either `template` or `user`",
+		"system: only a system prompt\n":  "missing `template` or `user`",
+		"template: hi\nmodle: phi4\n":     "field modle not found",
+		"template: \"{{ broken\"\n":       "failed to parse template",
+		"system: \"{% if %}\"\nuser: x\n": "failed to parse system section",
+	}
+	for src, want := range cases {
+		_, err := ParseTemplate([]byte(src))
+		assert.ErrorContains(t, err, want, src)
+	}
+}
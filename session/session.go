@@ -0,0 +1,199 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"raja.aiml/ai.explorer/llm"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+// DefaultDir is where sessions are stored unless overridden.
+const DefaultDir = ".ai-explorer/sessions"
+
+// ErrNotFound is returned when a session file does not exist.
+var ErrNotFound = errors.New("session not found")
+
+// validName restricts session names to safe file names.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Message is a single persisted chat turn.
+type Message struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	// Backend is the provider:model that wrote an assistant message. It
+	// differs from the session's when a fallback answered.
+	Backend string `json:"backend,omitempty"`
+}
+
+// Session is a named, resumable conversation.
+type Session struct {
+	Name        string    `json:"name"`
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model"`
+	Temperature float64   `json:"temperature"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Messages    []Message `json:"messages"`
+}
+
+// Append records a new message at the given time.
+func (s *Session) Append(role wrapper.ChatMessageType, content string, at time.Time) {
+	s.Messages = append(s.Messages, Message{Role: string(role), Content: content, CreatedAt: at})
+}
+
+// AppendReply records an assistant message written by backend.
+func (s *Session) AppendReply(content, backend string, at time.Time) {
+	s.Messages = append(s.Messages, Message{Role: string(wrapper.RoleAssistant), Content: content, CreatedAt: at, Backend: backend})
+}
+
+// History converts the stored messages into a chat history for a model call.
+func (s *Session) History() *llm.History {
+	h := llm.NewHistory("")
+	for _, m := range s.Messages {
+		switch wrapper.ChatMessageType(m.Role) {
+		case wrapper.RoleSystem:
+			h.SetSystem(m.Content)
+		case wrapper.RoleAssistant:
+			h.AddAssistant(m.Content)
+		default:
+			h.AddUser(m.Content)
+		}
+	}
+	return h
+}
+
+// Transcript renders the session as Markdown with a metadata header.
+func (s *Session) Transcript() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Session: %s\n\n", s.Name)
+	fmt.Fprintf(&sb, "- Provider: %s\n- Model: %s\n- Temperature: %g\n", s.Provider, s.Model, s.Temperature)
+	fmt.Fprintf(&sb, "- Created: %s\n- Updated: %s\n\n", s.CreatedAt.Format(time.RFC3339), s.UpdatedAt.Format(time.RFC3339))
+	sb.WriteString(s.History().Transcript())
+	return sb.String()
+}
+
+// Store persists sessions as one JSON file per session in Dir.
+type Store struct {
+	Dir string
+	Now func() time.Time
+}
+
+// NewStore returns a store rooted at dir, falling back to DefaultDir.
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &Store{Dir: dir, Now: time.Now}
+}
+
+// path returns the file backing the named session.
+func (s *Store) path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid session name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	return filepath.Join(s.Dir, name+".json"), nil
+}
+
+// Load reads a session by name.
+func (s *Store) Load(name string) (*Session, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var sess Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", name, err)
+	}
+	return &sess, nil
+}
+
+// Open loads a session or starts a new, unsaved one.
+func (s *Store) Open(name string) (sess *Session, created bool, err error) {
+	sess, err = s.Load(name)
+	if errors.Is(err, ErrNotFound) {
+		now := s.Now()
+		return &Session{Name: name, CreatedAt: now, UpdatedAt: now}, true, nil
+	}
+	return sess, false, err
+}
+
+// Save writes the session, stamping its update time.
+func (s *Store) Save(sess *Session) error {
+	path, err := s.path(sess.Name)
+	if err != nil {
+		return err
+	}
+	sess.UpdatedAt = s.Now()
+
+	data, err := json.MarshalIndent(sess, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.MkdirAll(s.Dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	// Write through a temp file so an interrupted save never truncates a session.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Delete removes a session file.
+func (s *Store) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	} else if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// List returns every stored session, most recently updated first.
+func (s *Store) List() ([]*Session, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var sessions []*Session
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		sess, err := s.Load(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
@@ -0,0 +1,101 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"raja.aiml/ai.explorer/llm/wrapper"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	clock := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	store := NewStore(filepath.Join(t.TempDir(), "sessions"))
+	store.Now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	return store
+}
+
+func TestStore_OpenSaveLoad(t *testing.T) {
+	store := newTestStore(t)
+
+	sess, created, err := store.Open("net-review")
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	sess.Provider, sess.Model, sess.Temperature = "ollama", "phi4", 0.2
+	sess.Append(wrapper.RoleSystem, "be brief", store.Now())
+	sess.Append(wrapper.RoleUser, "what is OSPF?", store.Now())
+	sess.AppendReply("a link-state protocol", "ollama:llama3", store.Now())
+	assert.NoError(t, store.Save(sess))
+
+	loaded, created, err := store.Open("net-review")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "phi4", loaded.Model)
+	assert.Equal(t, 0.2, loaded.Temperature)
+	assert.Len(t, loaded.Messages, 3)
+	assert.Equal(t, "ollama:llama3", loaded.Messages[2].Backend)
+	assert.Empty(t, loaded.Messages[1].Backend)
+	assert.True(t, loaded.UpdatedAt.After(loaded.CreatedAt))
+
+	history := loaded.History()
+	assert.Equal(t, "be brief", history.System())
+	assert.Equal(t, 3, history.Len())
+	assert.Equal(t, wrapper.RoleAssistant, history.Messages()[2].Role)
+}
+
+func TestStore_ListSortsByUpdate(t *testing.T) {
+	store := newTestStore(t)
+	for _, name := range []string{"first", "second"} {
+		sess, _, _ := store.Open(name)
+		assert.NoError(t, store.Save(sess))
+	}
+
+	sessions, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, "second", sessions[0].Name)
+}
+
+func TestStore_ListMissingDir(t *testing.T) {
+	sessions, err := NewStore(filepath.Join(t.TempDir(), "nope")).List()
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestStore_Delete(t *testing.T) {
+	store := newTestStore(t)
+	sess, _, _ := store.Open("tmp")
+	assert.NoError(t, store.Save(sess))
+
+	assert.NoError(t, store.Delete("tmp"))
+	_, err := os.Stat(filepath.Join(store.Dir, "tmp.json"))
+	assert.True(t, os.IsNotExist(err))
+	assert.ErrorIs(t, store.Delete("tmp"), ErrNotFound)
+}
+
+func TestStore_RejectsUnsafeNames(t *testing.T) {
+	store := newTestStore(t)
+	for _, name := range []string{"", "../escape", "a/b", ".hidden"} {
+		_, err := store.Load(name)
+		assert.ErrorContains(t, err, "invalid session name", name)
+	}
+}
+
+func TestSession_Transcript(t *testing.T) {
+	sess := &Session{Name: "demo", Provider: "ollama", Model: "phi4", Temperature: 0.8}
+	sess.Append(wrapper.RoleUser, "hi", time.Time{})
+	sess.Append(wrapper.RoleAssistant, "hello", time.Time{})
+
+	out := sess.Transcript()
+	assert.Contains(t, out, "# Session: demo")
+	assert.Contains(t, out, "- Model: phi4")
+	assert.Contains(t, out, "### User\n\nhi")
+	assert.Contains(t, out, "### Assistant\n\nhello")
+}
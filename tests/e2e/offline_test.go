@@ -0,0 +1,230 @@
+package e2e_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	mockFixtures   = "tests/e2e/testdata/mock.yaml"
+	bgpPrompt      = "tests/e2e/testdata/bgp-prompt.txt"
+	overloadPrompt = "tests/e2e/testdata/overload-prompt.txt"
+	timeoutPrompt  = "tests/e2e/testdata/timeout-once-prompt.txt"
+	hangPrompt     = "tests/e2e/testdata/hang-prompt.txt"
+	tinyWindows    = "tests/e2e/testdata/tiny-windows.yaml"
+	recommendDir   = "tests/e2e/testdata/recommend"
+	ollamaCassette = "tests/e2e/testdata/cassettes/ollama"
+	offlineDir     = ".build/e2e-offline"
+)
+
+var _ = Describe("Offline LLM providers (E2E)", Ordered, func() {
+	paths := &TestPaths{RootDir: rootDir, BinPath: filepath.Join(".build", binaryName)}
+	cacheDir := filepath.Join(offlineDir, "cache")
+	reflectorAnswer := "A route reflector lets iBGP peers skip the full mesh"
+
+	BeforeAll(func() {
+		Expect(os.RemoveAll(filepath.Join(rootDir, offlineDir))).To(Succeed())
+		createDir(filepath.Join(rootDir, offlineDir))
+	})
+
+	It("echoes the prompt with the echo provider", func() {
+		output := filepath.Join(offlineDir, "echo.md")
+		out, err := runCommand(paths, "llm", "--provider", "echo", "--prompt", bgpPrompt, "--output", output)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+
+		want, err := os.ReadFile(filepath.Join(rootDir, bgpPrompt))
+		Expect(err).ToNot(HaveOccurred())
+		got, err := os.ReadFile(filepath.Join(rootDir, output))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(got)).To(Equal(string(want)))
+		Expect(string(out)).To(ContainSubstring("Explain BGP route reflectors"))
+	})
+
+	It("answers from fixtures with the mock provider and caches the response", func() {
+		out, err := runCommand(paths, "llm", "--provider", "mock", "--model", mockFixtures,
+			"--prompt", bgpPrompt, "--cache", "--cache-dir", cacheDir)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring(reflectorAnswer))
+	})
+
+	It("plays back the recorded response with the replay provider", func() {
+		out, err := runCommand(paths, "llm", "--provider", "replay", "--model", cacheDir, "--prompt", bgpPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring(reflectorAnswer))
+	})
+
+	It("falls back when the primary backend fails", func() {
+		out, err := runCommand(paths, "llm", "--provider", "mock", "--model", mockFixtures,
+			"--fallback", "echo:echo", "--max-attempts", "1", "--prompt", overloadPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("answered by fallback echo:echo"))
+		Expect(string(out)).To(ContainSubstring("Please simulate overload."))
+	})
+
+	It("records the fallback that answered on the session reply", func() {
+		sessions := filepath.Join(offlineDir, "sessions")
+		out, err := runCommand(paths, "llm", "--provider", "mock", "--model", mockFixtures, "--fallback", "echo:echo",
+			"--max-attempts", "1", "--session", "overload", "--session-dir", sessions, "--prompt", overloadPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := os.ReadFile(filepath.Join(rootDir, sessions, "overload.json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"provider": "mock"`), "the session keeps its primary")
+		Expect(string(data)).To(ContainSubstring(`"backend": "echo:echo"`))
+	})
+
+	It("falls back when the primary backend hangs", func() {
+		out, err := runCommand(paths, "llm", "--provider", "mock", "--model", mockFixtures,
+			"--fallback", "echo:echo", "--max-attempts", "1", "--timeout", "200ms", "--prompt", hangPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("answered by fallback echo:echo"))
+		Expect(string(out)).To(ContainSubstring("Please hang forever."))
+	})
+
+	It("retries an attempt that timed out", func() {
+		out, err := runCommand(paths, "llm", "--provider", "mock", "--model", mockFixtures,
+			"--timeout", "200ms", "--prompt", timeoutPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("Answered on the second attempt."))
+	})
+
+	It("fails cleanly when nothing was recorded", func() {
+		out, err := runCommand(paths, "llm", "--provider", "replay", "--model", cacheDir, "--prompt", overloadPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).To(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("no recorded response"))
+	})
+
+	It("replays recorded Ollama traffic through the real provider client", func() {
+		out, err := runCommand(paths, "llm", "--provider", "ollama", "--model", "phi4",
+			"--replay", ollamaCassette, "--prompt", bgpPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("A route reflector re-advertises iBGP routes"))
+	})
+	It("refuses a prompt that exceeds the context window", func() {
+		out, err := runCommand(paths, "llm", "--provider", "echo", "--context-windows", tinyWindows, "--prompt", bgpPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).To(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("prompt exceeds context window"))
+	})
+
+	It("fits the prompt into the smallest window of the fallback chain", func() {
+		out, err := runCommand(paths, "llm", "--provider", "mock", "--model", mockFixtures,
+			"--fallback", "echo:echo", "--context-windows", tinyWindows, "--prompt", bgpPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).To(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("prompt exceeds context window"))
+		Expect(string(out)).To(ContainSubstring("for echo:echo"))
+	})
+
+	It("truncates an overflowing prompt and reports it", func() {
+		out, err := runCommand(paths, "llm", "--provider", "echo", "--context-windows", tinyWindows,
+			"--on-overflow", "truncate-head", "--prompt", bgpPrompt)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("Prompt truncated (truncate-head)"))
+		Expect(string(out)).To(ContainSubstring("in one sentence."))
+		Expect(string(out)).ToNot(ContainSubstring("Explain BGP"))
+	})
+	It("uses the recommended model of the template beside the prompt", func() {
+		out, err := runCommand(paths, "llm", "--prompt", filepath.Join(recommendDir, "prompt.txt"))
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("Using the template's recommended model echo:echo (temperature 0)"))
+		Expect(string(out)).To(ContainSubstring("what does a route reflector do?"))
+	})
+
+	It("renders system and user sections and honours the recommended model in run", func() {
+		output := filepath.Join(offlineDir, "recommend.md")
+		out, err := runCommand(paths, "run", "--template", filepath.Join(recommendDir, "template.yaml"),
+			"--config", filepath.Join(recommendDir, "config.yaml"), "--query", "What is BGP?", "--output", output)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("recommended model echo:echo"))
+
+		// The echo provider answers with the last user message only.
+		answer, err := os.ReadFile(filepath.Join(rootDir, output))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(answer)).To(Equal("Question: What is BGP?\n"))
+	})
+
+	It("lints the bundled resources tree without errors", func() {
+		out, err := runCommand(paths, "prompt", "lint", "resources")
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("no problems found"))
+	})
+
+	It("renders the router without an example block when its config has none", func() {
+		out, err := runCommand(paths, "prompt", "--category", "classification", "--topic", "router",
+			"--query", "Explain BGP", "--strict", "--preview")
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).ToNot(ContainSubstring("Example Query:"))
+		Expect(string(out)).To(ContainSubstring("Options: basic, intermediate, advanced. Example: advanced"))
+		Expect(string(out)).To(ContainSubstring("Give the sections in this order: Tree of Thought,"))
+	})
+
+	It("lists topics and shows one from the resources catalog", func() {
+		out, err := runCommand(paths, "prompt", "list")
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("classification/router"))
+		Expect(string(out)).To(ContainSubstring("topics/git"))
+
+		out, err = runCommand(paths, "prompt", "show", "classification/router")
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("resources/classification/router/config.yaml"))
+	})
+
+	It("renders one router prompt per dataset row with a manifest", func() {
+		outDir := filepath.Join(offlineDir, "dataset")
+		out, err := runCommand(paths, "prompt", "--category", "classification", "--topic", "router",
+			"--dataset", "resources/classification/router/ground-truth/data.csv", "--output-dir", outDir)
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("Rendered 50 of 50 rows"))
+
+		first, err := os.ReadFile(filepath.Join(rootDir, outDir, "01.txt"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(first)).To(ContainSubstring("Explain how tree-of-thought prompting works"))
+		Expect(string(first)).ToNot(ContainSubstring("# ===="), "template banners are comments, not prompt text")
+		manifest, err := os.ReadFile(filepath.Join(rootDir, outDir, "manifest.jsonl"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(manifest)).To(ContainSubstring(`{"row":50,"id":"50","status":"ok"`))
+	})
+
+	It("exits with a code that tells template and config failures apart", func() {
+		out, err := runCommand(paths, "prompt", "--template", "resources/missing/template.yaml", "--preview")
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		var exitErr *exec.ExitError
+		Expect(errors.As(err, &exitErr)).To(BeTrue())
+		Expect(exitErr.ExitCode()).To(Equal(3))
+		Expect(string(out)).To(ContainSubstring("template not found: resources/missing/template.yaml"))
+
+		out, err = runCommand(paths, "prompt", "--category", "classification", "--topic", "router", "--preview")
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(errors.As(err, &exitErr)).To(BeTrue())
+		Expect(exitErr.ExitCode()).To(Equal(3))
+		Expect(string(out)).To(ContainSubstring("template rendering failed: missing required variables: user_query"))
+
+		out, err = runCommand(paths, "prompt", "--topic", "git", "--config", "resources/missing/config.yaml", "--preview")
+		GinkgoWriter.Printf("CLI output:\n%s\n", out)
+		Expect(errors.As(err, &exitErr)).To(BeTrue())
+		Expect(exitErr.ExitCode()).To(Equal(4))
+	})
+})
@@ -0,0 +1,180 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// OverflowPolicy says what to do with a prompt that exceeds the model's
+// context window.
+type OverflowPolicy string
+
+const (
+	// OverflowError refuses to send the prompt.
+	OverflowError OverflowPolicy = "error"
+	// OverflowTruncateHead drops tokens from the start of the prompt.
+	OverflowTruncateHead OverflowPolicy = "truncate-head"
+	// OverflowTruncateMiddle keeps the start and end and drops the middle.
+	OverflowTruncateMiddle OverflowPolicy = "truncate-middle"
+	// OverflowTruncateQuery shortens only the user query inside the prompt.
+	OverflowTruncateQuery OverflowPolicy = "truncate-query"
+)
+
+// OverflowPolicies lists the accepted policies.
+var OverflowPolicies = []OverflowPolicy{OverflowError, OverflowTruncateHead, OverflowTruncateMiddle, OverflowTruncateQuery}
+
+// ParseOverflowPolicy validates a policy name.
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	for _, p := range OverflowPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	names := make([]string, len(OverflowPolicies))
+	for i, p := range OverflowPolicies {
+		names[i] = string(p)
+	}
+	return "", fmt.Errorf("invalid overflow policy %q (want one of: %s)", s, strings.Join(names, ", "))
+}
+
+// ErrContextOverflow is returned when a prompt does not fit and cannot be
+// truncated to fit.
+var ErrContextOverflow = errors.New("prompt exceeds context window")
+
+// truncationMarker replaces the tokens dropped by truncate-middle.
+const truncationMarker = "\n\n[... %s tokens truncated ...]\n\n"
+
+// Guard checks prompts against a model's context window before sending.
+type Guard struct {
+	Counter *Counter
+	Windows *ContextWindows
+	Policy  OverflowPolicy
+}
+
+// Fit is the outcome of fitting a prompt into a context window.
+type Fit struct {
+	Prompt string
+	// Tokens is the token count of Prompt; Original is the count before
+	// truncation.
+	Tokens   int
+	Original int
+	// Window is the model's context length, or zero when unknown.
+	Window    int
+	Truncated bool
+}
+
+// Removed is the number of tokens truncation dropped.
+func (f Fit) Removed() int {
+	return f.Original - f.Tokens
+}
+
+// Fit returns prompt unchanged when it fits the model's context window (or
+// the window is unknown) and otherwise applies the guard's policy. query is
+// the user query as it appears in prompt; only truncate-query uses it.
+func (g *Guard) Fit(provider, model, prompt, query string) (Fit, error) {
+	count := g.Counter.Count(provider, model, prompt).Tokens
+	fit := Fit{Prompt: prompt, Tokens: count, Original: count}
+	window, ok := g.Windows.Lookup(provider, model)
+	if !ok {
+		return fit, nil
+	}
+	fit.Window = window
+	if count <= window {
+		return fit, nil
+	}
+
+	overflow := overflowError(provider, model, count, window)
+	var build func(remove int) (string, bool)
+	switch g.Policy {
+	case OverflowTruncateHead:
+		toks := g.Counter.tokenize(provider, model, prompt)
+		build = func(remove int) (string, bool) {
+			if remove >= toks.n {
+				return "", false
+			}
+			return toks.slice(remove, toks.n), true
+		}
+	case OverflowTruncateMiddle:
+		toks := g.Counter.tokenize(provider, model, prompt)
+		build = func(remove int) (string, bool) {
+			marker := fmt.Sprintf(truncationMarker, FormatInt(remove))
+			remove += g.Counter.Count(provider, model, marker).Tokens
+			if remove >= toks.n {
+				return "", false
+			}
+			head := (toks.n - remove) / 2
+			return toks.slice(0, head) + marker + toks.slice(head+remove, toks.n), true
+		}
+	case OverflowTruncateQuery:
+		at := strings.LastIndex(prompt, query)
+		if query == "" || at < 0 {
+			return fit, fmt.Errorf("%w; truncate-query needs the query text that appears in the prompt", overflow)
+		}
+		toks := g.Counter.tokenize(provider, model, query)
+		before, after := prompt[:at], prompt[at+len(query):]
+		build = func(remove int) (string, bool) {
+			if remove >= toks.n {
+				return "", false
+			}
+			return before + toks.slice(0, toks.n-remove) + after, true
+		}
+	default:
+		return fit, overflow
+	}
+
+	// Token boundaries can shift where the pieces are rejoined, so recount
+	// and cut further until the result fits.
+	for remove := count - window; ; {
+		truncated, ok := build(remove)
+		if !ok {
+			return fit, fmt.Errorf("%w; %s cannot remove enough", overflow, g.Policy)
+		}
+		tokens := g.Counter.Count(provider, model, truncated).Tokens
+		if tokens <= window {
+			return Fit{Prompt: truncated, Tokens: tokens, Original: count, Window: window, Truncated: true}, nil
+		}
+		remove += tokens - window
+	}
+}
+
+// Check reports an overflow error when text exceeds the model's context
+// window, whatever the policy.
+func (g *Guard) Check(provider, model, text string) error {
+	window, ok := g.Windows.Lookup(provider, model)
+	if !ok {
+		return nil
+	}
+	if count := g.Counter.Count(provider, model, text).Tokens; count > window {
+		return overflowError(provider, model, count, window)
+	}
+	return nil
+}
+
+func overflowError(provider, model string, count, window int) error {
+	return fmt.Errorf("%w: %s tokens for %s:%s, which accepts %s",
+		ErrContextOverflow, FormatInt(count), provider, model, FormatInt(window))
+}
+
+// tokenized is text split at token boundaries.
+type tokenized struct {
+	n     int
+	slice func(from, to int) string
+}
+
+// tokenize splits text into the model's tokens, or into heuristic
+// character runs when no encoding is available.
+func (c *Counter) tokenize(provider, model, text string) tokenized {
+	name, _ := EncodingFor(provider, model)
+	if enc, err := c.encoder(name); err == nil {
+		ids := enc.Encode(text, nil, nil)
+		return tokenized{n: len(ids), slice: func(from, to int) string {
+			// A cut can split a multi-byte character across two tokens.
+			return strings.ToValidUTF8(enc.Decode(ids[from:to]), "")
+		}}
+	}
+	runes := []rune(text)
+	return tokenized{n: Estimate(text), slice: func(from, to int) string {
+		return string(runes[min(from*charsPerToken, len(runes)):min(to*charsPerToken, len(runes))])
+	}}
+}
@@ -0,0 +1,136 @@
+package tokens
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runeEncoder treats every rune as one token.
+type runeEncoder struct{}
+
+func (runeEncoder) Encode(text string, _, _ []string) []int {
+	ids := make([]int, 0, len(text))
+	for _, r := range text {
+		ids = append(ids, int(r))
+	}
+	return ids
+}
+
+func (runeEncoder) Decode(ids []int) string {
+	runes := make([]rune, len(ids))
+	for i, id := range ids {
+		runes[i] = rune(id)
+	}
+	return string(runes)
+}
+
+func newGuard(policy OverflowPolicy, window int) *Guard {
+	return &Guard{
+		Counter: &Counter{Load: func(string) (Encoder, error) { return runeEncoder{}, nil }},
+		Windows: &ContextWindows{Windows: map[string]int{"ollama:*": window}},
+		Policy:  policy,
+	}
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	p, err := ParseOverflowPolicy("truncate-middle")
+	assert.NoError(t, err)
+	assert.Equal(t, OverflowTruncateMiddle, p)
+
+	_, err = ParseOverflowPolicy("drop")
+	assert.ErrorContains(t, err, "want one of: error, truncate-head, truncate-middle, truncate-query")
+}
+
+func TestGuard_FitsOrUnknown(t *testing.T) {
+	g := newGuard(OverflowError, 10)
+
+	fit, err := g.Fit("ollama", "phi4", "short", "")
+	assert.NoError(t, err)
+	assert.False(t, fit.Truncated)
+	assert.Equal(t, 10, fit.Window)
+
+	fit, err = g.Fit("openai", "gpt-4o", strings.Repeat("x", 50), "")
+	assert.NoError(t, err)
+	assert.Zero(t, fit.Window)
+	assert.Equal(t, 50, fit.Tokens)
+}
+
+func TestGuard_Error(t *testing.T) {
+	_, err := newGuard(OverflowError, 10).Fit("ollama", "phi4", "abcdefghijklmno", "")
+	assert.ErrorIs(t, err, ErrContextOverflow)
+	assert.ErrorContains(t, err, "15 tokens for ollama:phi4, which accepts 10")
+}
+
+func TestGuard_TruncateHead(t *testing.T) {
+	fit, err := newGuard(OverflowTruncateHead, 10).Fit("ollama", "phi4", "abcdefghijklmno", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "fghijklmno", fit.Prompt)
+	assert.True(t, fit.Truncated)
+	assert.Equal(t, 5, fit.Removed())
+}
+
+func TestGuard_TruncateMiddle(t *testing.T) {
+	prompt := strings.Repeat("a", 40) + strings.Repeat("b", 40) + strings.Repeat("c", 40)
+	fit, err := newGuard(OverflowTruncateMiddle, 80).Fit("ollama", "phi4", prompt, "")
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, fit.Tokens, 80)
+	assert.True(t, strings.HasPrefix(fit.Prompt, "aaaa"))
+	assert.True(t, strings.HasSuffix(fit.Prompt, "cccc"))
+	assert.Contains(t, fit.Prompt, "tokens truncated ...]")
+}
+
+func TestGuard_TruncateQuery(t *testing.T) {
+	prompt := "Route this. Input Query: what is the airspeed of a swallow\nAnswer in JSON."
+	fit, err := newGuard(OverflowTruncateQuery, 60).Fit("ollama", "phi4", prompt, "what is the airspeed of a swallow")
+	assert.NoError(t, err)
+	assert.Equal(t, "Route this. Input Query: what is the airspee\nAnswer in JSON.", fit.Prompt)
+	assert.Equal(t, 60, fit.Tokens)
+
+	_, err = newGuard(OverflowTruncateQuery, 60).Fit("ollama", "phi4", prompt, "not in prompt")
+	assert.ErrorContains(t, err, "truncate-query needs the query text")
+
+	_, err = newGuard(OverflowTruncateQuery, 20).Fit("ollama", "phi4", prompt, "swallow")
+	assert.ErrorContains(t, err, "truncate-query cannot remove enough")
+}
+
+func TestGuard_HeuristicTruncation(t *testing.T) {
+	g := newGuard(OverflowTruncateHead, 2)
+	g.Counter = &Counter{Load: func(string) (Encoder, error) { return nil, errors.New("offline") }}
+	fit, err := g.Fit("ollama", "phi4", "aaaabbbbcccc", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "bbbbcccc", fit.Prompt)
+}
+
+func TestGuard_Check(t *testing.T) {
+	g := newGuard(OverflowTruncateHead, 5)
+	assert.NoError(t, g.Check("ollama", "phi4", "abc"))
+	assert.ErrorIs(t, g.Check("ollama", "phi4", "abcdefgh"), ErrContextOverflow)
+}
+
+func TestContextWindows(t *testing.T) {
+	windows := DefaultContextWindows()
+	size, ok := windows.Lookup("ollama", "phi4")
+	assert.True(t, ok)
+	assert.Equal(t, 4096, size)
+	size, _ = windows.Lookup("openai", "gpt-4o-mini")
+	assert.Equal(t, 128000, size)
+	_, ok = windows.Lookup("echo", "phi4")
+	assert.False(t, ok)
+
+	path := t.TempDir() + "/windows.yaml"
+	assert.NoError(t, os.WriteFile(path, []byte("windows:\n  \"ollama:phi4\": 16384\n"), 0644))
+	windows, err := LoadContextWindows(path)
+	assert.NoError(t, err)
+	size, _ = windows.Lookup("ollama", "phi4")
+	assert.Equal(t, 16384, size)
+	size, _ = windows.Lookup("ollama", "llama3")
+	assert.Equal(t, 4096, size)
+
+	assert.NoError(t, os.WriteFile(path, []byte("windows:\n  \"ollama:phi4\": 0\n"), 0644))
+	_, err = LoadContextWindows(path)
+	assert.ErrorContains(t, err, "must be positive")
+}
@@ -0,0 +1,115 @@
+package tokens
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// downloadTimeout bounds fetching a BPE file on first use.
+const downloadTimeout = 10 * time.Second
+
+var installLoader sync.Once
+
+// loadEncoding returns a tiktoken encoding, fetching its BPE file through
+// bpeLoader on first use.
+func loadEncoding(name string) (Encoder, error) {
+	installLoader.Do(func() {
+		tiktoken.SetBpeLoader(&bpeLoader{client: &http.Client{Timeout: downloadTimeout}})
+	})
+	enc, err := tiktoken.GetEncoding(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s encoding: %w", name, err)
+	}
+	return enc, nil
+}
+
+// bpeLoader reads BPE rank files from a local cache, downloading them with a
+// timeout when missing. The cache lives in $TIKTOKEN_CACHE_DIR, or the user
+// cache directory; pre-seed it to count exactly while offline.
+type bpeLoader struct {
+	client *http.Client
+}
+
+func (l *bpeLoader) LoadTiktokenBpe(url string) (map[string]int, error) {
+	data, err := l.read(url)
+	if err != nil {
+		return nil, err
+	}
+	return parseBpe(data)
+}
+
+func (l *bpeLoader) read(url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return os.ReadFile(url)
+	}
+	cached := filepath.Join(cacheDir(), filepath.Base(url))
+	if data, err := os.ReadFile(cached); err == nil {
+		return data, nil
+	}
+
+	resp, err := l.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Caching is best effort; a failure only means downloading again.
+	if os.MkdirAll(filepath.Dir(cached), os.ModePerm) == nil {
+		tmp := fmt.Sprintf("%s.%d.tmp", cached, os.Getpid())
+		if os.WriteFile(tmp, data, 0644) == nil {
+			_ = os.Rename(tmp, cached)
+		}
+	}
+	return data, nil
+}
+
+// cacheDir returns where BPE files are cached.
+func cacheDir() string {
+	if dir := os.Getenv("TIKTOKEN_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "ai-explorer", "tiktoken")
+	}
+	return filepath.Join(os.TempDir(), "ai-explorer-tiktoken")
+}
+
+// parseBpe parses "<base64 token> <rank>" lines.
+func parseBpe(data []byte) (map[string]int, error) {
+	ranks := map[string]int{}
+	for i, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		token, rank, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid BPE line %d", i+1)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BPE line %d: %w", i+1, err)
+		}
+		n, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BPE line %d: %w", i+1, err)
+		}
+		ranks[string(decoded)] = n
+	}
+	return ranks, nil
+}
@@ -0,0 +1,139 @@
+package tokens
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed prices.yaml
+var defaultPrices []byte
+
+// Price is the cost of a model in currency units per million tokens.
+type Price struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// PriceTable maps glob patterns over "provider:model" to prices.
+type PriceTable struct {
+	Currency string           `yaml:"currency"`
+	Prices   map[string]Price `yaml:"prices"`
+}
+
+// Cost is an estimated charge for a call.
+type Cost struct {
+	Input    float64
+	Output   float64
+	Currency string
+}
+
+// Total returns the input plus output cost.
+func (c Cost) Total() float64 {
+	return c.Input + c.Output
+}
+
+func (c Cost) String() string {
+	if c.Currency == "USD" {
+		return fmt.Sprintf("$%.4f", c.Total())
+	}
+	return fmt.Sprintf("%.4f %s", c.Total(), c.Currency)
+}
+
+// DefaultPriceTable returns the built-in price table.
+func DefaultPriceTable() *PriceTable {
+	table, err := parsePriceTable(defaultPrices)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in price table: %v", err))
+	}
+	return table
+}
+
+// LoadPriceTable reads a price table from path and layers it over the
+// built-in one. An empty path returns the built-in table.
+func LoadPriceTable(filePath string) (*PriceTable, error) {
+	table := DefaultPriceTable()
+	if filePath == "" {
+		return table, nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price table: %w", err)
+	}
+	override, err := parsePriceTable(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse price table %s: %w", filePath, err)
+	}
+	if override.Currency != "" {
+		table.Currency = override.Currency
+	}
+	for pattern, price := range override.Prices {
+		table.Prices[pattern] = price
+	}
+	return table, nil
+}
+
+func parsePriceTable(data []byte) (*PriceTable, error) {
+	var table PriceTable
+	if err := yaml.Unmarshal(data, &table); err != nil {
+		return nil, err
+	}
+	for pattern := range table.Prices {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	if table.Prices == nil {
+		table.Prices = map[string]Price{}
+	}
+	return &table, nil
+}
+
+// Lookup returns the price for a model using the most specific matching
+// pattern.
+func (t *PriceTable) Lookup(provider, model string) (Price, bool) {
+	return lookupPattern(t.Prices, provider+":"+model)
+}
+
+// lookupPattern returns the value for name from a map keyed by glob
+// patterns, preferring an exact key and then the longest matching pattern.
+func lookupPattern[V any](values map[string]V, name string) (V, bool) {
+	if v, ok := values[name]; ok {
+		return v, true
+	}
+	patterns := make([]string, 0, len(values))
+	for pattern := range values {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return values[pattern], true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Cost estimates the charge for usage on a model. ok is false when the
+// model has no price.
+func (t *PriceTable) Cost(provider, model string, usage Usage) (Cost, bool) {
+	price, ok := t.Lookup(provider, model)
+	if !ok {
+		return Cost{}, false
+	}
+	return Cost{
+		Input:    float64(usage.PromptTokens) * price.Input / 1e6,
+		Output:   float64(usage.CompletionTokens) * price.Output / 1e6,
+		Currency: t.Currency,
+	}, true
+}
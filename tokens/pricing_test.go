@@ -0,0 +1,64 @@
+package tokens
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPriceTable_Lookup(t *testing.T) {
+	table := DefaultPriceTable()
+
+	price, ok := table.Lookup("openai", "gpt-4o-mini")
+	assert.True(t, ok)
+	assert.Equal(t, Price{Input: 0.15, Output: 0.60}, price, "gpt-4o-mini* beats gpt-4o-*")
+
+	price, ok = table.Lookup("openai", "gpt-4o-2024-08-06")
+	assert.True(t, ok)
+	assert.Equal(t, 2.50, price.Input)
+
+	price, ok = table.Lookup("ollama", "phi4")
+	assert.True(t, ok)
+	assert.Zero(t, price.Input)
+
+	_, ok = table.Lookup("openai", "unknown-model")
+	assert.False(t, ok)
+}
+
+func TestPriceTable_Cost(t *testing.T) {
+	cost, ok := DefaultPriceTable().Cost("openai", "gpt-4o-mini", Usage{PromptTokens: 1_000_000, CompletionTokens: 500_000})
+	assert.True(t, ok)
+	assert.InDelta(t, 0.15, cost.Input, 1e-9)
+	assert.InDelta(t, 0.30, cost.Output, 1e-9)
+	assert.Equal(t, "$0.4500", cost.String())
+}
+
+func TestLoadPriceTable_Overrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+currency: EUR
+prices:
+  "ollama:*": { input: 0.01, output: 0.02 }
+  "openai:my-finetune": { input: 5, output: 15 }
+`), 0644))
+
+	table, err := LoadPriceTable(path)
+	require.NoError(t, err)
+	price, _ := table.Lookup("ollama", "phi4")
+	assert.Equal(t, Price{Input: 0.01, Output: 0.02}, price)
+	_, ok := table.Lookup("openai", "my-finetune")
+	assert.True(t, ok)
+	_, ok = table.Lookup("openai", "gpt-4o-mini")
+	assert.True(t, ok, "built-in prices are kept")
+	assert.Equal(t, "0.0000 EUR", Cost{Currency: "EUR"}.String())
+
+	require.NoError(t, os.WriteFile(path, []byte(`prices: {"[": {input: 1}}`), 0644))
+	_, err = LoadPriceTable(path)
+	assert.ErrorContains(t, err, "invalid pattern")
+
+	_, err = LoadPriceTable(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read price table")
+}
@@ -0,0 +1,59 @@
+package tokens
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Summary describes the token usage and estimated cost of one call.
+type Summary struct {
+	Backend string
+	Usage   Usage
+	// Reported is set when the provider reported the usage; otherwise it
+	// was counted locally.
+	Reported bool
+	// Cost is nil when the model has no price.
+	Cost *Cost
+}
+
+func (s Summary) String() string {
+	source := "counted"
+	if s.Reported {
+		source = "reported"
+	}
+	cost := "cost unknown"
+	if s.Cost != nil {
+		cost = "est. cost " + s.Cost.String()
+	}
+	return fmt.Sprintf("%s: %s prompt + %s completion = %s tokens (%s) · %s",
+		s.Backend, FormatInt(s.Usage.PromptTokens), FormatInt(s.Usage.CompletionTokens),
+		FormatInt(s.Usage.Total()), source, cost)
+}
+
+// Describe renders a count such as "1,234 tokens (cl100k_base, approximate)".
+func (c Count) Describe() string {
+	detail := c.Encoding
+	if c.Approximate && c.Encoding != EncodingHeuristic {
+		detail += ", approximate"
+	}
+	return fmt.Sprintf("%s tokens (%s)", FormatInt(c.Tokens), detail)
+}
+
+// FormatInt formats n with thousands separators.
+func FormatInt(n int) string {
+	s := strconv.Itoa(n)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if neg {
+		return "-" + b.String()
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary_String(t *testing.T) {
+	s := Summary{
+		Backend:  "openai:gpt-4o-mini",
+		Usage:    Usage{PromptTokens: 1234, CompletionTokens: 56},
+		Reported: true,
+		Cost:     &Cost{Input: 0.0002, Output: 0.00003, Currency: "USD"},
+	}
+	assert.Equal(t, "openai:gpt-4o-mini: 1,234 prompt + 56 completion = 1,290 tokens (reported) · est. cost $0.0002", s.String())
+
+	s.Reported, s.Cost = false, nil
+	assert.Contains(t, s.String(), "(counted) · cost unknown")
+}
+
+func TestCount_Describe(t *testing.T) {
+	assert.Equal(t, "7,215 tokens (cl100k_base, approximate)", Count{Tokens: 7215, Encoding: EncodingCL100K, Approximate: true}.Describe())
+	assert.Equal(t, "12 tokens (heuristic)", Count{Tokens: 12, Encoding: EncodingHeuristic, Approximate: true}.Describe())
+}
+
+func TestFormatInt(t *testing.T) {
+	assert.Equal(t, "0", FormatInt(0))
+	assert.Equal(t, "999", FormatInt(999))
+	assert.Equal(t, "1,000", FormatInt(1000))
+	assert.Equal(t, "1,234,567", FormatInt(1234567))
+	assert.Equal(t, "-12,345", FormatInt(-12345))
+}
@@ -0,0 +1,117 @@
+// Package tokens counts prompt tokens per model family, extracts the token
+// usage providers report, and estimates cost from a YAML price table.
+package tokens
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// charsPerToken is the rule-of-thumb ratio used when no tokenizer is available.
+const charsPerToken = 4
+
+// Tokenizer names. Heuristic means no BPE encoding could be loaded.
+const (
+	EncodingCL100K    = "cl100k_base"
+	EncodingHeuristic = "heuristic"
+)
+
+// Encoder turns text into tokens and back. *tiktoken.Tiktoken satisfies it.
+type Encoder interface {
+	Encode(text string, allowedSpecial, disallowedSpecial []string) []int
+	Decode(tokens []int) string
+}
+
+// Count is the result of counting a text's tokens.
+type Count struct {
+	Tokens   int
+	Encoding string
+	// Approximate is set when the encoding is not the model's own tokenizer.
+	Approximate bool
+}
+
+// cl100kPrefixes lists OpenAI model families whose tokenizer is cl100k_base.
+// "gpt-4-" deliberately excludes gpt-4o and gpt-4.1, which use o200k_base.
+var cl100kPrefixes = []string{"gpt-4-", "gpt-3.5-turbo", "text-embedding-3-", "text-embedding-ada-002"}
+
+// EncodingFor returns the encoding used to count tokens for a model, and
+// whether it is the model's actual tokenizer. Models with their own
+// tokenizers (Llama, Phi, Mistral, newer OpenAI families) are approximated
+// with cl100k_base, which is usually within a few percent.
+func EncodingFor(provider, model string) (string, bool) {
+	if provider == "openai" {
+		if model == "gpt-4" {
+			return EncodingCL100K, true
+		}
+		for _, prefix := range cl100kPrefixes {
+			if strings.HasPrefix(model, prefix) {
+				return EncodingCL100K, true
+			}
+		}
+	}
+	return EncodingCL100K, false
+}
+
+// Counter counts tokens, loading each encoding once. If an encoding cannot
+// be loaded (for example offline, with no cached BPE file) it falls back to
+// a character-based estimate.
+type Counter struct {
+	Load func(encoding string) (Encoder, error)
+
+	mu       sync.Mutex
+	encoders map[string]Encoder
+	failures map[string]error
+}
+
+// NewCounter returns a Counter backed by tiktoken.
+func NewCounter() *Counter {
+	return &Counter{Load: loadEncoding}
+}
+
+// Default is the shared Counter used by the CLI.
+var Default = NewCounter()
+
+// Count returns the number of tokens in text for the given model.
+func (c *Counter) Count(provider, model, text string) Count {
+	name, exact := EncodingFor(provider, model)
+	enc, err := c.encoder(name)
+	if err != nil {
+		return Count{Tokens: Estimate(text), Encoding: EncodingHeuristic, Approximate: true}
+	}
+	return Count{Tokens: len(enc.Encode(text, nil, nil)), Encoding: name, Approximate: !exact}
+}
+
+// Err reports why an encoding could not be loaded, if it failed.
+func (c *Counter) Err(encoding string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failures[encoding]
+}
+
+func (c *Counter) encoder(name string) (Encoder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if enc, ok := c.encoders[name]; ok {
+		return enc, nil
+	}
+	if err, ok := c.failures[name]; ok {
+		return nil, err
+	}
+	if c.encoders == nil {
+		c.encoders, c.failures = map[string]Encoder{}, map[string]error{}
+	}
+	enc, err := c.Load(name)
+	if err != nil {
+		c.failures[name] = err
+		return nil, err
+	}
+	c.encoders[name] = enc
+	return enc, nil
+}
+
+// Estimate approximates a token count from the number of characters.
+func Estimate(text string) int {
+	n := utf8.RuneCountInString(text)
+	return (n + charsPerToken - 1) / charsPerToken
+}
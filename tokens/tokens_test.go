@@ -0,0 +1,82 @@
+package tokens
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// wordEncoder counts whitespace-separated words as tokens.
+type wordEncoder struct{}
+
+func (wordEncoder) Encode(text string, _, _ []string) []int {
+	return make([]int, len(strings.Fields(text)))
+}
+
+func (wordEncoder) Decode([]int) string { return "" }
+
+func TestEncodingFor(t *testing.T) {
+	cases := []struct {
+		provider, model string
+		exact           bool
+	}{
+		{"openai", "gpt-4", true},
+		{"openai", "gpt-4-turbo", true},
+		{"openai", "gpt-3.5-turbo-0125", true},
+		{"openai", "text-embedding-3-small", true},
+		{"openai", "gpt-4o-mini", false},
+		{"ollama", "phi4", false},
+		{"ollama", "gpt-4", false},
+	}
+	for _, c := range cases {
+		enc, exact := EncodingFor(c.provider, c.model)
+		assert.Equal(t, EncodingCL100K, enc)
+		assert.Equal(t, c.exact, exact, "%s:%s", c.provider, c.model)
+	}
+}
+
+func TestCounter_Count(t *testing.T) {
+	loads := 0
+	c := &Counter{Load: func(string) (Encoder, error) {
+		loads++
+		return wordEncoder{}, nil
+	}}
+
+	got := c.Count("openai", "gpt-4", "one two three")
+	assert.Equal(t, Count{Tokens: 3, Encoding: EncodingCL100K}, got)
+
+	got = c.Count("ollama", "phi4", "one two")
+	assert.Equal(t, Count{Tokens: 2, Encoding: EncodingCL100K, Approximate: true}, got)
+	assert.Equal(t, 1, loads, "encodings are loaded once")
+}
+
+func TestCounter_FallsBackToEstimate(t *testing.T) {
+	loads := 0
+	c := &Counter{Load: func(string) (Encoder, error) {
+		loads++
+		return nil, errors.New("offline")
+	}}
+
+	got := c.Count("openai", "gpt-4", "abcdefghi")
+	assert.Equal(t, Count{Tokens: 3, Encoding: EncodingHeuristic, Approximate: true}, got)
+	c.Count("openai", "gpt-4", "again")
+	assert.Equal(t, 1, loads, "failures are remembered")
+	assert.EqualError(t, c.Err(EncodingCL100K), "offline")
+}
+
+func TestEstimate(t *testing.T) {
+	assert.Equal(t, 0, Estimate(""))
+	assert.Equal(t, 1, Estimate("abc"))
+	assert.Equal(t, 2, Estimate("héllo wö"))
+}
+
+func TestParseBpe(t *testing.T) {
+	ranks, err := parseBpe([]byte("aGVsbG8= 0\nIHdvcmxk 1\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"hello": 0, " world": 1}, ranks)
+
+	_, err = parseBpe([]byte("no-rank\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
@@ -0,0 +1,41 @@
+package tokens
+
+// Usage is the token consumption of a single call.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Total returns prompt plus completion tokens.
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// UsageFromGenerationInfo extracts the usage langchaingo providers report in
+// ContentChoice.GenerationInfo. ok is false when the provider reported none.
+func UsageFromGenerationInfo(info map[string]any) (Usage, bool) {
+	prompt, okPrompt := intValue(info["PromptTokens"])
+	completion, okCompletion := intValue(info["CompletionTokens"])
+	if !okPrompt && !okCompletion {
+		return Usage{}, false
+	}
+	if prompt == 0 && completion == 0 {
+		// Streaming OpenAI responses without usage chunks report zeros.
+		return Usage{}, false
+	}
+	return Usage{PromptTokens: prompt, CompletionTokens: completion}, true
+}
+
+func intValue(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,20 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageFromGenerationInfo(t *testing.T) {
+	u, ok := UsageFromGenerationInfo(map[string]any{"PromptTokens": 12, "CompletionTokens": 30, "TotalTokens": 42})
+	assert.True(t, ok)
+	assert.Equal(t, Usage{PromptTokens: 12, CompletionTokens: 30}, u)
+	assert.Equal(t, 42, u.Total())
+
+	_, ok = UsageFromGenerationInfo(map[string]any{"PromptTokens": 0, "CompletionTokens": 0})
+	assert.False(t, ok, "zero usage means none was reported")
+
+	_, ok = UsageFromGenerationInfo(nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,74 @@
+package tokens
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed context_windows.yaml
+var defaultWindows []byte
+
+// ContextWindows maps glob patterns over "provider:model" to context
+// lengths in tokens.
+type ContextWindows struct {
+	Windows map[string]int `yaml:"windows"`
+}
+
+// DefaultContextWindows returns the built-in context-length registry.
+func DefaultContextWindows() *ContextWindows {
+	windows, err := parseContextWindows(defaultWindows)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in context windows: %v", err))
+	}
+	return windows
+}
+
+// LoadContextWindows reads a registry from path and layers it over the
+// built-in one. An empty path returns the built-in registry.
+func LoadContextWindows(filePath string) (*ContextWindows, error) {
+	windows := DefaultContextWindows()
+	if filePath == "" {
+		return windows, nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context windows: %w", err)
+	}
+	override, err := parseContextWindows(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse context windows %s: %w", filePath, err)
+	}
+	for pattern, size := range override.Windows {
+		windows.Windows[pattern] = size
+	}
+	return windows, nil
+}
+
+func parseContextWindows(data []byte) (*ContextWindows, error) {
+	var windows ContextWindows
+	if err := yaml.Unmarshal(data, &windows); err != nil {
+		return nil, err
+	}
+	for pattern, size := range windows.Windows {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if size <= 0 {
+			return nil, fmt.Errorf("context window for %q must be positive, got %d", pattern, size)
+		}
+	}
+	if windows.Windows == nil {
+		windows.Windows = map[string]int{}
+	}
+	return &windows, nil
+}
+
+// Lookup returns a model's context length using the most specific matching
+// pattern. ok is false when the model is not in the registry.
+func (w *ContextWindows) Lookup(provider, model string) (int, bool) {
+	return lookupPattern(w.Windows, provider+":"+model)
+}